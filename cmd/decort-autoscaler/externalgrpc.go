@@ -0,0 +1,536 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+// Messages and service descriptor of the cluster-autoscaler external gRPC cloud provider
+// (cluster-autoscaler/cloudprovider/externalgrpc/protos/externalgrpc.proto). Only the messages
+// used by the autoscaler are encoded, their fields keep the numbers of the upstream proto file so
+// the server is wire compatible with the "externalgrpc" cloud provider of cluster-autoscaler.
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const externalGrpcService = "clusterautoscaler.cloudprovider.v1.externalgrpc.CloudProvider"
+
+// instance states of the InstanceStatus message
+const (
+	instanceRunning  = 1
+	instanceCreating = 2
+	instanceDeleting = 3
+)
+
+// message is implemented by the request and response types of the service
+type message interface {
+	marshal(b []byte) []byte
+	unmarshal(b []byte) error
+}
+
+// codec replaces the default gRPC proto codec, which requires generated proto messages
+type codec struct{}
+
+func (codec) Name() string {
+	return "proto"
+}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(message)
+	if !ok {
+		return nil, fmt.Errorf("cannot marshal %T", v)
+	}
+	return msg.marshal(nil), nil
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(message)
+	if !ok {
+		return fmt.Errorf("cannot unmarshal %T", v)
+	}
+	return msg.unmarshal(data)
+}
+
+// walkFields calls fn for every field of the encoded message, fields fn does not know are skipped
+func walkFields(b []byte, fn func(num protowire.Number, typ protowire.Type, b []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		n, err := fn(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func consumeString(b []byte, s *string) (int, error) {
+	v, n := protowire.ConsumeString(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*s = v
+	return n, nil
+}
+
+func consumeInt32(b []byte, i *int32) (int, error) {
+	v, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*i = int32(v)
+	return n, nil
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendInt32(b []byte, num protowire.Number, i int32) []byte {
+	if i == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(int64(i)))
+}
+
+func appendMessage(b []byte, num protowire.Number, msg message) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg.marshal(nil))
+}
+
+// empty is used for requests and responses without fields
+type empty struct{}
+
+func (*empty) marshal(b []byte) []byte {
+	return b
+}
+
+func (*empty) unmarshal(b []byte) error {
+	return walkFields(b, func(protowire.Number, protowire.Type, []byte) (int, error) { return 0, nil })
+}
+
+type nodeGroup struct {
+	ID      string
+	MinSize int32
+	MaxSize int32
+	Debug   string
+}
+
+func (g *nodeGroup) marshal(b []byte) []byte {
+	b = appendString(b, 1, g.ID)
+	b = appendInt32(b, 2, g.MinSize)
+	b = appendInt32(b, 3, g.MaxSize)
+	return appendString(b, 4, g.Debug)
+}
+
+func (g *nodeGroup) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			return consumeString(b, &g.ID)
+		case num == 2 && typ == protowire.VarintType:
+			return consumeInt32(b, &g.MinSize)
+		case num == 3 && typ == protowire.VarintType:
+			return consumeInt32(b, &g.MaxSize)
+		case num == 4 && typ == protowire.BytesType:
+			return consumeString(b, &g.Debug)
+		}
+		return 0, nil
+	})
+}
+
+// externalGrpcNode is the Kubernetes node the autoscaler asks about, labels and annotations are not used
+type externalGrpcNode struct {
+	ProviderID string
+	Name       string
+}
+
+func (n *externalGrpcNode) marshal(b []byte) []byte {
+	b = appendString(b, 1, n.ProviderID)
+	return appendString(b, 2, n.Name)
+}
+
+func (n *externalGrpcNode) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			return consumeString(b, &n.ProviderID)
+		case num == 2 && typ == protowire.BytesType:
+			return consumeString(b, &n.Name)
+		}
+		return 0, nil
+	})
+}
+
+type nodeGroupsResponse struct {
+	NodeGroups []*nodeGroup
+}
+
+func (r *nodeGroupsResponse) marshal(b []byte) []byte {
+	for _, g := range r.NodeGroups {
+		b = appendMessage(b, 1, g)
+	}
+	return b
+}
+
+func (r *nodeGroupsResponse) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != 1 || typ != protowire.BytesType {
+			return 0, nil
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		g := &nodeGroup{}
+		if err := g.unmarshal(v); err != nil {
+			return 0, err
+		}
+		r.NodeGroups = append(r.NodeGroups, g)
+		return n, nil
+	})
+}
+
+type nodeGroupForNodeRequest struct {
+	Node externalGrpcNode
+}
+
+func (r *nodeGroupForNodeRequest) marshal(b []byte) []byte {
+	return appendMessage(b, 1, &r.Node)
+}
+
+func (r *nodeGroupForNodeRequest) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != 1 || typ != protowire.BytesType {
+			return 0, nil
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		return n, r.Node.unmarshal(v)
+	})
+}
+
+// nodeGroupForNodeResponse holds a node group with an empty ID if the node does not belong to any group
+type nodeGroupForNodeResponse struct {
+	NodeGroup nodeGroup
+}
+
+func (r *nodeGroupForNodeResponse) marshal(b []byte) []byte {
+	return appendMessage(b, 1, &r.NodeGroup)
+}
+
+func (r *nodeGroupForNodeResponse) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != 1 || typ != protowire.BytesType {
+			return 0, nil
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		return n, r.NodeGroup.unmarshal(v)
+	})
+}
+
+type gpuLabelResponse struct {
+	Label string
+}
+
+func (r *gpuLabelResponse) marshal(b []byte) []byte {
+	return appendString(b, 1, r.Label)
+}
+
+func (r *gpuLabelResponse) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num == 1 && typ == protowire.BytesType {
+			return consumeString(b, &r.Label)
+		}
+		return 0, nil
+	})
+}
+
+// nodeGroupRequest is used by requests carrying the node group ID only
+type nodeGroupRequest struct {
+	ID string
+}
+
+func (r *nodeGroupRequest) marshal(b []byte) []byte {
+	return appendString(b, 1, r.ID)
+}
+
+func (r *nodeGroupRequest) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num == 1 && typ == protowire.BytesType {
+			return consumeString(b, &r.ID)
+		}
+		return 0, nil
+	})
+}
+
+type nodeGroupTargetSizeResponse struct {
+	TargetSize int32
+}
+
+func (r *nodeGroupTargetSizeResponse) marshal(b []byte) []byte {
+	return appendInt32(b, 1, r.TargetSize)
+}
+
+func (r *nodeGroupTargetSizeResponse) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num == 1 && typ == protowire.VarintType {
+			return consumeInt32(b, &r.TargetSize)
+		}
+		return 0, nil
+	})
+}
+
+// nodeGroupDeltaRequest is used by NodeGroupIncreaseSize and NodeGroupDecreaseTargetSize
+type nodeGroupDeltaRequest struct {
+	Delta int32
+	ID    string
+}
+
+func (r *nodeGroupDeltaRequest) marshal(b []byte) []byte {
+	b = appendInt32(b, 1, r.Delta)
+	return appendString(b, 2, r.ID)
+}
+
+func (r *nodeGroupDeltaRequest) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			return consumeInt32(b, &r.Delta)
+		case num == 2 && typ == protowire.BytesType:
+			return consumeString(b, &r.ID)
+		}
+		return 0, nil
+	})
+}
+
+type nodeGroupDeleteNodesRequest struct {
+	Nodes []*externalGrpcNode
+	ID    string
+}
+
+func (r *nodeGroupDeleteNodesRequest) marshal(b []byte) []byte {
+	for _, node := range r.Nodes {
+		b = appendMessage(b, 1, node)
+	}
+	return appendString(b, 2, r.ID)
+}
+
+func (r *nodeGroupDeleteNodesRequest) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return 0, protowire.ParseError(n)
+			}
+			node := &externalGrpcNode{}
+			if err := node.unmarshal(v); err != nil {
+				return 0, err
+			}
+			r.Nodes = append(r.Nodes, node)
+			return n, nil
+		case num == 2 && typ == protowire.BytesType:
+			return consumeString(b, &r.ID)
+		}
+		return 0, nil
+	})
+}
+
+// instance is the Instance message with the nested InstanceStatus, error info is not reported
+type instance struct {
+	ID    string
+	State int32
+}
+
+func (i *instance) marshal(b []byte) []byte {
+	b = appendString(b, 1, i.ID)
+	status := protowire.AppendTag(nil, 1, protowire.VarintType)
+	status = protowire.AppendVarint(status, uint64(i.State))
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendBytes(b, status)
+}
+
+func (i *instance) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			return consumeString(b, &i.ID)
+		case num == 2 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return 0, protowire.ParseError(n)
+			}
+			return n, walkFields(v, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+				if num == 1 && typ == protowire.VarintType {
+					return consumeInt32(b, &i.State)
+				}
+				return 0, nil
+			})
+		}
+		return 0, nil
+	})
+}
+
+type nodeGroupNodesResponse struct {
+	Instances []*instance
+}
+
+func (r *nodeGroupNodesResponse) marshal(b []byte) []byte {
+	for _, i := range r.Instances {
+		b = appendMessage(b, 1, i)
+	}
+	return b
+}
+
+func (r *nodeGroupNodesResponse) unmarshal(b []byte) error {
+	return walkFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != 1 || typ != protowire.BytesType {
+			return 0, nil
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		i := &instance{}
+		if err := i.unmarshal(v); err != nil {
+			return 0, err
+		}
+		r.Instances = append(r.Instances, i)
+		return n, nil
+	})
+}
+
+// cloudProviderServer is implemented by the DECORT cloud provider. Pricing, node templates and
+// per group autoscaling options are not part of it, the service replies with Unimplemented to them
+// and cluster-autoscaler falls back to its defaults.
+type cloudProviderServer interface {
+	NodeGroups(ctx context.Context) (*nodeGroupsResponse, error)
+	NodeGroupForNode(ctx context.Context, req *nodeGroupForNodeRequest) (*nodeGroupForNodeResponse, error)
+	GPULabel(ctx context.Context) (*gpuLabelResponse, error)
+	GetAvailableGPUTypes(ctx context.Context) (*empty, error)
+	Cleanup(ctx context.Context) (*empty, error)
+	Refresh(ctx context.Context) (*empty, error)
+	NodeGroupTargetSize(ctx context.Context, req *nodeGroupRequest) (*nodeGroupTargetSizeResponse, error)
+	NodeGroupIncreaseSize(ctx context.Context, req *nodeGroupDeltaRequest) (*empty, error)
+	NodeGroupDeleteNodes(ctx context.Context, req *nodeGroupDeleteNodesRequest) (*empty, error)
+	NodeGroupDecreaseTargetSize(ctx context.Context, req *nodeGroupDeltaRequest) (*empty, error)
+	NodeGroupNodes(ctx context.Context, req *nodeGroupRequest) (*nodeGroupNodesResponse, error)
+}
+
+// unaryMethod builds a gRPC method description from a handler of the cloud provider
+func unaryMethod(name string, newReq func() message, fn func(s cloudProviderServer, ctx context.Context, req message) (message, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := newReq()
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return fn(srv.(cloudProviderServer), ctx, req.(message))
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + externalGrpcService + "/" + name,
+			}
+			return interceptor(ctx, req, info, handler)
+		},
+	}
+}
+
+func newEmpty() message {
+	return &empty{}
+}
+
+func newNodeGroupRequest() message {
+	return &nodeGroupRequest{}
+}
+
+func newNodeGroupDeltaRequest() message {
+	return &nodeGroupDeltaRequest{}
+}
+
+var cloudProviderServiceDesc = grpc.ServiceDesc{
+	ServiceName: externalGrpcService,
+	HandlerType: (*cloudProviderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("NodeGroups", newEmpty, func(s cloudProviderServer, ctx context.Context, _ message) (message, error) {
+			return s.NodeGroups(ctx)
+		}),
+		unaryMethod("NodeGroupForNode", func() message { return &nodeGroupForNodeRequest{} }, func(s cloudProviderServer, ctx context.Context, req message) (message, error) {
+			return s.NodeGroupForNode(ctx, req.(*nodeGroupForNodeRequest))
+		}),
+		unaryMethod("GPULabel", newEmpty, func(s cloudProviderServer, ctx context.Context, _ message) (message, error) {
+			return s.GPULabel(ctx)
+		}),
+		unaryMethod("GetAvailableGPUTypes", newEmpty, func(s cloudProviderServer, ctx context.Context, _ message) (message, error) {
+			return s.GetAvailableGPUTypes(ctx)
+		}),
+		unaryMethod("Cleanup", newEmpty, func(s cloudProviderServer, ctx context.Context, _ message) (message, error) {
+			return s.Cleanup(ctx)
+		}),
+		unaryMethod("Refresh", newEmpty, func(s cloudProviderServer, ctx context.Context, _ message) (message, error) {
+			return s.Refresh(ctx)
+		}),
+		unaryMethod("NodeGroupTargetSize", newNodeGroupRequest, func(s cloudProviderServer, ctx context.Context, req message) (message, error) {
+			return s.NodeGroupTargetSize(ctx, req.(*nodeGroupRequest))
+		}),
+		unaryMethod("NodeGroupIncreaseSize", newNodeGroupDeltaRequest, func(s cloudProviderServer, ctx context.Context, req message) (message, error) {
+			return s.NodeGroupIncreaseSize(ctx, req.(*nodeGroupDeltaRequest))
+		}),
+		unaryMethod("NodeGroupDeleteNodes", func() message { return &nodeGroupDeleteNodesRequest{} }, func(s cloudProviderServer, ctx context.Context, req message) (message, error) {
+			return s.NodeGroupDeleteNodes(ctx, req.(*nodeGroupDeleteNodesRequest))
+		}),
+		unaryMethod("NodeGroupDecreaseTargetSize", newNodeGroupDeltaRequest, func(s cloudProviderServer, ctx context.Context, req message) (message, error) {
+			return s.NodeGroupDecreaseTargetSize(ctx, req.(*nodeGroupDeltaRequest))
+		}),
+		unaryMethod("NodeGroupNodes", newNodeGroupRequest, func(s cloudProviderServer, ctx context.Context, req message) (message, error) {
+			return s.NodeGroupNodes(ctx, req.(*nodeGroupRequest))
+		}),
+	},
+	Metadata: "externalgrpc.proto",
+}
@@ -0,0 +1,404 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	_ "google.golang.org/protobuf/types/known/anypb"
+)
+
+// upstreamProto is the descriptor of cluster-autoscaler/cloudprovider/externalgrpc/protos/externalgrpc.proto,
+// transcribed from the upstream file. Methods of pricing, node templates and autoscaling options and
+// their messages are left out, as they refer to Kubernetes API types. Fields the server does not use
+// are kept to check they are skipped.
+const upstreamProto = `
+name: "externalgrpc.proto"
+package: "clusterautoscaler.cloudprovider.v1.externalgrpc"
+dependency: "google/protobuf/any.proto"
+syntax: "proto3"
+message_type: {
+  name: "NodeGroup"
+  field: { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field: { name: "minSize" number: 2 label: LABEL_OPTIONAL type: TYPE_INT32 }
+  field: { name: "maxSize" number: 3 label: LABEL_OPTIONAL type: TYPE_INT32 }
+  field: { name: "debug" number: 4 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type: {
+  name: "ExternalGrpcNode"
+  field: { name: "providerID" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field: { name: "name" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field: { name: "labels" number: 3 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".clusterautoscaler.cloudprovider.v1.externalgrpc.ExternalGrpcNode.LabelsEntry" }
+  field: { name: "annotations" number: 4 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".clusterautoscaler.cloudprovider.v1.externalgrpc.ExternalGrpcNode.AnnotationsEntry" }
+  nested_type: {
+    name: "LabelsEntry"
+    field: { name: "key" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+    field: { name: "value" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+    options: { map_entry: true }
+  }
+  nested_type: {
+    name: "AnnotationsEntry"
+    field: { name: "key" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+    field: { name: "value" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+    options: { map_entry: true }
+  }
+}
+message_type: { name: "NodeGroupsRequest" }
+message_type: {
+  name: "NodeGroupsResponse"
+  field: { name: "nodeGroups" number: 1 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroup" }
+}
+message_type: {
+  name: "NodeGroupForNodeRequest"
+  field: { name: "node" number: 1 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".clusterautoscaler.cloudprovider.v1.externalgrpc.ExternalGrpcNode" }
+}
+message_type: {
+  name: "NodeGroupForNodeResponse"
+  field: { name: "nodeGroup" number: 1 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroup" }
+}
+message_type: { name: "GPULabelRequest" }
+message_type: {
+  name: "GPULabelResponse"
+  field: { name: "label" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type: { name: "GetAvailableGPUTypesRequest" }
+message_type: {
+  name: "GetAvailableGPUTypesResponse"
+  field: { name: "gpuTypes" number: 1 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".clusterautoscaler.cloudprovider.v1.externalgrpc.GetAvailableGPUTypesResponse.GpuTypesEntry" }
+  nested_type: {
+    name: "GpuTypesEntry"
+    field: { name: "key" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+    field: { name: "value" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Any" }
+    options: { map_entry: true }
+  }
+}
+message_type: { name: "CleanupRequest" }
+message_type: { name: "CleanupResponse" }
+message_type: { name: "RefreshRequest" }
+message_type: { name: "RefreshResponse" }
+message_type: {
+  name: "NodeGroupTargetSizeRequest"
+  field: { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type: {
+  name: "NodeGroupTargetSizeResponse"
+  field: { name: "targetSize" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32 }
+}
+message_type: {
+  name: "NodeGroupIncreaseSizeRequest"
+  field: { name: "delta" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32 }
+  field: { name: "id" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type: { name: "NodeGroupIncreaseSizeResponse" }
+message_type: {
+  name: "NodeGroupDeleteNodesRequest"
+  field: { name: "nodes" number: 1 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".clusterautoscaler.cloudprovider.v1.externalgrpc.ExternalGrpcNode" }
+  field: { name: "id" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type: { name: "NodeGroupDeleteNodesResponse" }
+message_type: {
+  name: "NodeGroupDecreaseTargetSizeRequest"
+  field: { name: "delta" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32 }
+  field: { name: "id" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type: { name: "NodeGroupDecreaseTargetSizeResponse" }
+message_type: {
+  name: "NodeGroupNodesRequest"
+  field: { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type: {
+  name: "NodeGroupNodesResponse"
+  field: { name: "instances" number: 1 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".clusterautoscaler.cloudprovider.v1.externalgrpc.Instance" }
+}
+message_type: {
+  name: "Instance"
+  field: { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field: { name: "status" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".clusterautoscaler.cloudprovider.v1.externalgrpc.InstanceStatus" }
+}
+message_type: {
+  name: "InstanceStatus"
+  field: { name: "instanceState" number: 1 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".clusterautoscaler.cloudprovider.v1.externalgrpc.InstanceStatus.InstanceState" }
+  field: { name: "errorInfo" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".clusterautoscaler.cloudprovider.v1.externalgrpc.InstanceErrorInfo" }
+  enum_type: {
+    name: "InstanceState"
+    value: { name: "unspecified" number: 0 }
+    value: { name: "instanceRunning" number: 1 }
+    value: { name: "instanceCreating" number: 2 }
+    value: { name: "instanceDeleting" number: 3 }
+  }
+}
+message_type: {
+  name: "InstanceErrorInfo"
+  field: { name: "errorCode" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field: { name: "errorMessage" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field: { name: "instanceErrorClass" number: 3 label: LABEL_OPTIONAL type: TYPE_INT32 }
+}
+service: {
+  name: "CloudProvider"
+  method: { name: "NodeGroups" input_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupsRequest" output_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupsResponse" }
+  method: { name: "NodeGroupForNode" input_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupForNodeRequest" output_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupForNodeResponse" }
+  method: { name: "GPULabel" input_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.GPULabelRequest" output_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.GPULabelResponse" }
+  method: { name: "GetAvailableGPUTypes" input_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.GetAvailableGPUTypesRequest" output_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.GetAvailableGPUTypesResponse" }
+  method: { name: "Cleanup" input_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.CleanupRequest" output_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.CleanupResponse" }
+  method: { name: "Refresh" input_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.RefreshRequest" output_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.RefreshResponse" }
+  method: { name: "NodeGroupTargetSize" input_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupTargetSizeRequest" output_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupTargetSizeResponse" }
+  method: { name: "NodeGroupIncreaseSize" input_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupIncreaseSizeRequest" output_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupIncreaseSizeResponse" }
+  method: { name: "NodeGroupDeleteNodes" input_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupDeleteNodesRequest" output_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupDeleteNodesResponse" }
+  method: { name: "NodeGroupDecreaseTargetSize" input_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupDecreaseTargetSizeRequest" output_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupDecreaseTargetSizeResponse" }
+  method: { name: "NodeGroupNodes" input_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupNodesRequest" output_type: ".clusterautoscaler.cloudprovider.v1.externalgrpc.NodeGroupNodesResponse" }
+}
+`
+
+func upstreamFile(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+
+	fdp := &descriptorpb.FileDescriptorProto{}
+	if err := prototext.Unmarshal([]byte(upstreamProto), fdp); err != nil {
+		t.Fatalf("cannot parse upstream descriptor: %v", err)
+	}
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("invalid upstream descriptor: %v", err)
+	}
+	return fd
+}
+
+func upstreamMessage(t *testing.T, fd protoreflect.FileDescriptor, name string, text string) *dynamicpb.Message {
+	t.Helper()
+
+	md := fd.Messages().ByName(protoreflect.Name(name))
+	if md == nil {
+		t.Fatalf("message %s is not in the upstream descriptor", name)
+	}
+	msg := dynamicpb.NewMessage(md)
+	if err := prototext.Unmarshal([]byte(text), msg); err != nil {
+		t.Fatalf("cannot parse %s %q: %v", name, text, err)
+	}
+	return msg
+}
+
+func TestCodecUpstreamRoundTrip(t *testing.T) {
+	fd := upstreamFile(t)
+
+	tests := []struct {
+		upstream string  // name of the upstream message
+		msg      message // message of the server
+		text     string  // the same message in upstream text format
+		extra    string  // the same message with upstream fields the server does not use, if any
+	}{
+		{
+			upstream: "NodeGroupsRequest",
+			msg:      &empty{},
+		},
+		{
+			upstream: "GetAvailableGPUTypesResponse",
+			msg:      &empty{},
+			extra:    `gpuTypes: { key: "nvidia" value: {} }`,
+		},
+		{
+			upstream: "NodeGroupsResponse",
+			msg: &nodeGroupsResponse{NodeGroups: []*nodeGroup{
+				{ID: "456", MinSize: 1, MaxSize: 5, Debug: "k8s 123 workers"},
+				{ID: "457", MaxSize: 3},
+			}},
+			text: `nodeGroups: { id: "456" minSize: 1 maxSize: 5 debug: "k8s 123 workers" } nodeGroups: { id: "457" maxSize: 3 }`,
+		},
+		{
+			upstream: "NodeGroupForNodeRequest",
+			msg:      &nodeGroupForNodeRequest{Node: externalGrpcNode{ProviderID: "decort://1234", Name: "worker-1"}},
+			text:     `node: { providerID: "decort://1234" name: "worker-1" }`,
+			extra: `node: { providerID: "decort://1234" name: "worker-1"
+				labels: { key: "role" value: "worker" } annotations: { key: "a" value: "b" } }`,
+		},
+		{
+			upstream: "NodeGroupForNodeResponse",
+			msg:      &nodeGroupForNodeResponse{NodeGroup: nodeGroup{ID: "456", MinSize: 1, MaxSize: 5}},
+			text:     `nodeGroup: { id: "456" minSize: 1 maxSize: 5 }`,
+		},
+		{
+			upstream: "NodeGroupForNodeResponse",
+			msg:      &nodeGroupForNodeResponse{},
+			text:     `nodeGroup: {}`,
+		},
+		{
+			upstream: "GPULabelResponse",
+			msg:      &gpuLabelResponse{Label: "decort/gpu"},
+			text:     `label: "decort/gpu"`,
+		},
+		{
+			upstream: "NodeGroupTargetSizeRequest",
+			msg:      &nodeGroupRequest{ID: "456"},
+			text:     `id: "456"`,
+		},
+		{
+			upstream: "NodeGroupNodesRequest",
+			msg:      &nodeGroupRequest{ID: "456"},
+			text:     `id: "456"`,
+		},
+		{
+			upstream: "NodeGroupTargetSizeResponse",
+			msg:      &nodeGroupTargetSizeResponse{TargetSize: 3},
+			text:     `targetSize: 3`,
+		},
+		{
+			upstream: "NodeGroupIncreaseSizeRequest",
+			msg:      &nodeGroupDeltaRequest{Delta: 2, ID: "456"},
+			text:     `delta: 2 id: "456"`,
+		},
+		{
+			upstream: "NodeGroupDecreaseTargetSizeRequest",
+			msg:      &nodeGroupDeltaRequest{Delta: -2, ID: "456"},
+			text:     `delta: -2 id: "456"`,
+		},
+		{
+			upstream: "NodeGroupDeleteNodesRequest",
+			msg: &nodeGroupDeleteNodesRequest{
+				Nodes: []*externalGrpcNode{{ProviderID: "decort://1234", Name: "worker-1"}, {Name: "worker-2"}},
+				ID:    "456",
+			},
+			text: `nodes: { providerID: "decort://1234" name: "worker-1" } nodes: { name: "worker-2" } id: "456"`,
+		},
+		{
+			upstream: "NodeGroupNodesResponse",
+			msg: &nodeGroupNodesResponse{Instances: []*instance{
+				{ID: "decort://1234", State: instanceRunning},
+				{ID: "decort://1235", State: instanceCreating},
+				{ID: "decort://1236", State: instanceDeleting},
+			}},
+			text: `instances: { id: "decort://1234" status: { instanceState: instanceRunning } }
+				instances: { id: "decort://1235" status: { instanceState: instanceCreating } }
+				instances: { id: "decort://1236" status: { instanceState: instanceDeleting } }`,
+			extra: `instances: { id: "decort://1234" status: { instanceState: instanceRunning
+					errorInfo: { errorCode: "quota" errorMessage: "no quota" instanceErrorClass: 1 } } }
+				instances: { id: "decort://1235" status: { instanceState: instanceCreating } }
+				instances: { id: "decort://1236" status: { instanceState: instanceDeleting } }`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.upstream, func(t *testing.T) {
+			want := upstreamMessage(t, fd, tc.upstream, tc.text)
+
+			// the server encodes, cluster-autoscaler decodes
+			b, err := codec{}.Marshal(tc.msg)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			got := dynamicpb.NewMessage(want.Descriptor())
+			if err := proto.Unmarshal(b, got); err != nil {
+				t.Fatalf("upstream cannot decode %s: %v", tc.upstream, err)
+			}
+			if unknown := got.GetUnknown(); len(unknown) != 0 {
+				t.Errorf("fields unknown to upstream %s: %x", tc.upstream, unknown)
+			}
+			if !proto.Equal(got, want) {
+				t.Errorf("upstream decoded %v, want %v", prototext.Format(got), prototext.Format(want))
+			}
+
+			// cluster-autoscaler encodes, the server decodes and skips the fields it does not use
+			sent := want
+			if tc.extra != "" {
+				sent = upstreamMessage(t, fd, tc.upstream, tc.extra)
+			}
+			b, err = proto.Marshal(sent)
+			if err != nil {
+				t.Fatalf("upstream cannot encode %s: %v", tc.upstream, err)
+			}
+			decoded := reflect.New(reflect.TypeOf(tc.msg).Elem()).Interface()
+			if err := (codec{}).Unmarshal(b, decoded); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tc.msg) {
+				t.Errorf("Unmarshal() = %+v, want %+v", decoded, tc.msg)
+			}
+		})
+	}
+}
+
+func TestCodecInstanceStates(t *testing.T) {
+	fd := upstreamFile(t)
+	states := fd.Messages().ByName("InstanceStatus").Enums().ByName("InstanceState").Values()
+
+	for name, state := range map[protoreflect.Name]int32{
+		"instanceRunning":  instanceRunning,
+		"instanceCreating": instanceCreating,
+		"instanceDeleting": instanceDeleting,
+	} {
+		value := states.ByName(name)
+		if value == nil {
+			t.Errorf("state %s is not in the upstream descriptor", name)
+			continue
+		}
+		if int32(value.Number()) != state {
+			t.Errorf("state %s = %d, upstream %d", name, state, value.Number())
+		}
+	}
+}
+
+func TestServiceDescUpstream(t *testing.T) {
+	fd := upstreamFile(t)
+	service := fd.Services().ByName("CloudProvider")
+
+	if got, want := cloudProviderServiceDesc.ServiceName, string(service.FullName()); got != want {
+		t.Errorf("ServiceName = %s, want %s", got, want)
+	}
+	for _, method := range cloudProviderServiceDesc.Methods {
+		if service.Methods().ByName(protoreflect.Name(method.MethodName)) == nil {
+			t.Errorf("method %s is not in the upstream service", method.MethodName)
+		}
+	}
+	if got, want := len(cloudProviderServiceDesc.Methods), service.Methods().Len(); got != want {
+		t.Errorf("%d methods are served, want %d", got, want)
+	}
+}
+
+func TestCodecErrors(t *testing.T) {
+	if _, err := (codec{}).Marshal("not a message"); err == nil {
+		t.Error("Marshal() of a foreign type succeeded")
+	}
+	if err := (codec{}).Unmarshal(nil, new(string)); err == nil {
+		t.Error("Unmarshal() into a foreign type succeeded")
+	}
+	// a string field truncated in the middle
+	if err := (codec{}).Unmarshal([]byte{0x0a, 0x05, 'a'}, &nodeGroupRequest{}); err == nil {
+		t.Error("Unmarshal() of a truncated message succeeded")
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+decort-autoscaler - cluster-autoscaler external gRPC cloud provider for worker groups
+of a DECORT k8s cluster. The autoscaler adds workers to and deletes workers from the
+groups with the workerAdd and deleteWorkerFromGroup calls of the DECORT API.
+
+Usage:
+
+	decort-autoscaler -controller-url https://ds1.digitalenergy.online \
+		-oauth2-url https://sso.digitalenergy.online -k8s-id 123 \
+		-node-group 1:5:456 -node-group 0:3:457 -address :8086
+
+Each -node-group is <min>:<max>:<worker group id>. min_num and max_num of the corresponding
+decort_k8s_wg resource are the single source of the bounds: pass its autoscaler_node_group
+attribute, which is built from them, as the value of -node-group, so the autoscaler never
+works with sizes the resource does not ignore. Credentials are taken from the same environment variables as the provider
+uses: DECORT_APP_ID, DECORT_APP_SECRET, DECORT_JWT, DECORT_USER, DECORT_PASSWORD.
+
+Run cluster-autoscaler with --cloud-provider=externalgrpc and a cloud config pointing to
+the address of this server. Nodes are matched to workers by provider ID decort://<compute id>
+or, if a node has no provider ID, by name.
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/provider"
+)
+
+// nodeGroupFlags collects repeated -node-group flags
+type nodeGroupFlags []nodeGroupBounds
+
+func (f *nodeGroupFlags) String() string {
+	specs := make([]string, 0, len(*f))
+	for _, g := range *f {
+		specs = append(specs, fmt.Sprintf("%d:%d:%d", g.min, g.max, g.wgId))
+	}
+	return strings.Join(specs, ",")
+}
+
+func (f *nodeGroupFlags) Set(spec string) error {
+	g, err := parseNodeGroupBounds(spec)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, g)
+	return nil
+}
+
+func main() {
+	var nodeGroups nodeGroupFlags
+
+	authenticator := flag.String("authenticator", "oauth2", "authentication mode: oauth2, legacy or jwt")
+	controllerUrl := flag.String("controller-url", os.Getenv("DECORT_CONTROLLER_URL"), "URL of DECORT cloud controller")
+	oauth2Url := flag.String("oauth2-url", os.Getenv("DECORT_OAUTH2_URL"), "OAuth2 application URL")
+	allowUnverifiedSsl := flag.Bool("allow-unverified-ssl", false, "do not verify SSL certificates of the controller")
+	k8sId := flag.Uint64("k8s-id", 0, "ID of the k8s cluster to scale")
+	flag.Var(&nodeGroups, "node-group", "autoscaled worker group as <min>:<max>:<worker group id>, may be repeated")
+	address := flag.String("address", ":8086", "address to listen for cluster-autoscaler requests on")
+	certFile := flag.String("cert", "", "server TLS certificate, TLS is not used if empty")
+	keyFile := flag.String("key", "", "server TLS key")
+	caFile := flag.String("cacert", "", "CA certificate to verify cluster-autoscaler client certificates")
+	debug := flag.Bool("debug", false, "enable debug logging")
+	flag.Parse()
+
+	if *debug {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	if *k8sId == 0 {
+		log.Fatal("k8s-id must be specified")
+	}
+	if len(nodeGroups) == 0 {
+		log.Fatal("at least one node-group must be specified")
+	}
+
+	d := (&schema.Resource{Schema: provider.Provider().Schema}).Data(nil)
+	for key, value := range map[string]interface{}{
+		"authenticator":        *authenticator,
+		"controller_url":       *controllerUrl,
+		"oauth2_url":           *oauth2Url,
+		"allow_unverified_ssl": *allowUnverifiedSsl,
+		"app_id":               os.Getenv("DECORT_APP_ID"),
+		"app_secret":           os.Getenv("DECORT_APP_SECRET"),
+		"jwt":                  os.Getenv("DECORT_JWT"),
+		"user":                 os.Getenv("DECORT_USER"),
+		"password":             os.Getenv("DECORT_PASSWORD"),
+	} {
+		if err := d.Set(key, value); err != nil {
+			log.Fatalf("can not set %s: %v", key, err)
+		}
+	}
+
+	c, err := controller.ControllerConfigure(context.Background(), d)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := []grpc.ServerOption{grpc.ForceServerCodec(codec{})}
+	if *certFile != "" {
+		creds, err := serverCredentials(*certFile, *keyFile, *caFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	listener, err := net.Listen("tcp", *address)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := grpc.NewServer(opts...)
+	server.RegisterService(&cloudProviderServiceDesc, newCloudProvider(c, *k8sId, nodeGroups))
+
+	log.Infof("serving %d node groups of k8s cluster %d on %s", len(nodeGroups), *k8sId, *address)
+	if err := server.Serve(listener); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// serverCredentials returns TLS credentials of the server, client certificates are required if caFile is set
+func serverCredentials(certFile, keyFile, caFile string) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load server certificate: %v", err)
+	}
+
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA certificate: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return credentials.NewTLS(config), nil
+}
@@ -0,0 +1,325 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/k8s"
+)
+
+// providerIdPrefix is the prefix of node provider IDs, kubelets of worker nodes are expected to be
+// started with --provider-id=decort://<compute id>
+const providerIdPrefix = "decort://"
+
+// nodeGroupBounds are the autoscaling bounds of a worker group, taken from autoscaler_node_group of
+// the decort_k8s_wg resource
+type nodeGroupBounds struct {
+	wgId uint64
+	min  int
+	max  int
+}
+
+// parseNodeGroupBounds parses the node group specification in the cluster-autoscaler --nodes form: <min>:<max>:<worker group id>
+func parseNodeGroupBounds(spec string) (nodeGroupBounds, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) != 3 {
+		return nodeGroupBounds{}, fmt.Errorf("invalid node group %q: expected <min>:<max>:<worker group id>", spec)
+	}
+
+	min, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nodeGroupBounds{}, fmt.Errorf("invalid node group %q: min must be a number", spec)
+	}
+	max, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nodeGroupBounds{}, fmt.Errorf("invalid node group %q: max must be a number", spec)
+	}
+	wgId, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return nodeGroupBounds{}, fmt.Errorf("invalid node group %q: worker group id must be a number", spec)
+	}
+	if min < 0 || min > max {
+		return nodeGroupBounds{}, fmt.Errorf("invalid node group %q: min must be between 0 and max", spec)
+	}
+
+	return nodeGroupBounds{wgId: wgId, min: min, max: max}, nil
+}
+
+// cloudProvider implements the external gRPC cloud provider for worker groups of one k8s cluster
+type cloudProvider struct {
+	c      *controller.ControllerCfg
+	k8sId  uint64
+	ids    []string // node group IDs in the order of the command line
+	groups map[string]nodeGroupBounds
+
+	mu      sync.Mutex
+	cluster *k8s.K8SRecord // cached until the next Refresh or change of the cluster
+}
+
+func newCloudProvider(c *controller.ControllerCfg, k8sId uint64, groups []nodeGroupBounds) *cloudProvider {
+	p := &cloudProvider{
+		c:      c,
+		k8sId:  k8sId,
+		groups: make(map[string]nodeGroupBounds, len(groups)),
+	}
+	for _, g := range groups {
+		id := strconv.FormatUint(g.wgId, 10)
+		p.ids = append(p.ids, id)
+		p.groups[id] = g
+	}
+	return p
+}
+
+// clusterGet returns the cluster, p.mu must be held
+func (p *cloudProvider) clusterGet(ctx context.Context) (*k8s.K8SRecord, error) {
+	if p.cluster != nil {
+		return p.cluster, nil
+	}
+
+	urlValues := &url.Values{}
+	urlValues.Add("k8sId", strconv.FormatUint(p.k8sId, 10))
+	resp, err := p.c.DecortAPICall(ctx, "POST", k8s.K8sGetAPI, urlValues)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "cannot get k8s cluster %d: %v", p.k8sId, err)
+	}
+
+	cluster := &k8s.K8SRecord{}
+	if err := json.Unmarshal([]byte(resp), cluster); err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot parse k8s cluster %d: %v", p.k8sId, err)
+	}
+	p.cluster = cluster
+	return cluster, nil
+}
+
+// workerGroupGet returns the worker group of the node group and its bounds, p.mu must be held
+func (p *cloudProvider) workerGroupGet(ctx context.Context, id string) (*k8s.K8SGroup, nodeGroupBounds, error) {
+	bounds, ok := p.groups[id]
+	if !ok {
+		return nil, nodeGroupBounds{}, status.Errorf(codes.NotFound, "node group %s is not managed by the autoscaler", id)
+	}
+
+	cluster, err := p.clusterGet(ctx)
+	if err != nil {
+		return nil, bounds, err
+	}
+	for i := range cluster.K8SGroups.Workers {
+		if cluster.K8SGroups.Workers[i].ID == bounds.wgId {
+			return &cluster.K8SGroups.Workers[i], bounds, nil
+		}
+	}
+	return nil, bounds, status.Errorf(codes.NotFound, "worker group %d is not found in k8s cluster %d", bounds.wgId, p.k8sId)
+}
+
+// workerFind returns the worker of the node, the node is looked up by provider ID and then by name
+func workerFind(wg *k8s.K8SGroup, node *externalGrpcNode) (k8s.DetailedInfo, bool) {
+	for _, worker := range wg.DetailedInfo {
+		if node.ProviderID != "" && node.ProviderID == providerIdPrefix+strconv.FormatUint(worker.ID, 10) {
+			return worker, true
+		}
+		if node.ProviderID == "" && node.Name == worker.Name {
+			return worker, true
+		}
+	}
+	return k8s.DetailedInfo{}, false
+}
+
+func (p *cloudProvider) nodeGroupMake(wg *k8s.K8SGroup, bounds nodeGroupBounds) *nodeGroup {
+	return &nodeGroup{
+		ID:      strconv.FormatUint(bounds.wgId, 10),
+		MinSize: int32(bounds.min),
+		MaxSize: int32(bounds.max),
+		Debug:   fmt.Sprintf("k8s %d worker group %s (%d), %d workers", p.k8sId, wg.Name, wg.ID, wg.Num),
+	}
+}
+
+func (p *cloudProvider) NodeGroups(ctx context.Context) (*nodeGroupsResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	resp := &nodeGroupsResponse{}
+	for _, id := range p.ids {
+		wg, bounds, err := p.workerGroupGet(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		resp.NodeGroups = append(resp.NodeGroups, p.nodeGroupMake(wg, bounds))
+	}
+	return resp, nil
+}
+
+func (p *cloudProvider) NodeGroupForNode(ctx context.Context, req *nodeGroupForNodeRequest) (*nodeGroupForNodeResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	resp := &nodeGroupForNodeResponse{}
+	for _, id := range p.ids {
+		wg, bounds, err := p.workerGroupGet(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := workerFind(wg, &req.Node); ok {
+			resp.NodeGroup = *p.nodeGroupMake(wg, bounds)
+			break
+		}
+	}
+	return resp, nil
+}
+
+func (p *cloudProvider) GPULabel(ctx context.Context) (*gpuLabelResponse, error) {
+	return &gpuLabelResponse{}, nil
+}
+
+func (p *cloudProvider) GetAvailableGPUTypes(ctx context.Context) (*empty, error) {
+	return &empty{}, nil
+}
+
+func (p *cloudProvider) Cleanup(ctx context.Context) (*empty, error) {
+	return &empty{}, nil
+}
+
+func (p *cloudProvider) Refresh(ctx context.Context) (*empty, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.cluster = nil
+	return &empty{}, nil
+}
+
+func (p *cloudProvider) NodeGroupTargetSize(ctx context.Context, req *nodeGroupRequest) (*nodeGroupTargetSizeResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	wg, _, err := p.workerGroupGet(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &nodeGroupTargetSizeResponse{TargetSize: int32(wg.Num)}, nil
+}
+
+func (p *cloudProvider) NodeGroupIncreaseSize(ctx context.Context, req *nodeGroupDeltaRequest) (*empty, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	wg, bounds, err := p.workerGroupGet(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Delta <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "size increase must be positive, got %d", req.Delta)
+	}
+	if int(wg.Num)+int(req.Delta) > bounds.max {
+		return nil, status.Errorf(codes.FailedPrecondition, "size of node group %s would exceed max %d", req.ID, bounds.max)
+	}
+
+	log.Infof("adding %d workers to worker group %s (%d)", req.Delta, wg.Name, wg.ID)
+	urlValues := &url.Values{}
+	urlValues.Add("k8sId", strconv.FormatUint(p.k8sId, 10))
+	urlValues.Add("workersGroupId", strconv.FormatUint(wg.ID, 10))
+	urlValues.Add("num", strconv.Itoa(int(req.Delta)))
+	_, err = p.c.DecortAPICall(ctx, "POST", k8s.K8sWorkerAddAPI, urlValues)
+	p.cluster = nil
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "cannot add workers to worker group %d: %v", wg.ID, err)
+	}
+
+	return &empty{}, nil
+}
+
+func (p *cloudProvider) NodeGroupDeleteNodes(ctx context.Context, req *nodeGroupDeleteNodesRequest) (*empty, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	wg, bounds, err := p.workerGroupGet(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if int(wg.Num)-len(req.Nodes) < bounds.min {
+		return nil, status.Errorf(codes.FailedPrecondition, "size of node group %s would be less than min %d", req.ID, bounds.min)
+	}
+
+	workers := make([]k8s.DetailedInfo, 0, len(req.Nodes))
+	for _, node := range req.Nodes {
+		worker, ok := workerFind(wg, node)
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "node %s (%s) does not belong to node group %s", node.Name, node.ProviderID, req.ID)
+		}
+		workers = append(workers, worker)
+	}
+
+	// the cached cluster is outdated as soon as the first worker is deleted
+	defer func() { p.cluster = nil }()
+	for _, worker := range workers {
+		log.Infof("deleting worker %s (%d) from worker group %s (%d)", worker.Name, worker.ID, wg.Name, wg.ID)
+		urlValues := &url.Values{}
+		urlValues.Add("k8sId", strconv.FormatUint(p.k8sId, 10))
+		urlValues.Add("workersGroupId", strconv.FormatUint(wg.ID, 10))
+		urlValues.Add("workerId", strconv.FormatUint(worker.ID, 10))
+		if _, err := p.c.DecortAPICall(ctx, "POST", k8s.K8sWorkerDeleteAPI, urlValues); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "cannot delete worker %d: %v", worker.ID, err)
+		}
+	}
+
+	return &empty{}, nil
+}
+
+// NodeGroupDecreaseTargetSize is called for nodes which were requested but not registered in the
+// cluster yet. Size of a worker group is the number of its computes, there is no target to decrease
+// without deleting them, so the autoscaler has to delete such nodes with NodeGroupDeleteNodes.
+func (p *cloudProvider) NodeGroupDecreaseTargetSize(ctx context.Context, req *nodeGroupDeltaRequest) (*empty, error) {
+	return nil, status.Errorf(codes.FailedPrecondition, "target size of node group %s equals to the number of its workers and cannot be decreased", req.ID)
+}
+
+func (p *cloudProvider) NodeGroupNodes(ctx context.Context, req *nodeGroupRequest) (*nodeGroupNodesResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	wg, _, err := p.workerGroupGet(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &nodeGroupNodesResponse{}
+	for _, worker := range wg.DetailedInfo {
+		state := int32(instanceCreating)
+		switch {
+		case worker.Status == "DELETING" || worker.Status == "DESTROYING" || worker.TechStatus == "STOPPING":
+			state = instanceDeleting
+		case worker.TechStatus == "STARTED":
+			state = instanceRunning
+		}
+		resp.Instances = append(resp.Instances, &instance{
+			ID:    providerIdPrefix + strconv.FormatUint(worker.ID, 10),
+			State: state,
+		})
+	}
+	return resp, nil
+}
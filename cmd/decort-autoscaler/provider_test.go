@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package main
+
+import "testing"
+
+func TestParseNodeGroupBounds(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    nodeGroupBounds
+		wantErr bool
+	}{
+		{spec: "1:5:456", want: nodeGroupBounds{wgId: 456, min: 1, max: 5}},
+		{spec: "0:0:456", want: nodeGroupBounds{wgId: 456}},
+		{spec: "3:3:456", want: nodeGroupBounds{wgId: 456, min: 3, max: 3}},
+		{spec: "1:5", wantErr: true},
+		{spec: "1:5:456:7", wantErr: true},
+		{spec: "a:5:456", wantErr: true},
+		{spec: "1:b:456", wantErr: true},
+		{spec: "1:5:-456", wantErr: true},
+		{spec: "-1:5:456", wantErr: true},
+		{spec: "6:5:456", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			got, err := parseNodeGroupBounds(tc.spec)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseNodeGroupBounds() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("parseNodeGroupBounds() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+decort-cost - estimate how a saved Terraform plan changes the cost of DECORT resources.
+
+Usage:
+
+	terraform plan -out plan.tfplan
+	terraform show -json plan.tfplan > plan.json
+	decort-cost -plan plan.json -prices prices.json
+
+The price table is keyed by CU type the same way as in the decort_cost_estimate data
+source, CU_D prices may be overridden for SEP pools:
+
+	{
+		"currency": "RUB",
+		"units": {"CU_C": 500, "CU_M": 0.3, "CU_D": 5, "CU_I": 200},
+		"pools": [{"sep_id": 1, "pool": "ssd", "price": 12}]
+	}
+
+Computes, disks, k8s clusters and worker groups, and resource groups are counted.
+Attributes known only after apply are treated as unset and reported.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/rudecs/terraform-provider-decort/internal/cost"
+)
+
+type resourceDelta struct {
+	Address string   `json:"address"`
+	Type    string   `json:"type"`
+	Actions []string `json:"actions"`
+	Before  float64  `json:"before"`
+	After   float64  `json:"after"`
+	Delta   float64  `json:"delta"`
+	Unknown []string `json:"unknown,omitempty"`
+}
+
+type report struct {
+	Currency  string          `json:"currency"`
+	Resources []resourceDelta `json:"resources"`
+	Before    float64         `json:"before"`
+	After     float64         `json:"after"`
+	Delta     float64         `json:"delta"`
+}
+
+func main() {
+	planFile := flag.String("plan", "", "plan in JSON format produced by \"terraform show -json\"")
+	pricesFile := flag.String("prices", "", "price table in JSON format")
+	format := flag.String("format", "text", "output format: text or json")
+	debug := flag.Bool("debug", false, "enable debug logging")
+	flag.Parse()
+
+	if *debug {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	if *planFile == "" || *pricesFile == "" {
+		log.Fatal("plan and prices must be specified")
+	}
+
+	prices := cost.Prices{}
+	if err := readJSON(*pricesFile, &prices); err != nil {
+		log.Fatalf("Failed to read prices: %v", err)
+	}
+	plan := tfPlan{}
+	if err := readJSON(*planFile, &plan); err != nil {
+		log.Fatalf("Failed to read plan: %v", err)
+	}
+
+	rep := estimatePlan(plan, prices)
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			log.Fatal(err)
+		}
+	case "text":
+		printReport(rep)
+	default:
+		log.Fatalf("Unknown format %q", *format)
+	}
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func estimatePlan(plan tfPlan, prices cost.Prices) report {
+	rep := report{Currency: prices.Currency, Resources: []resourceDelta{}}
+	for _, change := range plan.ResourceChanges {
+		if change.Mode != "" && change.Mode != "managed" {
+			continue
+		}
+		if _, ok := usageFuncs[change.Type]; !ok {
+			continue
+		}
+		if len(change.Change.Actions) == 1 && change.Change.Actions[0] == "no-op" {
+			continue
+		}
+
+		before, _ := resourceUsage(change.Type, change.Change.Before, nil)
+		after, unknown := resourceUsage(change.Type, change.Change.After, change.Change.AfterUnknown)
+		_, beforeCost := prices.Estimate(before)
+		_, afterCost := prices.Estimate(after)
+		log.Debugf("estimatePlan: %s before %v after %v", change.Address, beforeCost, afterCost)
+
+		rep.Resources = append(rep.Resources, resourceDelta{
+			Address: change.Address,
+			Type:    change.Type,
+			Actions: change.Change.Actions,
+			Before:  beforeCost,
+			After:   afterCost,
+			Delta:   afterCost - beforeCost,
+			Unknown: unknown,
+		})
+		rep.Before += beforeCost
+		rep.After += afterCost
+	}
+	rep.Delta = rep.After - rep.Before
+	return rep
+}
+
+func printReport(rep report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tBEFORE\tAFTER\tDELTA")
+	for _, res := range rep.Resources {
+		fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%+.2f\n", res.Address, res.Before, res.After, res.Delta)
+	}
+	fmt.Fprintf(w, "TOTAL\t%.2f\t%.2f\t%+.2f %s\n", rep.Before, rep.After, rep.Delta, rep.Currency)
+	w.Flush()
+
+	for _, res := range rep.Resources {
+		if len(res.Unknown) != 0 {
+			fmt.Fprintf(os.Stderr, "%s: %v known only after apply, the estimate may be inaccurate\n", res.Address, res.Unknown)
+		}
+	}
+}
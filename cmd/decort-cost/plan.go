@@ -0,0 +1,200 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rudecs/terraform-provider-decort/internal/cost"
+)
+
+// tfPlan is the part of the "terraform show -json" output the estimate needs
+type tfPlan struct {
+	ResourceChanges []tfResourceChange `json:"resource_changes"`
+}
+
+type tfResourceChange struct {
+	Address string `json:"address"`
+	Mode    string `json:"mode"`
+	Type    string `json:"type"`
+	Change  struct {
+		Actions      []string               `json:"actions"`
+		Before       map[string]interface{} `json:"before"`
+		After        map[string]interface{} `json:"after"`
+		AfterUnknown map[string]interface{} `json:"after_unknown"`
+	} `json:"change"`
+}
+
+// usageFuncs calculate resources of the DECORT resource from its planned attributes
+var usageFuncs = map[string]func(attrs attributes) cost.Usage{
+	"decort_kvmvm":    computeUsage,
+	"decort_disk":     diskUsage,
+	"decort_k8s":      k8sUsage,
+	"decort_k8s_wg":   k8sWgUsage,
+	"decort_resgroup": resgroupUsage,
+}
+
+// usageAttrs are the attributes the usage of the resource is calculated from
+var usageAttrs = map[string][]string{
+	"decort_kvmvm":    {"cpu", "ram", "boot_disk_size", "sep_id", "pool", "network"},
+	"decort_disk":     {"size_max", "sep_id", "pool"},
+	"decort_k8s":      {"masters", "workers", "with_lb"},
+	"decort_k8s_wg":   {"num", "cpu", "ram", "disk", "sep_id", "sep_pool"},
+	"decort_resgroup": {"def_net_type"},
+}
+
+// resourceUsage returns the usage of the resource, and the attributes the usage depends
+// on whose values are not known until apply. A nil state, e.g. before create or after
+// destroy, means no usage.
+func resourceUsage(resType string, state map[string]interface{}, unknown map[string]interface{}) (cost.Usage, []string) {
+	var unknownAttrs []string
+	for _, attr := range usageAttrs[resType] {
+		if isUnknown(unknown[attr]) {
+			unknownAttrs = append(unknownAttrs, attr)
+		}
+	}
+	sort.Strings(unknownAttrs)
+
+	if state == nil {
+		return cost.Usage{}, unknownAttrs
+	}
+	return usageFuncs[resType](attributes(state)), unknownAttrs
+}
+
+// isUnknown reports whether the after_unknown value marks the attribute, or any of its
+// nested attributes, as unknown
+func isUnknown(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case []interface{}:
+		for _, item := range v {
+			if isUnknown(item) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			if isUnknown(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type attributes map[string]interface{}
+
+func (a attributes) int(key string) int {
+	if value, ok := a[key].(float64); ok {
+		return int(value)
+	}
+	return 0
+}
+
+func (a attributes) string(key string) string {
+	if value, ok := a[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+func (a attributes) bool(key string) bool {
+	if value, ok := a[key].(bool); ok {
+		return value
+	}
+	return false
+}
+
+func (a attributes) list(key string) []attributes {
+	items, _ := a[key].([]interface{})
+	res := make([]attributes, 0, len(items))
+	for _, item := range items {
+		if attrs, ok := item.(map[string]interface{}); ok {
+			res = append(res, attributes(attrs))
+		}
+	}
+	return res
+}
+
+// addDisk counts disk space by the SEP pool, or by CU_D when the SEP is left to the
+// platform default
+func addDisk(usage *cost.Usage, sepId int, pool string, size float64) {
+	if sepId == 0 {
+		usage.Add(cost.CUDisk, size)
+		return
+	}
+	usage.Disks = append(usage.Disks, cost.DiskUsage{SepID: sepId, Pool: pool, Size: size})
+}
+
+func computeUsage(attrs attributes) cost.Usage {
+	usage := cost.Usage{}
+	usage.Add(cost.CUCPU, float64(attrs.int("cpu")))
+	usage.Add(cost.CURAM, float64(attrs.int("ram")))
+	addDisk(&usage, attrs.int("sep_id"), attrs.string("pool"), float64(attrs.int("boot_disk_size")))
+	for _, network := range attrs.list("network") {
+		if strings.ToUpper(network.string("net_type")) == "EXTNET" {
+			usage.Add(cost.CUExtIP, 1)
+		}
+	}
+	return usage
+}
+
+func diskUsage(attrs attributes) cost.Usage {
+	usage := cost.Usage{}
+	addDisk(&usage, attrs.int("sep_id"), attrs.string("pool"), float64(attrs.int("size_max")))
+	return usage
+}
+
+// nodeGroupUsage adds the usage of a k8s master or worker group, the group attributes
+// are the same in decort_k8s and decort_k8s_wg
+func nodeGroupUsage(usage *cost.Usage, group attributes) {
+	num := group.int("num")
+	usage.Add(cost.CUCPU, float64(num*group.int("cpu")))
+	usage.Add(cost.CURAM, float64(num*group.int("ram")))
+	addDisk(usage, group.int("sep_id"), group.string("sep_pool"), float64(num*group.int("disk")))
+}
+
+func k8sUsage(attrs attributes) cost.Usage {
+	usage := cost.Usage{}
+	for _, group := range append(attrs.list("masters"), attrs.list("workers")...) {
+		nodeGroupUsage(&usage, group)
+	}
+	if attrs.bool("with_lb") {
+		usage.Add(cost.CUExtIP, 1)
+	}
+	return usage
+}
+
+func k8sWgUsage(attrs attributes) cost.Usage {
+	usage := cost.Usage{}
+	nodeGroupUsage(&usage, attrs)
+	return usage
+}
+
+func resgroupUsage(attrs attributes) cost.Usage {
+	usage := cost.Usage{}
+	// the ViNS of a resource group with a private network gets an external IP address
+	if strings.ToUpper(attrs.string("def_net_type")) == "PRIVATE" {
+		usage.Add(cost.CUExtIP, 1)
+	}
+	return usage
+}
@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/rudecs/terraform-provider-decort/internal/cost"
+)
+
+func TestResourceUsage(t *testing.T) {
+	tests := []struct {
+		name    string
+		resType string
+		state   string
+		want    cost.Usage
+	}{
+		{
+			name:    "compute",
+			resType: "decort_kvmvm",
+			state:   `{"cpu": 2, "ram": 4096, "boot_disk_size": 20, "sep_id": 1, "pool": "ssd", "network": [{"net_type": "EXTNET"}, {"net_type": "VINS"}]}`,
+			want: cost.Usage{
+				Units: map[string]float64{cost.CUCPU: 2, cost.CURAM: 4096, cost.CUExtIP: 1},
+				Disks: []cost.DiskUsage{{SepID: 1, Pool: "ssd", Size: 20}},
+			},
+		},
+		{
+			name:    "disk in the default SEP",
+			resType: "decort_disk",
+			state:   `{"size_max": 50}`,
+			want:    cost.Usage{Units: map[string]float64{cost.CUDisk: 50}},
+		},
+		{
+			name:    "k8s disks by pool",
+			resType: "decort_k8s",
+			state:   `{"with_lb": true, "masters": [{"num": 1, "cpu": 2, "ram": 2048, "disk": 10, "sep_id": 1, "sep_pool": "ssd"}], "workers": [{"num": 3, "cpu": 4, "ram": 8192, "disk": 20, "sep_id": 2, "sep_pool": "hdd"}]}`,
+			want: cost.Usage{
+				Units: map[string]float64{cost.CUCPU: 14, cost.CURAM: 26624, cost.CUExtIP: 1},
+				Disks: []cost.DiskUsage{{SepID: 1, Pool: "ssd", Size: 10}, {SepID: 2, Pool: "hdd", Size: 60}},
+			},
+		},
+		{
+			name:    "k8s worker group",
+			resType: "decort_k8s_wg",
+			state:   `{"num": 2, "cpu": 1, "ram": 1024, "disk": 15, "sep_id": 3, "sep_pool": "nvme"}`,
+			want: cost.Usage{
+				Units: map[string]float64{cost.CUCPU: 2, cost.CURAM: 2048},
+				Disks: []cost.DiskUsage{{SepID: 3, Pool: "nvme", Size: 30}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			state := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(tc.state), &state); err != nil {
+				t.Fatal(err)
+			}
+			usage, _ := resourceUsage(tc.resType, state, nil)
+			if !reflect.DeepEqual(usage, tc.want) {
+				t.Errorf("resourceUsage() = %+v, want %+v", usage, tc.want)
+			}
+		})
+	}
+}
+
+func TestResourceUsageUnknown(t *testing.T) {
+	state := map[string]interface{}{"num": 1.0}
+	unknown := map[string]interface{}{"sep_pool": true, "disk": false}
+	_, unknownAttrs := resourceUsage("decort_k8s_wg", state, unknown)
+	if !reflect.DeepEqual(unknownAttrs, []string{"sep_pool"}) {
+		t.Errorf("resourceUsage() unknown = %v, want [sep_pool]", unknownAttrs)
+	}
+}
@@ -0,0 +1,377 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	log "github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/account"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/disks"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/kvmvm"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/lb"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/rg"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/vins"
+	"github.com/rudecs/terraform-provider-decort/internal/status"
+	"github.com/rudecs/terraform-provider-decort/internal/techstatus"
+)
+
+// importBlock is a single platform object rendered as an import block
+// followed by the resource block it is imported into
+type importBlock struct {
+	resType string
+	label   string
+	id      string
+	body    func(b *hclwrite.Body)
+}
+
+type generator struct {
+	c         *controller.ControllerCfg
+	accountId int
+}
+
+var labelInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// resourceLabel builds a label, which is unique within the resource type,
+// from the object name and its ID
+func resourceLabel(name string, id uint64) string {
+	label := strings.Trim(labelInvalidChars.ReplaceAllString(strings.ToLower(name), "_"), "_-")
+	if label == "" || (label[0] >= '0' && label[0] <= '9') {
+		label = "obj_" + label
+	}
+	return fmt.Sprintf("%s_%d", strings.TrimSuffix(label, "_"), id)
+}
+
+func (g *generator) call(ctx context.Context, api string, urlValues *url.Values, out interface{}) error {
+	resp, err := g.c.DecortAPICall(ctx, "POST", api, urlValues)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(resp), out); err != nil {
+		return fmt.Errorf("can not unmarshal response of %s: %w", api, err)
+	}
+
+	return nil
+}
+
+func (g *generator) accountValues() *url.Values {
+	urlValues := &url.Values{}
+	urlValues.Add("accountId", strconv.Itoa(g.accountId))
+	return urlValues
+}
+
+func (g *generator) resgroups(ctx context.Context) ([]importBlock, map[uint64]bool, error) {
+	rgList := account.AccountRGList{}
+	if err := g.call(ctx, accountListRGAPI, g.accountValues(), &rgList); err != nil {
+		return nil, nil, err
+	}
+
+	blocks := make([]importBlock, 0, len(rgList))
+	rgIds := make(map[uint64]bool, len(rgList))
+	for _, item := range rgList {
+		urlValues := &url.Values{}
+		urlValues.Add("rgId", strconv.Itoa(item.RGID))
+
+		rgRecord := rg.ResgroupGetResp{}
+		if err := g.call(ctx, rgGetAPI, urlValues, &rgRecord); err != nil {
+			return nil, nil, err
+		}
+		log.Debugf("resgroups: found rg %d %q", rgRecord.ID, rgRecord.Name)
+
+		rgIds[uint64(rgRecord.ID)] = true
+		blocks = append(blocks, importBlock{
+			resType: "decort_resgroup",
+			label:   resourceLabel(rgRecord.Name, uint64(rgRecord.ID)),
+			id:      strconv.FormatUint(uint64(rgRecord.ID), 10),
+			body: func(b *hclwrite.Body) {
+				b.SetAttributeValue("account_id", cty.NumberIntVal(int64(rgRecord.AccountID)))
+				b.SetAttributeValue("gid", cty.NumberIntVal(int64(rgRecord.GridID)))
+				b.SetAttributeValue("name", cty.StringVal(rgRecord.Name))
+				if rgRecord.Desc != "" {
+					b.SetAttributeValue("description", cty.StringVal(rgRecord.Desc))
+				}
+				if rgRecord.DefaultNetType != "" {
+					b.SetAttributeValue("def_net_type", cty.StringVal(rgRecord.DefaultNetType))
+				}
+			},
+		})
+	}
+
+	return blocks, rgIds, nil
+}
+
+// lbs returns load balancers of the account resource groups and the IDs of
+// their node computes, which are managed by the platform and must not be imported
+func (g *generator) lbs(ctx context.Context, rgIds map[uint64]bool) ([]importBlock, map[uint64]bool, error) {
+	lbList := lb.LBList{}
+	if err := g.call(ctx, lbListAPI, &url.Values{}, &lbList); err != nil {
+		return nil, nil, err
+	}
+
+	blocks := make([]importBlock, 0, len(lbList))
+	nodes := make(map[uint64]bool)
+	for _, item := range lbList {
+		lbRecord := item.LoadBalancer
+		if !rgIds[lbRecord.RGID] {
+			continue
+		}
+		log.Debugf("lbs: found lb %d %q", lbRecord.ID, lbRecord.Name)
+
+		nodes[lbRecord.PrimaryNode.ComputeId] = true
+		nodes[lbRecord.SecondaryNode.ComputeId] = true
+		blocks = append(blocks, importBlock{
+			resType: "decort_lb",
+			label:   resourceLabel(lbRecord.Name, lbRecord.ID),
+			id:      strconv.FormatUint(lbRecord.ID, 10),
+			body: func(b *hclwrite.Body) {
+				b.SetAttributeValue("rg_id", cty.NumberUIntVal(lbRecord.RGID))
+				b.SetAttributeValue("name", cty.StringVal(lbRecord.Name))
+				b.SetAttributeValue("extnet_id", cty.NumberUIntVal(lbRecord.ExtnetId))
+				b.SetAttributeValue("vins_id", cty.NumberUIntVal(lbRecord.VinsId))
+				b.SetAttributeValue("start", cty.BoolVal(lbRecord.TechStatus == techstatus.Started))
+				if lbRecord.Description != "" {
+					b.SetAttributeValue("desc", cty.StringVal(lbRecord.Description))
+				}
+				if lbRecord.HAMode {
+					b.SetAttributeValue("ha_mode", cty.True)
+				}
+			},
+		})
+	}
+
+	return blocks, nodes, nil
+}
+
+func (g *generator) computes(ctx context.Context, skip map[uint64]bool) ([]importBlock, error) {
+	computeList := account.AccountComputesList{}
+	if err := g.call(ctx, accountListComputesAPI, g.accountValues(), &computeList); err != nil {
+		return nil, err
+	}
+
+	blocks := make([]importBlock, 0, len(computeList))
+	for _, item := range computeList {
+		if !item.UserManaged || skip[uint64(item.ComputeId)] {
+			log.Debugf("computes: skip platform managed compute %d", item.ComputeId)
+			continue
+		}
+
+		urlValues := &url.Values{}
+		urlValues.Add("computeId", strconv.Itoa(item.ComputeId))
+
+		compute := kvmvm.ComputeGetResp{}
+		if err := g.call(ctx, computeGetAPI, urlValues, &compute); err != nil {
+			return nil, err
+		}
+		log.Debugf("computes: found compute %d %q", compute.ID, compute.Name)
+
+		blocks = append(blocks, importBlock{
+			resType: "decort_kvmvm",
+			label:   resourceLabel(compute.Name, uint64(compute.ID)),
+			id:      strconv.FormatUint(uint64(compute.ID), 10),
+			body: func(b *hclwrite.Body) {
+				imageId := compute.ImageID
+				if compute.VirtualImageID != 0 {
+					imageId = compute.VirtualImageID
+				}
+
+				b.SetAttributeValue("name", cty.StringVal(compute.Name))
+				b.SetAttributeValue("rg_id", cty.NumberIntVal(int64(compute.RgID)))
+				b.SetAttributeValue("driver", cty.StringVal(compute.Driver))
+				b.SetAttributeValue("cpu", cty.NumberIntVal(int64(compute.Cpu)))
+				b.SetAttributeValue("ram", cty.NumberIntVal(int64(compute.Ram)))
+				b.SetAttributeValue("image_id", cty.NumberIntVal(int64(imageId)))
+				if compute.Desc != "" {
+					b.SetAttributeValue("description", cty.StringVal(compute.Desc))
+				}
+				b.SetAttributeValue("started", cty.BoolVal(compute.TechStatus == techstatus.Started))
+
+				extraDisks := make([]cty.Value, 0)
+				for _, disk := range compute.Disks {
+					switch disk.Type {
+					case "B":
+						b.SetAttributeValue("boot_disk_size", cty.NumberIntVal(int64(disk.SizeMax)))
+						b.SetAttributeValue("sep_id", cty.NumberIntVal(int64(disk.SepID)))
+						b.SetAttributeValue("pool", cty.StringVal(disk.Pool))
+					case "D":
+						extraDisks = append(extraDisks, cty.NumberUIntVal(uint64(disk.ID)))
+					}
+				}
+				if len(extraDisks) != 0 {
+					b.SetAttributeValue("extra_disks", cty.SetVal(extraDisks))
+				}
+
+				for _, iface := range compute.Interfaces {
+					b.AppendNewline()
+					network := b.AppendNewBlock("network", nil).Body()
+					network.SetAttributeValue("net_type", cty.StringVal(iface.NetType))
+					network.SetAttributeValue("net_id", cty.NumberIntVal(int64(iface.NetID)))
+					if iface.IPAddress != "" {
+						network.SetAttributeValue("ip_address", cty.StringVal(iface.IPAddress))
+					}
+				}
+			},
+		})
+	}
+
+	return blocks, nil
+}
+
+func (g *generator) disks(ctx context.Context) ([]importBlock, error) {
+	diskList := account.AccountDisksList{}
+	if err := g.call(ctx, accountListDisksAPI, g.accountValues(), &diskList); err != nil {
+		return nil, err
+	}
+
+	blocks := make([]importBlock, 0, len(diskList))
+	for _, item := range diskList {
+		// boot disks are managed through decort_kvmvm
+		if item.Type == "B" {
+			continue
+		}
+
+		urlValues := &url.Values{}
+		urlValues.Add("diskId", strconv.Itoa(item.ID))
+
+		disk := disks.Disk{}
+		if err := g.call(ctx, disksGetAPI, urlValues, &disk); err != nil {
+			return nil, err
+		}
+		log.Debugf("disks: found disk %d %q", disk.ID, disk.Name)
+
+		blocks = append(blocks, importBlock{
+			resType: "decort_disk",
+			label:   resourceLabel(disk.Name, uint64(disk.ID)),
+			id:      strconv.FormatUint(uint64(disk.ID), 10),
+			body: func(b *hclwrite.Body) {
+				b.SetAttributeValue("account_id", cty.NumberIntVal(int64(disk.AccountID)))
+				b.SetAttributeValue("gid", cty.NumberIntVal(int64(disk.GridID)))
+				b.SetAttributeValue("disk_name", cty.StringVal(disk.Name))
+				b.SetAttributeValue("size_max", cty.NumberIntVal(int64(disk.SizeMax)))
+				b.SetAttributeValue("type", cty.StringVal(disk.Type))
+				b.SetAttributeValue("sep_id", cty.NumberIntVal(int64(disk.SepID)))
+				b.SetAttributeValue("pool", cty.StringVal(disk.Pool))
+				if disk.Desc != "" {
+					b.SetAttributeValue("desc", cty.StringVal(disk.Desc))
+				}
+			},
+		})
+	}
+
+	return blocks, nil
+}
+
+func (g *generator) vins(ctx context.Context) ([]importBlock, error) {
+	vinsList := account.AccountVinsList{}
+	if err := g.call(ctx, accountListVinsAPI, g.accountValues(), &vinsList); err != nil {
+		return nil, err
+	}
+
+	blocks := make([]importBlock, 0, len(vinsList))
+	for _, item := range vinsList {
+		urlValues := &url.Values{}
+		urlValues.Add("vinsId", strconv.Itoa(item.ID))
+
+		vinsRecord := vins.VINSDetailed{}
+		if err := g.call(ctx, vinsGetAPI, urlValues, &vinsRecord); err != nil {
+			return nil, err
+		}
+		if !vinsRecord.UserManaged {
+			log.Debugf("vins: skip platform managed vins %d", vinsRecord.ID)
+			continue
+		}
+		log.Debugf("vins: found vins %d %q", vinsRecord.ID, vinsRecord.Name)
+
+		blocks = append(blocks, importBlock{
+			resType: "decort_vins",
+			label:   resourceLabel(vinsRecord.Name, vinsRecord.ID),
+			id:      strconv.FormatUint(vinsRecord.ID, 10),
+			body: func(b *hclwrite.Body) {
+				b.SetAttributeValue("name", cty.StringVal(vinsRecord.Name))
+				if vinsRecord.RGID != 0 {
+					b.SetAttributeValue("rg_id", cty.NumberUIntVal(vinsRecord.RGID))
+				} else {
+					b.SetAttributeValue("account_id", cty.NumberUIntVal(vinsRecord.AccountID))
+				}
+				if vinsRecord.VNFS.GW.Config.ExtNetID != 0 {
+					b.SetAttributeValue("ext_net_id", cty.NumberUIntVal(vinsRecord.VNFS.GW.Config.ExtNetID))
+				}
+				if vinsRecord.Network != "" {
+					b.SetAttributeValue("ipcidr", cty.StringVal(vinsRecord.Network))
+				}
+				b.SetAttributeValue("enable", cty.BoolVal(vinsRecord.Status == status.Enabled))
+				if vinsRecord.Description != "" {
+					b.SetAttributeValue("desc", cty.StringVal(vinsRecord.Description))
+				}
+			},
+		})
+	}
+
+	return blocks, nil
+}
+
+// render writes import blocks and resource configurations of the given
+// objects into a single HCL file
+func render(blocks []importBlock) []byte {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	for i, block := range blocks {
+		if i != 0 {
+			body.AppendNewline()
+		}
+
+		imp := body.AppendNewBlock("import", nil).Body()
+		imp.SetAttributeTraversal("to", hcl.Traversal{
+			hcl.TraverseRoot{Name: block.resType},
+			hcl.TraverseAttr{Name: block.label},
+		})
+		imp.SetAttributeValue("id", cty.StringVal(block.id))
+		body.AppendNewline()
+
+		res := body.AppendNewBlock("resource", []string{block.resType, block.label}).Body()
+		block.body(res)
+	}
+
+	return hclwrite.Format(f.Bytes())
+}
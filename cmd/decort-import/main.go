@@ -0,0 +1,155 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+decort-import - generate Terraform configuration with import blocks for the
+computes, disks, ViNS, resource groups and load balancers of an existing
+DECORT account.
+
+Usage:
+
+	decort-import -controller-url https://ds1.digitalenergy.online \
+		-oauth2-url https://sso.digitalenergy.online -account-id 123 -out ./imported
+
+Credentials are taken from the same environment variables as the provider uses:
+DECORT_APP_ID, DECORT_APP_SECRET, DECORT_JWT, DECORT_USER, DECORT_PASSWORD.
+After generation run "terraform plan" in the output directory to import the objects.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/provider"
+)
+
+func main() {
+	authenticator := flag.String("authenticator", "oauth2", "authentication mode: oauth2, legacy or jwt")
+	controllerUrl := flag.String("controller-url", os.Getenv("DECORT_CONTROLLER_URL"), "URL of DECORT cloud controller")
+	oauth2Url := flag.String("oauth2-url", os.Getenv("DECORT_OAUTH2_URL"), "OAuth2 application URL")
+	allowUnverifiedSsl := flag.Bool("allow-unverified-ssl", false, "do not verify SSL certificates of the controller")
+	accountId := flag.Int("account-id", 0, "ID of the account to generate configuration for")
+	outDir := flag.String("out", ".", "directory to write generated .tf files to")
+	force := flag.Bool("force", false, "overwrite existing files in the output directory")
+	debug := flag.Bool("debug", false, "enable debug logging")
+	flag.Parse()
+
+	if *debug {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	if *accountId == 0 {
+		log.Fatal("account-id must be specified")
+	}
+
+	d := (&schema.Resource{Schema: provider.Provider().Schema}).Data(nil)
+	for key, value := range map[string]interface{}{
+		"authenticator":        *authenticator,
+		"controller_url":       *controllerUrl,
+		"oauth2_url":           *oauth2Url,
+		"allow_unverified_ssl": *allowUnverifiedSsl,
+		"app_id":               os.Getenv("DECORT_APP_ID"),
+		"app_secret":           os.Getenv("DECORT_APP_SECRET"),
+		"jwt":                  os.Getenv("DECORT_JWT"),
+		"user":                 os.Getenv("DECORT_USER"),
+		"password":             os.Getenv("DECORT_PASSWORD"),
+	} {
+		if err := d.Set(key, value); err != nil {
+			log.Fatalf("can not set %s: %v", key, err)
+		}
+	}
+
+	c, err := controller.ControllerConfigure(context.Background(), d)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := run(context.Background(), &generator{c: c, accountId: *accountId}, *outDir, *force); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, g *generator, outDir string, force bool) error {
+	resgroups, rgIds, err := g.resgroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	lbs, lbNodes, err := g.lbs(ctx, rgIds)
+	if err != nil {
+		return err
+	}
+
+	computes, err := g.computes(ctx, lbNodes)
+	if err != nil {
+		return err
+	}
+
+	disks, err := g.disks(ctx)
+	if err != nil {
+		return err
+	}
+
+	vins, err := g.vins(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name   string
+		blocks []importBlock
+	}{
+		{"resgroups.tf", resgroups},
+		{"vins.tf", vins},
+		{"disks.tf", disks},
+		{"computes.tf", computes},
+		{"lbs.tf", lbs},
+	}
+
+	for _, f := range files {
+		if len(f.blocks) == 0 {
+			continue
+		}
+
+		path := filepath.Join(outDir, f.name)
+		if _, err := os.Stat(path); err == nil && !force {
+			return fmt.Errorf("file %s already exists, use -force to overwrite it", path)
+		}
+
+		if err := os.WriteFile(path, render(f.blocks), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("%s: %d resources\n", path, len(f.blocks))
+	}
+
+	return nil
+}
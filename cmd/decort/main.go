@@ -32,8 +32,6 @@ Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
 package main
 
 import (
-	log "github.com/sirupsen/logrus"
-
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 
@@ -43,9 +41,6 @@ import (
 //go:generate go run github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs
 
 func main() {
-	log.SetLevel(log.DebugLevel)
-	log.Debug("Debug logging enabled")
-
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: func() *schema.Provider {
 			return provider.Provider()
@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// API calls are split into cacheable reads, other reads, which neither use nor drop the
+// cache, and mutating calls
+const (
+	apiKindWrite = iota
+	apiKindRead
+	apiKindQuery
+)
+
+// apiQueries are read-only calls that are not cached, their results change without calls
+// made through the provider
+var apiQueries = map[string]bool{
+	"audits":      true,
+	"consumption": true,
+	"search":      true,
+}
+
+// apiKind classifies the API by the last element of its path: get and list calls and their
+// variants like getConfig, groupGet, listComputes or snapshotList are cached
+func apiKind(api string) int {
+	if strings.Contains(api, "/tasks/") {
+		return apiKindQuery
+	}
+
+	name := api[strings.LastIndex(api, "/")+1:]
+	switch {
+	case strings.HasPrefix(name, "get"), strings.HasPrefix(name, "list"),
+		strings.HasSuffix(name, "Get"), strings.HasSuffix(name, "List"):
+		return apiKindRead
+	case apiQueries[name]:
+		return apiKindQuery
+	}
+	return apiKindWrite
+}
+
+type apiCacheEntry struct {
+	done    chan struct{}
+	resp    string
+	err     error
+	expires time.Time // zero while the call is in progress
+}
+
+// apiCache keeps responses of read calls. Concurrent identical calls wait for the first one
+// instead of being sent to the controller again. Errors are not cached.
+type apiCache struct {
+	sync.Mutex
+	entries   map[string]*apiCacheEntry
+	lastSweep time.Time
+}
+
+type noCacheKey struct{}
+
+// WithoutCache returns the context whose API calls always reach the controller. It is used when
+// polling objects for changes of their state.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func cacheDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noCacheKey{}).(bool)
+	return disabled
+}
+
+// decortAPICallCached makes a read API call or reuses the response of the same call made
+// within ttl
+func (config *ControllerCfg) decortAPICallCached(ctx context.Context, method string, api_name string, url_values *url.Values, ttl time.Duration) (string, error) {
+	if ttl <= 0 || cacheDisabled(ctx) {
+		return config.decortAPICall(ctx, method, api_name, url_values)
+	}
+
+	cache := &config.cache
+	key := method + " " + api_name + "?" + url_values.Encode()
+
+	cache.Lock()
+	entry, ok := cache.entries[key]
+	if ok && (entry.expires.IsZero() || time.Now().Before(entry.expires)) {
+		cache.Unlock()
+		logger.Debugf(ctx, "decortAPICall: %s %s served from cache", method, api_name)
+
+		select {
+		case <-entry.done:
+			return entry.resp, entry.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	if cache.entries == nil {
+		cache.entries = make(map[string]*apiCacheEntry)
+	}
+	entry = &apiCacheEntry{done: make(chan struct{})}
+	cache.entries[key] = entry
+	cache.Unlock()
+
+	entry.resp, entry.err = config.decortAPICall(ctx, method, api_name, url_values)
+
+	cache.Lock()
+	now := time.Now()
+	if entry.err != nil {
+		if cache.entries[key] == entry {
+			delete(cache.entries, key)
+		}
+	} else {
+		// if the cache was invalidated while the call was in progress, the entry is no longer
+		// in it, as the response may predate the change
+		entry.expires = now.Add(ttl)
+	}
+	if now.Sub(cache.lastSweep) > ttl {
+		for key, entry := range cache.entries {
+			if !entry.expires.IsZero() && now.After(entry.expires) {
+				delete(cache.entries, key)
+			}
+		}
+		cache.lastSweep = now
+	}
+	cache.Unlock()
+	close(entry.done)
+
+	return entry.resp, entry.err
+}
+
+// CacheInvalidate drops all cached responses. A change of one object is often visible through
+// others, e.g. adding a k8s worker creates a compute and changes resource group usage, so
+// entries are not tracked per object. Calls in progress are dropped as well.
+func (config *ControllerCfg) CacheInvalidate() {
+	config.cache.Lock()
+	defer config.cache.Unlock()
+	if len(config.cache.entries) > 0 {
+		config.cache.entries = make(map[string]*apiCacheEntry)
+	}
+}
@@ -0,0 +1,311 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestApiKind(t *testing.T) {
+	tests := []struct {
+		api  string
+		want int
+	}{
+		{api: "/restmachine/cloudapi/compute/get", want: apiKindRead},
+		{api: "/restmachine/cloudapi/compute/list", want: apiKindRead},
+		{api: "/restmachine/cloudapi/compute/listDeleted", want: apiKindRead},
+		{api: "/restmachine/cloudapi/compute/getConfig", want: apiKindRead},
+		{api: "/restmachine/cloudapi/bservice/groupGet", want: apiKindRead},
+		{api: "/restmachine/cloudapi/compute/snapshotList", want: apiKindRead},
+		{api: "/restmachine/cloudapi/compute/audits", want: apiKindQuery},
+		{api: "/restmachine/cloudapi/account/getConsumption", want: apiKindRead},
+		{api: "/restmachine/cloudapi/account/consumption", want: apiKindQuery},
+		{api: "/restmachine/cloudapi/tasks/get", want: apiKindQuery},
+		{api: "/restmachine/cloudapi/compute/create", want: apiKindWrite},
+		{api: "/restmachine/cloudapi/compute/delete", want: apiKindWrite},
+		{api: "/restmachine/cloudapi/compute/snapshotCreate", want: apiKindWrite},
+		{api: "/restmachine/cloudapi/compute/netAttach", want: apiKindWrite},
+		{api: "/restmachine/cloudapi/k8s/workerAdd", want: apiKindWrite},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.api, func(t *testing.T) {
+			if got := apiKind(tc.api); got != tc.want {
+				t.Errorf("apiKind() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+// testController counts the calls reaching the controller by path and answers with the number
+// of the call, so that a reused response is told apart from a new one
+type testController struct {
+	sync.Mutex
+	calls   map[string]int
+	status  int           // status of the responses, 200 if not set
+	release chan struct{} // if set, responses are held until it is closed
+	started chan struct{} // if set, receives a value when a call reaches the controller
+}
+
+func (tc *testController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tc.Lock()
+	tc.calls[r.URL.Path]++
+	n, status := tc.calls[r.URL.Path], tc.status
+	tc.Unlock()
+
+	if tc.started != nil {
+		tc.started <- struct{}{}
+	}
+	if tc.release != nil {
+		<-tc.release
+	}
+	if status != 0 {
+		w.WriteHeader(status)
+	}
+	fmt.Fprintf(w, "%d", n)
+}
+
+func (tc *testController) count(path string) int {
+	tc.Lock()
+	defer tc.Unlock()
+	return tc.calls[path]
+}
+
+func newTestControllerCfg(t *testing.T, tc *testController, ttl time.Duration) *ControllerCfg {
+	t.Helper()
+
+	if tc.calls == nil {
+		tc.calls = map[string]int{}
+	}
+	srv := httptest.NewServer(tc)
+	t.Cleanup(srv.Close)
+
+	return &ControllerCfg{
+		controller_url: srv.URL,
+		auth_mode_code: MODE_JWT,
+		cc_client:      srv.Client(),
+		cache_ttl:      ttl,
+	}
+}
+
+const (
+	testGetAPI    = "/restmachine/cloudapi/compute/get"
+	testListAPI   = "/restmachine/cloudapi/compute/list"
+	testWriteAPI  = "/restmachine/cloudapi/compute/stop"
+	testTaskAPI   = "/restmachine/cloudapi/tasks/get"
+	testComputeId = "123"
+)
+
+func testCall(t *testing.T, ctx context.Context, c *ControllerCfg, api string, computeId string) string {
+	t.Helper()
+
+	urlValues := &url.Values{}
+	urlValues.Add("computeId", computeId)
+	resp, err := c.DecortAPICall(ctx, "POST", api, urlValues)
+	if err != nil {
+		t.Fatalf("DecortAPICall(%s) error = %v", api, err)
+	}
+	return resp
+}
+
+func TestDecortAPICallCache(t *testing.T) {
+	type call struct {
+		api       string
+		computeId string
+		noCache   bool
+		want      string // response, that is the number of the call of the API by the controller
+	}
+
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		calls []call
+	}{
+		{
+			name: "reads are reused",
+			ttl:  time.Minute,
+			calls: []call{
+				{api: testGetAPI, computeId: testComputeId, want: "1"},
+				{api: testGetAPI, computeId: testComputeId, want: "1"},
+				{api: testListAPI, computeId: testComputeId, want: "1"},
+				{api: testListAPI, computeId: testComputeId, want: "1"},
+			},
+		},
+		{
+			name: "parameters are part of the key",
+			ttl:  time.Minute,
+			calls: []call{
+				{api: testGetAPI, computeId: "123", want: "1"},
+				{api: testGetAPI, computeId: "456", want: "2"},
+				{api: testGetAPI, computeId: "123", want: "1"},
+			},
+		},
+		{
+			name: "writes drop cached responses",
+			ttl:  time.Minute,
+			calls: []call{
+				{api: testGetAPI, computeId: testComputeId, want: "1"},
+				{api: testWriteAPI, computeId: testComputeId, want: "1"},
+				{api: testGetAPI, computeId: testComputeId, want: "2"},
+				{api: testGetAPI, computeId: testComputeId, want: "2"},
+			},
+		},
+		{
+			name: "queries are not cached",
+			ttl:  time.Minute,
+			calls: []call{
+				{api: testTaskAPI, computeId: testComputeId, want: "1"},
+				{api: testTaskAPI, computeId: testComputeId, want: "2"},
+			},
+		},
+		{
+			name: "polling bypasses the cache",
+			ttl:  time.Minute,
+			calls: []call{
+				{api: testGetAPI, computeId: testComputeId, want: "1"},
+				{api: testGetAPI, computeId: testComputeId, noCache: true, want: "2"},
+				{api: testGetAPI, computeId: testComputeId, noCache: true, want: "3"},
+				{api: testGetAPI, computeId: testComputeId, want: "1"},
+			},
+		},
+		{
+			name: "cache disabled",
+			calls: []call{
+				{api: testGetAPI, computeId: testComputeId, want: "1"},
+				{api: testGetAPI, computeId: testComputeId, want: "2"},
+			},
+		},
+		{
+			name: "expired",
+			ttl:  time.Nanosecond,
+			calls: []call{
+				{api: testGetAPI, computeId: testComputeId, want: "1"},
+				{api: testGetAPI, computeId: testComputeId, want: "2"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestControllerCfg(t, &testController{}, tc.ttl)
+			for i, call := range tc.calls {
+				ctx := context.Background()
+				if call.noCache {
+					ctx = WithoutCache(ctx)
+				}
+				if got := testCall(t, ctx, c, call.api, call.computeId); got != call.want {
+					t.Errorf("call %d of %s = %s, want %s", i, call.api, got, call.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecortAPICallCacheErrors(t *testing.T) {
+	tc := &testController{status: http.StatusBadRequest}
+	c := newTestControllerCfg(t, tc, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.DecortAPICall(context.Background(), "POST", testGetAPI, &url.Values{}); err == nil {
+			t.Fatalf("call %d: DecortAPICall() succeeded with status %d", i, tc.status)
+		}
+	}
+	if got := tc.count(testGetAPI); got != 2 {
+		t.Errorf("%d calls reached the controller, want 2 as errors are not cached", got)
+	}
+}
+
+func TestDecortAPICallCacheConcurrent(t *testing.T) {
+	tc := &testController{release: make(chan struct{}), started: make(chan struct{}, 10)}
+	c := newTestControllerCfg(t, tc, time.Minute)
+
+	var wg sync.WaitGroup
+	resps := make([]string, 5)
+	for i := range resps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := c.DecortAPICall(context.Background(), "POST", testGetAPI, &url.Values{})
+			if err != nil {
+				t.Errorf("DecortAPICall() error = %v", err)
+			}
+			resps[i] = resp
+		}(i)
+	}
+	<-tc.started
+	// let the other calls find the call in progress
+	time.Sleep(50 * time.Millisecond)
+	close(tc.release)
+	wg.Wait()
+
+	if got := tc.count(testGetAPI); got != 1 {
+		t.Errorf("%d identical concurrent calls reached the controller, want 1", got)
+	}
+	for i, resp := range resps {
+		if resp != "1" {
+			t.Errorf("call %d = %s, want the response of the first call", i, resp)
+		}
+	}
+}
+
+func TestDecortAPICallCacheInvalidateInProgress(t *testing.T) {
+	tc := &testController{release: make(chan struct{}), started: make(chan struct{}, 10)}
+	c := newTestControllerCfg(t, tc, time.Minute)
+
+	done := make(chan string)
+	go func() {
+		resp, _ := c.DecortAPICall(context.Background(), "POST", testGetAPI, &url.Values{"computeId": {testComputeId}})
+		done <- resp
+	}()
+	<-tc.started
+	// a change made while the read is in progress: its response may predate the change
+	c.CacheInvalidate()
+	close(tc.release)
+	if got := <-done; got != "1" {
+		t.Fatalf("DecortAPICall() = %s, want 1", got)
+	}
+
+	if got := testCall(t, context.Background(), c, testGetAPI, testComputeId); got != "2" {
+		t.Errorf("DecortAPICall() after invalidation = %s, want a new response", got)
+	}
+	if got := testCall(t, context.Background(), c, testGetAPI, testComputeId); got != "2" {
+		t.Errorf("DecortAPICall() = %s, want the cached response", got)
+	}
+}
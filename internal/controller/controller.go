@@ -27,14 +27,14 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	// "time"
 
-	log "github.com/sirupsen/logrus"
-
 	jwt "github.com/golang-jwt/jwt/v4"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -61,9 +61,23 @@ type ControllerCfg struct {
 	oauth2_url      string       // always required
 	decort_username string       // assigned to either legacy_user (legacy mode) or Oauth2 user (oauth2 mode) upon successful verification
 	cc_client       *http.Client // assigned when all initial checks successfully passed
+
+	default_tags map[string]string // tags added to every resource that supports tags
+	name_prefix  string            // required prefix of resource names
+	name_pattern *regexp.Regexp    // optional regex resource names should match
+	quota_check  string            // off, warn or error: how exceeded quotas are reported at plan time
+	quota_state  interface{}       // usage planned by this provider instance, owned by the quota package
+	quota_once   sync.Once
+
+	default_grid_id int // grid of resources that do not set their own, resolved when the provider is configured
+
+	trace *traceFile // API calls are written to it when trace_file is set
+
+	cache_ttl time.Duration // how long responses of read calls are reused, 0 disables the cache
+	cache     apiCache
 }
 
-func ControllerConfigure(d *schema.ResourceData) (*ControllerCfg, error) {
+func ControllerConfigure(ctx context.Context, d *schema.ResourceData) (*ControllerCfg, error) {
 	// This function first will check that all required provider parameters for the
 	// selected authenticator mode are set correctly and initialize ControllerCfg structure
 	// based on the provided parameters.
@@ -86,10 +100,23 @@ func ControllerConfigure(d *schema.ResourceData) (*ControllerCfg, error) {
 		app_secret:      d.Get("app_secret").(string),
 		oauth2_url:      d.Get("oauth2_url").(string),
 		decort_username: "",
+		cache_ttl:       time.Duration(d.Get("cache_ttl").(int)) * time.Second,
 	}
 
 	allow_unverified_ssl := d.Get("allow_unverified_ssl").(bool)
 
+	if err := ret_config.configurePolicy(d); err != nil {
+		return nil, err
+	}
+
+	if trace_file := d.Get("trace_file").(string); trace_file != "" {
+		trace, err := openTraceFile(trace_file)
+		if err != nil {
+			return nil, err
+		}
+		ret_config.trace = trace
+	}
+
 	if ret_config.controller_url == "" {
 		return nil, fmt.Errorf("Empty DECORT cloud controller URL provided.")
 	}
@@ -130,7 +157,7 @@ func ControllerConfigure(d *schema.ResourceData) (*ControllerCfg, error) {
 	}
 
 	if allow_unverified_ssl {
-		log.Warn("ControllerConfigure: allow_unverified_ssl is set - will not check certificates!")
+		logger.Warn(ctx, "ControllerConfigure: allow_unverified_ssl is set - will not check certificates!")
 		transCfg := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}} //nolint:gosec
 		ret_config.cc_client = &http.Client{
 			Transport: transCfg,
@@ -190,6 +217,32 @@ func (config *ControllerCfg) GetDecortUsername() string {
 	return config.decort_username
 }
 
+// GetDefaultGridID returns the grid resources are created in when they do not set their own
+func (config *ControllerCfg) GetDefaultGridID() int {
+	return config.default_grid_id
+}
+
+// SetDefaultGridID sets the grid resources are created in when they do not set their own
+func (config *ControllerCfg) SetDefaultGridID(gridId int) {
+	config.default_grid_id = gridId
+}
+
+// Secrets returns credentials of the provider and the tokens obtained with them, they are
+// masked in logs
+func (config *ControllerCfg) Secrets() []string {
+	return []string{config.legacy_password, config.legacy_sid, config.jwt, config.app_secret}
+}
+
+func (config *ControllerCfg) GetControllerURL() string {
+	return config.controller_url
+}
+
+// HTTPClient returns a client for requests to hosts other than the controller, e.g. image URLs.
+// It shares the transport of the controller client, so allow_unverified_ssl applies to it too.
+func (config *ControllerCfg) HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: config.cc_client.Transport, Timeout: timeout}
+}
+
 func (config *ControllerCfg) getOAuth2JWT() (string, error) {
 	// 	Obtain JWT from the Oauth2 provider using application ID and application secret provided in config.
 	if config.auth_mode_code == MODE_UNDEF {
@@ -223,7 +276,7 @@ func (config *ControllerCfg) getOAuth2JWT() (string, error) {
 		// fmt.Println("response Headers:", resp.Header)
 		// fmt.Println("response Headers:", req.URL)
 		return "", fmt.Errorf("getOauth2JWT: unexpected status code %d when obtaining JWT from %q for APP_ID %q, request Body %q",
-			resp.StatusCode, req.URL, config.app_id, params_str)
+			resp.StatusCode, req.URL, config.app_id, RedactValues(params).Encode())
 	}
 	defer resp.Body.Close()
 
@@ -317,7 +370,7 @@ func (config *ControllerCfg) validateLegacyUser() (bool, error) {
 
 	responseData, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatal(err)
+		return false, err
 	}
 
 	// validation successful - keep session ID for future use
@@ -327,6 +380,21 @@ func (config *ControllerCfg) validateLegacyUser() (bool, error) {
 }
 
 func (config *ControllerCfg) DecortAPICall(ctx context.Context, method string, api_name string, url_values *url.Values) (json_resp string, err error) { //nolint:unparam
+	// Responses of read calls are reused for cache_ttl, any mutating call drops them.
+
+	switch apiKind(api_name) {
+	case apiKindRead:
+		return config.decortAPICallCached(ctx, method, api_name, url_values, config.cache_ttl)
+	case apiKindWrite:
+		config.CacheInvalidate()
+		// reads made while the call is in progress may return the previous state
+		defer config.CacheInvalidate()
+	}
+
+	return config.decortAPICall(ctx, method, api_name, url_values)
+}
+
+func (config *ControllerCfg) decortAPICall(ctx context.Context, method string, api_name string, url_values *url.Values) (json_resp string, err error) {
 	// This is a convenience wrapper around standard HTTP request methods that is aware of the
 	// authorization mode for which the provider was initialized and compiles request accordingly.
 
@@ -357,6 +425,36 @@ func (config *ControllerCfg) DecortAPICall(ctx context.Context, method string, a
 		url_values.Add("authkey", config.legacy_sid)
 	}
 	params_str := url_values.Encode()
+	// parameters as they are logged and reported in errors
+	redacted_str := RedactValues(*url_values).Encode()
+
+	request_id := newRequestID()
+	ctx = logger.SetField(ctx, "request_id", request_id)
+
+	var status, attempts, resp_size int
+	if config.trace != nil {
+		start := time.Now()
+		defer func() {
+			record := &traceRecord{
+				Time:          start,
+				RequestID:     request_id,
+				Method:        method,
+				API:           api_name,
+				Params:        RedactValues(*url_values),
+				Status:        status,
+				Attempts:      attempts,
+				DurationMs:    time.Since(start).Milliseconds(),
+				ResponseBytes: resp_size,
+			}
+			if info, ok := ctx.Value(traceKey{}).(traceInfo); ok {
+				record.Resource, record.Operation, record.ID = info.resource, info.operation, info.id
+			}
+			if err != nil {
+				record.Error = err.Error()
+			}
+			config.trace.write(ctx, record)
+		}()
+	}
 
 	req, err := http.NewRequest(method, config.controller_url+api_name, strings.NewReader(params_str))
 	if err != nil {
@@ -368,6 +466,7 @@ func (config *ControllerCfg) DecortAPICall(ctx context.Context, method string, a
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Content-Length", strconv.Itoa(len(params_str)))
 	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Request-ID", request_id)
 
 	if config.auth_mode_code == MODE_OAUTH2 || config.auth_mode_code == MODE_JWT {
 		req.Header.Set("Authorization", fmt.Sprintf("bearer %s", config.jwt))
@@ -376,6 +475,7 @@ func (config *ControllerCfg) DecortAPICall(ctx context.Context, method string, a
 	var resp *http.Response
 	var body []byte
 	for i := 0; i < 5; i++ {
+		attempts++
 		resp, err = config.cc_client.Do(req)
 		if err != nil {
 			return "", err
@@ -386,21 +486,22 @@ func (config *ControllerCfg) DecortAPICall(ctx context.Context, method string, a
 			return "", err
 		}
 		resp.Body.Close()
-		log.Debugf("decortAPICall: %s %s\n %s", method, api_name, body)
+		status, resp_size = resp.StatusCode, len(body)
+		logger.Debugf(ctx, "decortAPICall: %s %s %s\n %s", method, api_name, redacted_str, redactResponse(api_name, body))
 
 		if resp.StatusCode == http.StatusOK {
 			return string(body), nil
 		} else {
 			if resp.StatusCode == http.StatusInternalServerError {
-				log.Warnf("got 500, retrying %d/5", i+1)
+				logger.Warnf(ctx, "got 500, retrying %d/5", i+1)
 				time.Sleep(time.Second * 5)
 				continue
 			}
-			return "", fmt.Errorf("decortAPICall: unexpected status code %d when calling API %q with request Body %q. Respone:\n%s",
-				resp.StatusCode, req.URL, params_str, body)
+			return "", fmt.Errorf("decortAPICall: unexpected status code %d when calling API %q with request Body %q, request ID %s. Respone:\n%s",
+				resp.StatusCode, req.URL, redacted_str, request_id, redactResponse(api_name, body))
 		}
 	}
 
-	return "", fmt.Errorf("decortAPICall: unexpected status code %d when calling API %q with request Body %q. Respone:\n%s",
-		resp.StatusCode, req.URL, params_str, body)
+	return "", fmt.Errorf("decortAPICall: unexpected status code %d when calling API %q with request Body %q, request ID %s. Respone:\n%s",
+		resp.StatusCode, req.URL, redacted_str, request_id, redactResponse(api_name, body))
 }
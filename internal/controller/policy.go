@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// configurePolicy reads organisation-wide settings of the provider: tags added to every
+// resource that supports tags and the naming policy checked at plan time.
+func (config *ControllerCfg) configurePolicy(d *schema.ResourceData) error {
+	config.default_tags = map[string]string{}
+	for key, value := range d.Get("default_tags").(map[string]interface{}) {
+		config.default_tags[key] = value.(string)
+	}
+
+	config.name_prefix = d.Get("name_prefix").(string)
+
+	if pattern := d.Get("name_pattern").(string); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("Invalid name_pattern %q: %v", pattern, err)
+		}
+		config.name_pattern = re
+	}
+
+	config.quota_check = d.Get("quota_check").(string)
+
+	return nil
+}
+
+// MergeTags returns provider default tags overridden by the resource tags.
+func (config *ControllerCfg) MergeTags(tags map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(config.default_tags)+len(tags))
+	for key, value := range config.default_tags {
+		res[key] = value
+	}
+	for key, value := range tags {
+		res[key] = value
+	}
+	return res
+}
+
+// ResourceTags is the reverse of MergeTags: it drops the tags that come from provider
+// default tags, unless they are set on the resource explicitly.
+func (config *ControllerCfg) ResourceTags(allTags map[string]interface{}, tags map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(allTags))
+	for key, value := range allTags {
+		if _, ok := tags[key]; !ok {
+			if defaultValue, ok := config.default_tags[key]; ok && defaultValue == value {
+				continue
+			}
+		}
+		res[key] = value
+	}
+	return res
+}
+
+// QuotaCheck returns how planned resource usage exceeding account and resource group
+// limits is reported: "off", "warn" or "error".
+func (config *ControllerCfg) QuotaCheck() string {
+	return config.quota_check
+}
+
+// QuotaState returns the state of quota checks of this provider instance, created by
+// newState on the first call. The state is opaque to the controller.
+func (config *ControllerCfg) QuotaState(newState func() interface{}) interface{} {
+	config.quota_once.Do(func() {
+		config.quota_state = newState()
+	})
+	return config.quota_state
+}
+
+// CheckName verifies that the name of a resource follows the naming policy of the provider.
+func (config *ControllerCfg) CheckName(name string) error {
+	if config.name_prefix != "" && !strings.HasPrefix(name, config.name_prefix) {
+		return fmt.Errorf("name %q does not start with the prefix %q required by the provider configuration", name, config.name_prefix)
+	}
+	if config.name_pattern != nil && !config.name_pattern.MatchString(name) {
+		return fmt.Errorf("name %q does not match the pattern %q required by the provider configuration", name, config.name_pattern.String())
+	}
+	return nil
+}
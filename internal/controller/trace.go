@@ -0,0 +1,179 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sensitiveParams are parts of names of API parameters and response fields whose values are
+// never logged or written to the trace file
+var sensitiveParams = []string{"password", "passwd", "secret", "token", "authkey", "jwt", "kubeconfig", "userdata", "cloudinit"}
+
+// sensitiveResponses are APIs whose responses are secrets as a whole
+var sensitiveResponses = map[string]bool{
+	"/restmachine/cloudapi/k8s/getConfig":      true,
+	"/restmachine/cloudbroker/k8s/getConfig":   true,
+	"/restmachine/cloudapi/users/authenticate": true,
+}
+
+// sensitiveResponse matches responses that may have sensitive fields
+var sensitiveResponse = regexp.MustCompile(`(?i)` + strings.Join(sensitiveParams, "|"))
+
+const redacted = "REDACTED"
+
+func sensitiveParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range sensitiveParams {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactValues returns a copy of API parameters with values of sensitive parameters masked
+func RedactValues(values url.Values) url.Values {
+	res := make(url.Values, len(values))
+	for name, value := range values {
+		if sensitiveParam(name) {
+			value = []string{redacted}
+		}
+		res[name] = value
+	}
+	return res
+}
+
+func redactJSON(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for name, field := range value {
+			if sensitiveParam(name) {
+				value[name] = redacted
+			} else {
+				value[name] = redactJSON(field)
+			}
+		}
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = redactJSON(elem)
+		}
+	}
+	return value
+}
+
+// redactResponse masks sensitive fields of the API response, e.g. passwords of OS users
+// returned by compute/get
+func redactResponse(api_name string, body []byte) string {
+	if sensitiveResponses[api_name] {
+		return fmt.Sprintf("<%d bytes redacted>", len(body))
+	}
+
+	if !sensitiveResponse.Match(body) {
+		return string(body)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return string(body)
+	}
+	res, err := json.Marshal(redactJSON(value))
+	if err != nil {
+		return string(body)
+	}
+	return string(res)
+}
+
+type traceKey struct{}
+
+type traceInfo struct {
+	resource  string
+	operation string
+	id        string
+}
+
+// WithTrace returns the context whose API calls are attributed to the operation on the resource
+// in the trace file
+func WithTrace(ctx context.Context, resource string, operation string, id string) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceInfo{resource: resource, operation: operation, id: id})
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// traceRecord is a line of the trace file
+type traceRecord struct {
+	Time          time.Time  `json:"time"`
+	RequestID     string     `json:"request_id"`
+	Resource      string     `json:"resource,omitempty"`
+	Operation     string     `json:"operation,omitempty"`
+	ID            string     `json:"id,omitempty"`
+	Method        string     `json:"method"`
+	API           string     `json:"api"`
+	Params        url.Values `json:"params,omitempty"`
+	Status        int        `json:"status,omitempty"`
+	Attempts      int        `json:"attempts"`
+	DurationMs    int64      `json:"duration_ms"`
+	ResponseBytes int        `json:"response_bytes"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// traceFile writes API calls to the trace_file as JSON Lines
+type traceFile struct {
+	sync.Mutex
+	file *os.File
+}
+
+func openTraceFile(path string) (*traceFile, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open trace_file: %w", err)
+	}
+	return &traceFile{file: file}, nil
+}
+
+func (trace *traceFile) write(ctx context.Context, record *traceRecord) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		logger.Warnf(ctx, "traceFile: cannot encode API call %s: %v", record.API, err)
+		return
+	}
+
+	trace.Lock()
+	defer trace.Unlock()
+	if _, err := trace.file.Write(append(line, '\n')); err != nil {
+		logger.Warnf(ctx, "traceFile: cannot write API call %s: %v", record.API, err)
+	}
+}
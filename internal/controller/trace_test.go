@@ -0,0 +1,199 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		values url.Values
+		want   url.Values
+	}{
+		{
+			name:   "nothing sensitive",
+			values: url.Values{"computeId": {"123"}, "name": {"vm"}},
+			want:   url.Values{"computeId": {"123"}, "name": {"vm"}},
+		},
+		{
+			name:   "passwords and keys",
+			values: url.Values{"username": {"admin"}, "password": {"secret"}, "passwd": {"x"}, "authkey": {"sid"}, "app_secret": {"s"}},
+			want:   url.Values{"username": {"admin"}, "password": {redacted}, "passwd": {redacted}, "authkey": {redacted}, "app_secret": {redacted}},
+		},
+		{
+			name:   "names are matched case-insensitively",
+			values: url.Values{"userData": {"#cloud-config"}, "accessToken": {"t"}, "JWT": {"j"}},
+			want:   url.Values{"userData": {redacted}, "accessToken": {redacted}, "JWT": {redacted}},
+		},
+		{
+			name:   "all values of a parameter",
+			values: url.Values{"password": {"a", "b"}},
+			want:   url.Values{"password": {redacted}},
+		},
+		{
+			name:   "empty",
+			values: url.Values{},
+			want:   url.Values{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			original := url.Values{}
+			for name, value := range tc.values {
+				original[name] = append([]string(nil), value...)
+			}
+
+			if got := RedactValues(tc.values); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("RedactValues() = %v, want %v", got, tc.want)
+			}
+			if !reflect.DeepEqual(tc.values, original) {
+				t.Errorf("RedactValues() changed its argument to %v", tc.values)
+			}
+		})
+	}
+}
+
+func TestRedactResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		api  string
+		body string
+		want string
+	}{
+		{
+			name: "nothing sensitive",
+			api:  "/restmachine/cloudapi/compute/get",
+			body: `{"id":123,"name":"vm"}`,
+			want: `{"id":123,"name":"vm"}`,
+		},
+		{
+			name: "nested fields",
+			api:  "/restmachine/cloudapi/compute/get",
+			body: `{"id":123,"osUsers":[{"login":"user","password":"secret"}],"userdata":{"a":1}}`,
+			want: `{"id":123,"osUsers":[{"login":"user","password":"REDACTED"}],"userdata":"REDACTED"}`,
+		},
+		{
+			name: "sensitive word in a value only",
+			api:  "/restmachine/cloudapi/compute/get",
+			body: `{"desc":"password rotation"}`,
+			want: `{"desc":"password rotation"}`,
+		},
+		{
+			name: "not json",
+			api:  "/restmachine/cloudapi/compute/get",
+			body: `token expired`,
+			want: `token expired`,
+		},
+		{
+			name: "whole response",
+			api:  "/restmachine/cloudapi/k8s/getConfig",
+			body: "apiVersion: v1\nkind: Config\n",
+			want: "<28 bytes redacted>",
+		},
+		{
+			name: "jwt",
+			api:  "/restmachine/cloudapi/users/authenticate",
+			body: "eyJhbGciOi",
+			want: "<10 bytes redacted>",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := redactResponse(tc.api, []byte(tc.body)); got != tc.want {
+				t.Errorf("redactResponse() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecortAPICallTrace(t *testing.T) {
+	tc := &testController{}
+	c := newTestControllerCfg(t, tc, time.Minute)
+
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	trace, err := openTraceFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trace.file.Close()
+	c.trace = trace
+
+	ctx := WithTrace(context.Background(), "decort_kvmvm", "create", "")
+	urlValues := &url.Values{"name": {"vm"}, "userdata": {"#cloud-config\npassword: secret"}}
+	if _, err := c.DecortAPICall(ctx, "POST", "/restmachine/cloudapi/kvmx86/create", urlValues); err != nil {
+		t.Fatalf("DecortAPICall() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("trace file has the secret: %s", data)
+	}
+
+	var record traceRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("trace file is not a JSON line: %v\n%s", err, data)
+	}
+	want := traceRecord{
+		RequestID:     record.RequestID,
+		Resource:      "decort_kvmvm",
+		Operation:     "create",
+		Method:        "POST",
+		API:           "/restmachine/cloudapi/kvmx86/create",
+		Params:        url.Values{"name": {"vm"}, "userdata": {redacted}},
+		Status:        200,
+		Attempts:      1,
+		ResponseBytes: 1,
+	}
+	record.Time, record.DurationMs = time.Time{}, 0
+	if !reflect.DeepEqual(record, want) {
+		t.Errorf("trace record = %+v, want %+v", record, want)
+	}
+	if len(record.RequestID) != 32 {
+		t.Errorf("request ID %q is not 16 hex bytes", record.RequestID)
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package cost
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CU types of the resources which the provider and decort-cost count on their own. The
+// controller reports consumed units of other types too, e.g. CU_NP, they can be priced
+// the same way.
+const (
+	CUCPU   = "CU_C" // vCPUs
+	CURAM   = "CU_M" // RAM, MB
+	CUDisk  = "CU_D" // disk space, GB
+	CUExtIP = "CU_I" // external IP addresses
+)
+
+// Prices is a price table of DECORT consumed units. Units are prices per unit of the CU
+// type for the period of the user's choice, e.g. a month: CU_C per vCPU, CU_M per MB of
+// RAM, CU_D per GB of disk, CU_I per external IP address. Pools override the CU_D price
+// for storage pools of SEPs.
+type Prices struct {
+	Currency string             `json:"currency"`
+	Units    map[string]float64 `json:"units"`
+	Pools    []PoolPrice        `json:"pools"`
+}
+
+type PoolPrice struct {
+	SepID int     `json:"sep_id"`
+	Pool  string  `json:"pool"`
+	Price float64 `json:"price"`
+}
+
+// Usage is an amount of consumed units by CU type. Disk space which pool is known is
+// counted in Disks, the rest of it in Units by CU_D.
+type Usage struct {
+	Units map[string]float64
+	Disks []DiskUsage
+}
+
+// DiskUsage is disk space in GB in the pool of the SEP
+type DiskUsage struct {
+	SepID int
+	Pool  string
+	Size  float64
+}
+
+// Add adds the quantity of consumed units of the CU type
+func (u *Usage) Add(cuType string, quantity float64) {
+	if u.Units == nil {
+		u.Units = map[string]float64{}
+	}
+	u.Units[cuType] += quantity
+}
+
+// Item is the cost of consumed units of a single CU type, CU_D items carry the SEP pool
+// when it is known
+type Item struct {
+	CUType    string
+	SepID     int
+	Pool      string
+	Quantity  float64
+	UnitPrice float64
+	Cost      float64
+}
+
+func (p Prices) diskPrice(sepId int, pool string) float64 {
+	for _, pp := range p.Pools {
+		if pp.SepID == sepId && (pp.Pool == "" || pp.Pool == pool) {
+			return pp.Price
+		}
+	}
+	return p.Units[CUDisk]
+}
+
+// Estimate prices the usage, items are returned in a stable order: by CU type, then
+// disks by SEP pool. CU types missing in the price table are priced at zero.
+func (p Prices) Estimate(u Usage) ([]Item, float64) {
+	cuTypes := make([]string, 0, len(u.Units))
+	for cuType := range u.Units {
+		cuTypes = append(cuTypes, cuType)
+	}
+	sort.Strings(cuTypes)
+
+	items := []Item{}
+	for _, cuType := range cuTypes {
+		items = append(items, Item{CUType: cuType, Quantity: u.Units[cuType], UnitPrice: p.Units[cuType]})
+	}
+
+	// disks of the same pool are summed up
+	disks := map[string]*Item{}
+	for _, disk := range u.Disks {
+		key := fmt.Sprintf("%d/%s", disk.SepID, disk.Pool)
+		if item, ok := disks[key]; ok {
+			item.Quantity += disk.Size
+			continue
+		}
+		disks[key] = &Item{CUType: CUDisk, SepID: disk.SepID, Pool: disk.Pool, Quantity: disk.Size, UnitPrice: p.diskPrice(disk.SepID, disk.Pool)}
+	}
+	keys := make([]string, 0, len(disks))
+	for key := range disks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		items = append(items, *disks[key])
+	}
+
+	total := 0.0
+	for i := range items {
+		items[i].Cost = round(items[i].Quantity * items[i].UnitPrice)
+		total += items[i].Cost
+	}
+	return items, round(total)
+}
+
+// round rounds money to hundredths to hide floating point noise
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,127 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package cost
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	prices := Prices{
+		Currency: "RUB",
+		Units:    map[string]float64{CUCPU: 500, CURAM: 0.2, CUDisk: 10, CUExtIP: 150},
+		Pools: []PoolPrice{
+			{SepID: 1, Pool: "ssd", Price: 25},
+			{SepID: 2, Price: 5},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		usage     Usage
+		wantItems []Item
+		wantTotal float64
+	}{
+		{
+			name:      "nothing",
+			wantItems: []Item{},
+			wantTotal: 0,
+		},
+		{
+			name:  "units by CU type",
+			usage: Usage{Units: map[string]float64{CUExtIP: 1, CURAM: 4096, CUCPU: 2}},
+			wantItems: []Item{
+				{CUType: CUCPU, Quantity: 2, UnitPrice: 500, Cost: 1000},
+				{CUType: CUExtIP, Quantity: 1, UnitPrice: 150, Cost: 150},
+				{CUType: CURAM, Quantity: 4096, UnitPrice: 0.2, Cost: 819.2},
+			},
+			wantTotal: 1969.2,
+		},
+		{
+			name:  "CU type missing in the price table",
+			usage: Usage{Units: map[string]float64{"CU_NP": 3}},
+			wantItems: []Item{
+				{CUType: "CU_NP", Quantity: 3},
+			},
+			wantTotal: 0,
+		},
+		{
+			name:  "pool prices",
+			usage: Usage{Disks: []DiskUsage{{SepID: 1, Pool: "ssd", Size: 20}, {SepID: 1, Pool: "hdd", Size: 100}, {SepID: 2, Pool: "any", Size: 50}}},
+			wantItems: []Item{
+				{CUType: CUDisk, SepID: 1, Pool: "hdd", Quantity: 100, UnitPrice: 10, Cost: 1000},
+				{CUType: CUDisk, SepID: 1, Pool: "ssd", Quantity: 20, UnitPrice: 25, Cost: 500},
+				{CUType: CUDisk, SepID: 2, Pool: "any", Quantity: 50, UnitPrice: 5, Cost: 250},
+			},
+			wantTotal: 1750,
+		},
+		{
+			name:  "disks of the same pool are summed up",
+			usage: Usage{Units: map[string]float64{CUDisk: 5.5}, Disks: []DiskUsage{{SepID: 1, Pool: "ssd", Size: 10}, {SepID: 1, Pool: "ssd", Size: 15}}},
+			wantItems: []Item{
+				{CUType: CUDisk, Quantity: 5.5, UnitPrice: 10, Cost: 55},
+				{CUType: CUDisk, SepID: 1, Pool: "ssd", Quantity: 25, UnitPrice: 25, Cost: 625},
+			},
+			wantTotal: 680,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			items, total := prices.Estimate(tc.usage)
+			if !reflect.DeepEqual(items, tc.wantItems) {
+				t.Errorf("Estimate() items = %+v, want %+v", items, tc.wantItems)
+			}
+			if total != tc.wantTotal {
+				t.Errorf("Estimate() total = %v, want %v", total, tc.wantTotal)
+			}
+		})
+	}
+}
+
+func TestEstimateRounding(t *testing.T) {
+	items, total := Prices{Units: map[string]float64{CURAM: 0.0001}}.Estimate(Usage{Units: map[string]float64{CURAM: 3000}})
+	if items[0].Cost != 0.3 || total != 0.3 {
+		t.Errorf("Estimate() ram cost = %v, total = %v, want 0.3", items[0].Cost, total)
+	}
+}
+
+func TestUsageAdd(t *testing.T) {
+	u := Usage{}
+	u.Add(CUCPU, 2)
+	u.Add(CUCPU, 1)
+	if u.Units[CUCPU] != 3 {
+		t.Errorf("Add() CU_C = %v, want 3", u.Units[CUCPU])
+	}
+}
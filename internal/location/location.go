@@ -24,37 +24,110 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
-)
 
-var DefaultGridID int
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
 
-func UtilityLocationGetDefaultGridID(ctx context.Context, m interface{}) (int, error) {
+// utilityLocationsList gets locations of the platform
+func utilityLocationsList(ctx context.Context, m interface{}) (LocationsListResp, error) {
 	c := m.(*controller.ControllerCfg)
 
 	urlValues := &url.Values{}
 
-	log.Debug("utilityLocationGetDefaultGridID: retrieving locations list")
+	logger.Debug(ctx, "utilityLocationsList: retrieving locations list")
 	apiResp, err := c.DecortAPICall(ctx, "POST", LocationsListAPI, urlValues)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	locList := LocationsListResp{}
 	err = json.Unmarshal([]byte(apiResp), &locList)
+	if err != nil {
+		return nil, err
+	}
+
+	return locList, nil
+}
+
+func locationFind(locList LocationsListResp, code string, gridId int) (*LocationRecord, error) {
+	codes := make([]string, 0, len(locList))
+	for i, loc := range locList {
+		if (code != "" && loc.LocationCode == code) || (gridId != 0 && loc.GridID == gridId) {
+			return &locList[i], nil
+		}
+		codes = append(codes, fmt.Sprintf("%s (grid ID %d)", loc.LocationCode, loc.GridID))
+	}
+
+	if code != "" {
+		return nil, fmt.Errorf("location %q not found, available locations: %s", code, strings.Join(codes, ", "))
+	}
+	return nil, fmt.Errorf("grid ID %d not found, available locations: %s", gridId, strings.Join(codes, ", "))
+}
+
+// UtilityLocationGetDefaultGridID returns the grid resources are created in when they do not
+// set their own: the grid with the given ID, the grid of the location with the given code or,
+// when neither is set, the grid of the first location
+func UtilityLocationGetDefaultGridID(ctx context.Context, m interface{}, code string, gridId int) (int, error) {
+	locList, err := utilityLocationsList(ctx, m)
 	if err != nil {
 		return 0, err
 	}
 
 	if len(locList) == 0 {
-		DefaultGridID = 0
 		return 0, fmt.Errorf("utilityLocationGetDefaultGridID: retrieved 0 length locations list")
 	}
 
-	DefaultGridID = locList[0].GridID
-	log.Debugf("utilityLocationGetDefaultGridID: default location GridID %d, name %s", DefaultGridID, locList[0].Name)
+	loc := &locList[0]
+	if code != "" || gridId != 0 {
+		loc, err = locationFind(locList, code, gridId)
+		if err != nil {
+			return 0, err
+		}
+	} else if len(locList) > 1 {
+		logger.Warnf(ctx, "utilityLocationGetDefaultGridID: the platform has %d locations, using the first one %s, set location or grid_id of the provider to choose another",
+			len(locList), loc.LocationCode)
+	}
+	logger.Debugf(ctx, "utilityLocationGetDefaultGridID: default location GridID %d, name %s", loc.GridID, loc.Name)
+
+	return loc.GridID, nil
+}
+
+// UtilityLocationGridID returns the grid of the location with the given code
+func UtilityLocationGridID(ctx context.Context, m interface{}, code string) (int, error) {
+	locList, err := utilityLocationsList(ctx, m)
+	if err != nil {
+		return 0, err
+	}
 
-	return DefaultGridID, nil
+	loc, err := locationFind(locList, code, 0)
+	if err != nil {
+		return 0, err
+	}
+	return loc.GridID, nil
+}
+
+// UtilityLocationResourceGridID returns the grid a resource is created in: its gid, the grid
+// of its location or the default grid of the provider
+func UtilityLocationResourceGridID(ctx context.Context, d *schema.ResourceData, m interface{}) (int, error) {
+	if gridId, ok := d.GetOk("gid"); ok {
+		return gridId.(int), nil
+	}
+	if code, ok := d.GetOk("location"); ok {
+		return UtilityLocationGridID(ctx, m, code.(string))
+	}
+	return m.(*controller.ControllerCfg).GetDefaultGridID(), nil
+}
+
+// ResourceLocationSchemaMake returns the schema of the location argument of resources
+func ResourceLocationSchemaMake() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		ForceNew:      true,
+		ConflictsWith: []string{"gid"},
+		Description:   "Code of the location to create the resource in. If neither location nor gid is set, the default grid of the provider is used.",
+	}
 }
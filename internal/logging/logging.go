@@ -0,0 +1,224 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logging provides subsystem loggers of the provider packages. Within Terraform
+// operations messages go to terraform-plugin-log, so they follow TF_LOG and TF_LOG_PROVIDER,
+// and the level of a subsystem can be set with TF_LOG_PROVIDER_DECORT_<SUBSYSTEM>, e.g.
+// TF_LOG_PROVIDER_DECORT_K8S=TRACE. Elsewhere, e.g. in the command line tools, messages go
+// to logrus.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/sirupsen/logrus"
+)
+
+// sensitiveFields are keys of fields whose values are masked
+var sensitiveFields = []string{"jwt", "password", "app_secret", "client_secret", "authkey", "kubeconfig"}
+
+// sensitiveMessages mask private keys and tokens of kubeconfigs in messages
+var sensitiveMessages = []*regexp.Regexp{
+	regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`(client-key-data|token):\s*\S+`),
+}
+
+var subsystems = struct {
+	sync.Mutex
+	names map[string]bool
+}{names: make(map[string]bool)}
+
+type initKey struct{}
+
+type fieldsKey struct{}
+
+// Logger writes messages of a subsystem
+type Logger struct {
+	subsystem string
+}
+
+// New returns the logger of the subsystem, it is called once per package
+func New(subsystem string) Logger {
+	subsystems.Lock()
+	defer subsystems.Unlock()
+	subsystems.names[subsystem] = true
+	return Logger{subsystem: subsystem}
+}
+
+// Init creates subsystem loggers in the context of a Terraform operation. Values of secrets
+// are masked in messages of all subsystems.
+func Init(ctx context.Context, secrets ...string) context.Context {
+	subsystems.Lock()
+	names := make([]string, 0, len(subsystems.names))
+	for name := range subsystems.names {
+		names = append(names, name)
+	}
+	subsystems.Unlock()
+	sort.Strings(names)
+
+	masked := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		if secret != "" {
+			masked = append(masked, secret)
+		}
+	}
+
+	for _, name := range names {
+		ctx = tflog.NewSubsystem(ctx, name,
+			tflog.WithLevelFromEnv("TF_LOG_PROVIDER_DECORT", name),
+			tflog.WithRootFields(),
+			// skip Logger methods to report locations of their callers
+			tflog.WithAdditionalLocationOffset(2))
+		ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, name, sensitiveFields...)
+		ctx = tflog.SubsystemMaskMessageRegexes(ctx, name, sensitiveMessages...)
+		if len(masked) > 0 {
+			ctx = tflog.SubsystemMaskMessageStrings(ctx, name, masked...)
+		}
+	}
+
+	return context.WithValue(ctx, initKey{}, true)
+}
+
+func initialized(ctx context.Context) bool {
+	ok, _ := ctx.Value(initKey{}).(bool)
+	return ok
+}
+
+// SetField returns the context whose messages of all subsystems have the field
+func SetField(ctx context.Context, key string, value interface{}) context.Context {
+	if !initialized(ctx) {
+		return setLogrusField(ctx, key, value)
+	}
+
+	subsystems.Lock()
+	defer subsystems.Unlock()
+	for name := range subsystems.names {
+		ctx = tflog.SubsystemSetField(ctx, name, key, value)
+	}
+	return ctx
+}
+
+// SetField returns the context whose messages of the subsystem have the field
+func (logger Logger) SetField(ctx context.Context, key string, value interface{}) context.Context {
+	if !initialized(ctx) {
+		return setLogrusField(ctx, key, value)
+	}
+	return tflog.SubsystemSetField(ctx, logger.subsystem, key, value)
+}
+
+func setLogrusField(ctx context.Context, key string, value interface{}) context.Context {
+	fields := logrus.Fields{}
+	if parent, ok := ctx.Value(fieldsKey{}).(logrus.Fields); ok {
+		for k, v := range parent {
+			fields[k] = v
+		}
+	}
+	fields[key] = value
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+const (
+	levelTrace = iota
+	levelDebug
+	levelInfo
+	levelWarn
+	levelError
+)
+
+// log is called directly by the exported methods, so the location of their caller is
+// reported by terraform-plugin-log
+func (logger Logger) log(ctx context.Context, level int, msg string) {
+	if !initialized(ctx) {
+		entry := logrus.WithField("subsystem", logger.subsystem)
+		if fields, ok := ctx.Value(fieldsKey{}).(logrus.Fields); ok {
+			entry = entry.WithFields(fields)
+		}
+		switch level {
+		case levelTrace:
+			entry.Trace(msg)
+		case levelDebug:
+			entry.Debug(msg)
+		case levelInfo:
+			entry.Info(msg)
+		case levelWarn:
+			entry.Warn(msg)
+		default:
+			entry.Error(msg)
+		}
+		return
+	}
+
+	switch level {
+	case levelTrace:
+		tflog.SubsystemTrace(ctx, logger.subsystem, msg)
+	case levelDebug:
+		tflog.SubsystemDebug(ctx, logger.subsystem, msg)
+	case levelInfo:
+		tflog.SubsystemInfo(ctx, logger.subsystem, msg)
+	case levelWarn:
+		tflog.SubsystemWarn(ctx, logger.subsystem, msg)
+	default:
+		tflog.SubsystemError(ctx, logger.subsystem, msg)
+	}
+}
+
+func (logger Logger) Trace(ctx context.Context, msg string) {
+	logger.log(ctx, levelTrace, msg)
+}
+
+func (logger Logger) Debug(ctx context.Context, msg string) {
+	logger.log(ctx, levelDebug, msg)
+}
+
+func (logger Logger) Info(ctx context.Context, msg string) {
+	logger.log(ctx, levelInfo, msg)
+}
+
+func (logger Logger) Warn(ctx context.Context, msg string) {
+	logger.log(ctx, levelWarn, msg)
+}
+
+func (logger Logger) Error(ctx context.Context, msg string) {
+	logger.log(ctx, levelError, msg)
+}
+
+func (logger Logger) Tracef(ctx context.Context, format string, args ...interface{}) {
+	logger.log(ctx, levelTrace, fmt.Sprintf(format, args...))
+}
+
+func (logger Logger) Debugf(ctx context.Context, format string, args ...interface{}) {
+	logger.log(ctx, levelDebug, fmt.Sprintf(format, args...))
+}
+
+func (logger Logger) Infof(ctx context.Context, format string, args ...interface{}) {
+	logger.log(ctx, levelInfo, fmt.Sprintf(format, args...))
+}
+
+func (logger Logger) Warnf(ctx context.Context, format string, args ...interface{}) {
+	logger.log(ctx, levelWarn, fmt.Sprintf(format, args...))
+}
+
+func (logger Logger) Errorf(ctx context.Context, format string, args ...interface{}) {
+	logger.log(ctx, levelError, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,140 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package parallel
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		n     int
+		limit int
+	}{
+		{name: "none", n: 0, limit: Limit},
+		{name: "sequential", n: 5, limit: 1},
+		{name: "fewer than limit", n: 3, limit: Limit},
+		{name: "more than limit", n: 50, limit: 4},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var running, maxRunning int32
+			results := make([]int, tc.n)
+
+			err := Run(context.Background(), tc.n, tc.limit, func(ctx context.Context, i int) error {
+				cur := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if cur <= max || atomic.CompareAndSwapInt32(&maxRunning, max, cur) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				results[i] = i + 1
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			for i, result := range results {
+				if result != i+1 {
+					t.Errorf("fn is not called for index %d", i)
+				}
+			}
+			if maxRunning > int32(tc.limit) {
+				t.Errorf("%d calls ran at the same time, limit %d", maxRunning, tc.limit)
+			}
+		})
+	}
+}
+
+func TestRunError(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name      string
+		limit     int
+		wantCalls int32 // upper bound of calls started
+	}{
+		{name: "sequential", limit: 1, wantCalls: 3},
+		{name: "concurrent", limit: 4, wantCalls: 6},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int32
+			err := Run(context.Background(), 100, tc.limit, func(ctx context.Context, i int) error {
+				atomic.AddInt32(&calls, 1)
+				if i == 2 {
+					return errFailed
+				}
+				if i < 2 {
+					return nil
+				}
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			if !errors.Is(err, errFailed) {
+				t.Errorf("Run() error = %v, want %v", err, errFailed)
+			}
+			if calls > tc.wantCalls {
+				t.Errorf("%d calls started after the error, want at most %d", calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls int32
+	err := Run(ctx, 100, 1, func(ctx context.Context, i int) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+	if calls > 1 {
+		t.Errorf("%d calls started with canceled context", calls)
+	}
+}
@@ -23,9 +23,11 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/account"
 	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/bservice"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/cost"
 	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/disks"
 	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/extnet"
 	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/image"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/k8ci"
 	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/k8s"
 	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/kvmvm"
 	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/lb"
@@ -40,11 +42,15 @@ func NewDataSourcesMap() map[string]*schema.Resource {
 		"decort_account":                        account.DataSourceAccount(),
 		"decort_resgroup":                       rg.DataSourceResgroup(),
 		"decort_kvmvm":                          kvmvm.DataSourceCompute(),
+		"decort_kvmvm_list":                     kvmvm.DataSourceComputeList(),
 		"decort_k8s":                            k8s.DataSourceK8s(),
 		"decort_k8s_list":                       k8s.DataSourceK8sList(),
 		"decort_k8s_list_deleted":               k8s.DataSourceK8sListDeleted(),
+		"decort_k8s_nodes":                      k8s.DataSourceK8sNodes(),
 		"decort_k8s_wg":                         k8s.DataSourceK8sWg(),
 		"decort_k8s_wg_list":                    k8s.DataSourceK8sWgList(),
+		"decort_k8ci":                           k8ci.DataSourceK8CI(),
+		"decort_k8ci_list":                      k8ci.DataSourceK8CIList(),
 		"decort_vins":                           vins.DataSourceVins(),
 		"decort_vins_list":                      vins.DataSourceVinsList(),
 		"decort_vins_audits":                    vins.DataSourceVinsAudits(),
@@ -74,6 +80,7 @@ func NewDataSourcesMap() map[string]*schema.Resource {
 		"decort_account_templates_list":         account.DataSourceAccountTemplatessList(),
 		"decort_account_deleted_list":           account.DataSourceAccountDeletedList(),
 		"decort_account_flipgroups_list":        account.DataSourceAccountFlipGroupsList(),
+		"decort_cost_estimate":                  cost.DataSourceCostEstimate(),
 		"decort_bservice_list":                  bservice.DataSourceBasicServiceList(),
 		"decort_bservice":                       bservice.DataSourceBasicService(),
 		"decort_bservice_snapshot_list":         bservice.DataSourceBasicServiceSnapshotList(),
@@ -36,24 +36,30 @@ import (
 
 func NewRersourcesMap() map[string]*schema.Resource {
 	return map[string]*schema.Resource{
-		"decort_resgroup":          rg.ResourceResgroup(),
-		"decort_kvmvm":             kvmvm.ResourceCompute(),
-		"decort_disk":              disks.ResourceDisk(),
-		"decort_disk_snapshot":     disks.ResourceDiskSnapshot(),
-		"decort_vins":              vins.ResourceVins(),
-		"decort_pfw":               pfw.ResourcePfw(),
-		"decort_k8s":               k8s.ResourceK8s(),
-		"decort_k8s_wg":            k8s.ResourceK8sWg(),
-		"decort_snapshot":          snapshot.ResourceSnapshot(),
-		"decort_account":           account.ResourceAccount(),
-		"decort_bservice":          bservice.ResourceBasicService(),
-		"decort_bservice_group":    bservice.ResourceBasicServiceGroup(),
-		"decort_image":             image.ResourceImage(),
-		"decort_image_virtual":     image.ResourceImageVirtual(),
-		"decort_lb":                lb.ResourceLB(),
-		"decort_lb_backend":        lb.ResourceLBBackend(),
-		"decort_lb_backend_server": lb.ResourceLBBackendServer(),
-		"decort_lb_frontend":       lb.ResourceLBFrontend(),
-		"decort_lb_frontend_bind":  lb.ResourceLBFrontendBind(),
+		"decort_resgroup":            rg.ResourceResgroup(),
+		"decort_kvmvm":               kvmvm.ResourceCompute(),
+		"decort_disk":                disks.ResourceDisk(),
+		"decort_disk_snapshot":       disks.ResourceDiskSnapshot(),
+		"decort_vins":                vins.ResourceVins(),
+		"decort_vins_ip_reservation": vins.ResourceVinsIpReservation(),
+		"decort_pfw":                 pfw.ResourcePfw(),
+		"decort_k8s":                 k8s.ResourceK8s(),
+		"decort_k8s_wg":              k8s.ResourceK8sWg(),
+		"decort_snapshot":            snapshot.ResourceSnapshot(),
+		"decort_snapshot_policy":     snapshot.ResourceSnapshotPolicy(),
+		"decort_snapshot_rollback":   snapshot.ResourceSnapshotRollback(),
+		"decort_account":             account.ResourceAccount(),
+		"decort_account_user":        account.ResourceAccountUser(),
+		"decort_account_group":       account.ResourceAccountGroup(),
+		"decort_bservice":            bservice.ResourceBasicService(),
+		"decort_bservice_group":      bservice.ResourceBasicServiceGroup(),
+		"decort_image":               image.ResourceImage(),
+		"decort_image_virtual":       image.ResourceImageVirtual(),
+		"decort_image_access":        image.ResourceImageAccess(),
+		"decort_lb":                  lb.ResourceLB(),
+		"decort_lb_backend":          lb.ResourceLBBackend(),
+		"decort_lb_backend_server":   lb.ResourceLBBackendServer(),
+		"decort_lb_frontend":         lb.ResourceLBFrontend(),
+		"decort_lb_frontend_bind":    lb.ResourceLBFrontendBind(),
 	}
 }
@@ -29,6 +29,7 @@ import (
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
 	"github.com/rudecs/terraform-provider-decort/internal/location"
+	"github.com/rudecs/terraform-provider-decort/internal/logging"
 	"github.com/rudecs/terraform-provider-decort/internal/statefuncs"
 )
 
@@ -100,6 +101,65 @@ func Provider() *schema.Provider {
 				Default:     false,
 				Description: "If true, DECORT API will not verify SSL certificates. Use this with caution and in trusted environments only!",
 			},
+
+			"default_tags": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Tags added to every resource that supports tags. Tags set on a resource override these.",
+			},
+
+			"name_prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Prefix required in names of computes, disks and ViNSes. Names without it are rejected at plan time.",
+			},
+
+			"name_pattern": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+				Description:  "Regular expression names of computes, disks and ViNSes should match. Names not matching it are rejected at plan time.",
+			},
+			"quota_check": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "off",
+				ValidateFunc: validation.StringInSlice([]string{"off", "warn", "error"}, false),
+				Description:  "Check at plan time that CPU, RAM, disk and external IPs planned for computes, disks, k8s clusters and resource groups fit account and resource group limits: off, warn (warnings reported by create and update of the resource) or error (fail the plan).",
+			},
+
+			"location": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"grid_id"},
+				Description:   "Code of the location resources are created in when they do not set their own. If neither location nor grid_id is set, the first location of the platform is used.",
+			},
+
+			"grid_id": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				ValidateFunc:  validation.IntAtLeast(1),
+				ConflictsWith: []string{"location"},
+				Description:   "ID of the grid resources are created in when they do not set their own.",
+			},
+
+			"trace_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("DECORT_TRACE_FILE", nil),
+				Description: "Path of the file every API call is appended to as a JSON line with its request ID, resource, operation, timing and status. Values of sensitive parameters are masked.",
+			},
+
+			"cache_ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("DECORT_CACHE_TTL", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Time in seconds responses of get and list API calls are reused for identical calls. Concurrent identical calls are sent to the controller once. Any other call drops cached responses. 0 disables the cache.",
+			},
 		},
 
 		ResourcesMap: selectSchema(false),
@@ -111,18 +171,21 @@ func Provider() *schema.Provider {
 }
 
 func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	decsController, err := controller.ControllerConfigure(d)
+	ctx = logging.Init(ctx, d.Get("password").(string), d.Get("jwt").(string), d.Get("app_secret").(string))
+
+	decsController, err := controller.ControllerConfigure(ctx, d)
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
 
-	gridId, err := location.UtilityLocationGetDefaultGridID(ctx, decsController)
+	gridId, err := location.UtilityLocationGetDefaultGridID(ctx, decsController, d.Get("location").(string), d.Get("grid_id").(int))
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
 	if gridId == 0 {
 		return nil, diag.FromErr(fmt.Errorf("providerConfigure: invalid default Grid ID = 0"))
 	}
+	decsController.SetDefaultGridID(gridId)
 
 	return decsController, nil
 }
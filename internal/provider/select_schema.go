@@ -34,9 +34,9 @@ func selectSchema(isDatasource bool) map[string]*schema.Resource {
 		adminMode = false
 	}
 	if isDatasource {
-		return selectDataSourceSchema(adminMode)
+		return traceResources(selectDataSourceSchema(adminMode), true)
 	}
-	return selectResourceSchema(adminMode)
+	return traceResources(selectResourceSchema(adminMode), false)
 
 }
 
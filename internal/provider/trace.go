@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/logging"
+)
+
+// traceResources makes API calls of resources and data sources attributed to them and
+// their operations in logs and the trace file
+func traceResources(resources map[string]*schema.Resource, isDatasource bool) map[string]*schema.Resource {
+	for name, resource := range resources {
+		if isDatasource {
+			name = "data." + name
+		}
+		traceResource(name, resource)
+	}
+	return resources
+}
+
+func traceResource(name string, resource *schema.Resource) {
+	resource.CreateContext = traceContextFunc(name, "create", resource.CreateContext)
+	resource.ReadContext = traceContextFunc(name, "read", resource.ReadContext)
+	resource.UpdateContext = traceContextFunc(name, "update", resource.UpdateContext)
+	resource.DeleteContext = traceContextFunc(name, "delete", resource.DeleteContext)
+
+	if customizeDiff := resource.CustomizeDiff; customizeDiff != nil {
+		resource.CustomizeDiff = func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			return customizeDiff(traceContext(ctx, m, name, "plan", d.Id()), d, m)
+		}
+	}
+
+	if resource.Importer != nil && resource.Importer.StateContext != nil {
+		stateContext := resource.Importer.StateContext
+		resource.Importer.StateContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+			return stateContext(traceContext(ctx, m, name, "import", d.Id()), d, m)
+		}
+	}
+}
+
+func traceContextFunc(name string, operation string, fn func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if fn == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		return fn(traceContext(ctx, m, name, operation, d.Id()), d, m)
+	}
+}
+
+// traceContext initializes loggers of the operation on the resource and adds its fields to
+// messages of all subsystems
+func traceContext(ctx context.Context, m interface{}, name string, operation string, id string) context.Context {
+	var secrets []string
+	if c, ok := m.(*controller.ControllerCfg); ok && c != nil {
+		secrets = c.Secrets()
+	}
+
+	ctx = logging.Init(ctx, secrets...)
+	ctx = logging.SetField(ctx, "resource", name)
+	ctx = logging.SetField(ctx, "operation", operation)
+	if id != "" {
+		ctx = logging.SetField(ctx, "id", id)
+	}
+	return controller.WithTrace(ctx, name, operation, id)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package quota
+
+// limits is how account/get and rg/get report resource limits, -1 means unlimited
+type limits struct {
+	CPU    float64 `json:"CU_C"`
+	RAM    float64 `json:"CU_M"`
+	Disk   float64 `json:"CU_D"`
+	ExtIPs float64 `json:"CU_I"`
+}
+
+// resource is how account/get and rg/get report resource usage
+type resource struct {
+	CPU    int     `json:"cpu"`
+	RAM    int     `json:"ram"`
+	Disk   float64 `json:"disksize"`
+	ExtIPs int     `json:"extips"`
+}
+
+type resources struct {
+	Current  resource `json:"Current"`
+	Reserved resource `json:"Reserved"`
+}
+
+// quotaOwner is the subset of account/get and rg/get response needed to check quotas
+type quotaOwner struct {
+	ID             int       `json:"id"`
+	AccountID      int       `json:"accountId"`
+	Name           string    `json:"name"`
+	ResourceLimits limits    `json:"resourceLimits"`
+	Resources      resources `json:"Resources"`
+}
@@ -0,0 +1,309 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+// Usage is the amount of resources a planned change adds: CPU count, RAM in MB,
+// disk in GB and external IP addresses
+type Usage struct {
+	CPU    int
+	RAM    int
+	Disk   int
+	ExtIPs int
+}
+
+func (u Usage) add(other Usage) Usage {
+	return Usage{
+		CPU:    u.CPU + other.CPU,
+		RAM:    u.RAM + other.RAM,
+		Disk:   u.Disk + other.Disk,
+		ExtIPs: u.ExtIPs + other.ExtIPs,
+	}
+}
+
+// positive drops decreases: released resources are not counted in favor of other changes,
+// as the order in which changes are applied is not known at plan time
+func (u Usage) positive() Usage {
+	res := Usage{}
+	if u.CPU > 0 {
+		res.CPU = u.CPU
+	}
+	if u.RAM > 0 {
+		res.RAM = u.RAM
+	}
+	if u.Disk > 0 {
+		res.Disk = u.Disk
+	}
+	if u.ExtIPs > 0 {
+		res.ExtIPs = u.ExtIPs
+	}
+	return res
+}
+
+type owner struct {
+	kind      string
+	id        int
+	name      string
+	accountId int
+	limits    limits
+	reserved  Usage
+	planned   map[string]Usage // changes planned for the owner by the key of the resource
+}
+
+func (o *owner) plannedTotal() Usage {
+	total := Usage{}
+	for _, usage := range o.planned {
+		total = total.add(usage)
+	}
+	return total
+}
+
+// state holds limits and reserved resources of accounts and resource groups as they were
+// when first checked by the provider instance, and the changes planned for them since.
+// Reserved resources are not reloaded: otherwise, during apply, resources created earlier
+// in the same run would be counted both as reserved and as planned.
+type state struct {
+	sync.Mutex
+	owners   map[string]*owner
+	warnings map[string][]string // exceeded limits not yet reported, by the key of the resource
+}
+
+func getState(c *controller.ControllerCfg) *state {
+	return c.QuotaState(func() interface{} {
+		return &state{
+			owners:   map[string]*owner{},
+			warnings: map[string][]string{},
+		}
+	}).(*state)
+}
+
+// Resource is implemented by both schema.ResourceData and schema.ResourceDiff
+type Resource interface {
+	Id() string
+	Get(key string) interface{}
+}
+
+// Key identifies the change planned for a resource: by its ID once it exists, and by its
+// kind, parent and name while it is created. Planning the resource again replaces its
+// usage instead of adding to it.
+func Key(kind string, d Resource, parentKey string, nameKey string) string {
+	if d.Id() != "" {
+		return fmt.Sprintf("%s/%s", kind, d.Id())
+	}
+	return fmt.Sprintf("%s/new/%v/%v", kind, d.Get(parentKey), d.Get(nameKey))
+}
+
+func (st *state) loadOwner(ctx context.Context, c *controller.ControllerCfg, kind string, id int) (*owner, error) {
+	key := fmt.Sprintf("%s/%d", kind, id)
+	if o, ok := st.owners[key]; ok {
+		return o, nil
+	}
+
+	urlValues := &url.Values{}
+	api := accountGetAPI
+	if kind == "rg" {
+		api = rgGetAPI
+		urlValues.Add("rgId", strconv.Itoa(id))
+	} else {
+		urlValues.Add("accountId", strconv.Itoa(id))
+	}
+
+	resp, err := c.DecortAPICall(ctx, "POST", api, urlValues)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load %s %d to check quota: %w", kind, id, err)
+	}
+
+	record := quotaOwner{}
+	if err := json.Unmarshal([]byte(resp), &record); err != nil {
+		return nil, err
+	}
+
+	o := &owner{
+		kind:      kind,
+		id:        id,
+		name:      record.Name,
+		accountId: record.AccountID,
+		limits:    record.ResourceLimits,
+		reserved: Usage{
+			CPU:    record.Resources.Reserved.CPU,
+			RAM:    record.Resources.Reserved.RAM,
+			Disk:   int(record.Resources.Reserved.Disk),
+			ExtIPs: record.Resources.Reserved.ExtIPs,
+		},
+		planned: map[string]Usage{},
+	}
+	st.owners[key] = o
+
+	logger.Debugf(ctx, "quota: loaded %s %d (%s), limits %+v, reserved %+v", kind, id, o.name, o.limits, o.reserved)
+	return o, nil
+}
+
+// forget drops the changes planned for the resource
+func (st *state) forget(key string) {
+	for _, o := range st.owners {
+		delete(o.planned, key)
+	}
+	delete(st.warnings, key)
+}
+
+func (o *owner) violations(delta Usage) []string {
+	res := make([]string, 0)
+	planned := o.plannedTotal()
+	check := func(resource string, limit float64, reserved int, planned int, requested int) {
+		if limit < 0 || reserved+planned <= int(limit) {
+			return
+		}
+		res = append(res, fmt.Sprintf("%s %d (%s): %s %d exceeds limit %d (reserved %d, planned in this run %d, of them by this resource %d)",
+			o.kind, o.id, o.name, resource, reserved+planned, int(limit), reserved, planned, requested))
+	}
+
+	check("cpu", o.limits.CPU, o.reserved.CPU, planned.CPU, delta.CPU)
+	check("ram", o.limits.RAM, o.reserved.RAM, planned.RAM, delta.RAM)
+	check("disk", o.limits.Disk, o.reserved.Disk, planned.Disk, delta.Disk)
+	check("external ips", o.limits.ExtIPs, o.reserved.ExtIPs, planned.ExtIPs, delta.ExtIPs)
+	return res
+}
+
+// plan records delta as the change planned for the resource identified by key, replacing the
+// one planned for it before, and returns the limits of the owners it exceeds
+func plan(key string, owners []*owner, delta Usage) []string {
+	violations := make([]string, 0)
+	for _, o := range owners {
+		o.planned[key] = delta
+		violations = append(violations, o.violations(delta)...)
+	}
+	return violations
+}
+
+// Check records resources planned for the resource identified by key among the resources
+// planned in this run for its account and resource group, and compares the total with their
+// limits. Depending on the quota_check provider argument exceeded limits are ignored, kept
+// to be reported as warnings by the resource operation (see Warnings) or returned as an error.
+// Either accountId or rgId may be 0 if not known at plan time.
+func Check(ctx context.Context, m interface{}, key string, accountId int, rgId int, delta Usage) error {
+	c := m.(*controller.ControllerCfg)
+	mode := c.QuotaCheck()
+	if mode == "" || mode == "off" {
+		return nil
+	}
+
+	st := getState(c)
+	st.Lock()
+	defer st.Unlock()
+
+	delta = delta.positive()
+	if delta == (Usage{}) {
+		st.forget(key)
+		return nil
+	}
+
+	checked := make([]*owner, 0, 2)
+	if rgId != 0 {
+		rg, err := st.loadOwner(ctx, c, "rg", rgId)
+		if err != nil {
+			return err
+		}
+		checked = append(checked, rg)
+		if accountId == 0 {
+			accountId = rg.accountId
+		}
+	}
+	if accountId != 0 {
+		account, err := st.loadOwner(ctx, c, "account", accountId)
+		if err != nil {
+			return err
+		}
+		checked = append(checked, account)
+	}
+
+	violations := plan(key, checked, delta)
+	if len(violations) == 0 {
+		delete(st.warnings, key)
+		return nil
+	}
+
+	if mode == "warn" {
+		for _, v := range violations {
+			logger.Warnf(ctx, "quota check: %s", v)
+		}
+		st.warnings[key] = violations
+		return nil
+	}
+	return fmt.Errorf("planned resources exceed quota:\n  %s", strings.Join(violations, "\n  "))
+}
+
+// Warnings returns the limits found exceeded when the resource identified by key was planned
+// in warn mode as warning diagnostics, as CustomizeDiff cannot return them
+func Warnings(m interface{}, key string) diag.Diagnostics {
+	c, ok := m.(*controller.ControllerCfg)
+	if !ok || c.QuotaCheck() != "warn" {
+		return nil
+	}
+
+	st := getState(c)
+	st.Lock()
+	defer st.Unlock()
+
+	var diags diag.Diagnostics
+	for _, v := range st.warnings[key] {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Planned resources exceed quota",
+			Detail:   v,
+		})
+	}
+	delete(st.warnings, key)
+	return diags
+}
+
+// WithWarnings appends the warnings of Warnings to the diagnostics of the create or update
+// operation fn. The key is taken before fn runs, as create sets the ID of the resource.
+func WithWarnings(fn func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics, key func(Resource) string) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		planKey := key(d)
+		return append(fn(ctx, d, m), Warnings(m, planKey)...)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package quota
+
+import "testing"
+
+func TestUsagePositive(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage Usage
+		want  Usage
+	}{
+		{name: "increase", usage: Usage{CPU: 2, RAM: 2048, Disk: 10, ExtIPs: 1}, want: Usage{CPU: 2, RAM: 2048, Disk: 10, ExtIPs: 1}},
+		{name: "decrease", usage: Usage{CPU: -2, RAM: -2048, Disk: -10, ExtIPs: -1}, want: Usage{}},
+		{name: "mixed", usage: Usage{CPU: -2, RAM: 1024, Disk: -10, ExtIPs: 1}, want: Usage{RAM: 1024, ExtIPs: 1}},
+		{name: "none", want: Usage{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.usage.positive(); got != tc.want {
+				t.Errorf("positive() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUsageAdd(t *testing.T) {
+	got := Usage{CPU: 1, RAM: 1024, Disk: 10}.add(Usage{CPU: 2, RAM: 512, ExtIPs: 1})
+	if want := (Usage{CPU: 3, RAM: 1536, Disk: 10, ExtIPs: 1}); got != want {
+		t.Errorf("add() = %+v, want %+v", got, want)
+	}
+}
+
+type testResource struct {
+	id    string
+	attrs map[string]interface{}
+}
+
+func (r testResource) Id() string                 { return r.id }
+func (r testResource) Get(key string) interface{} { return r.attrs[key] }
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		d    testResource
+		want string
+	}{
+		{name: "existing", d: testResource{id: "123", attrs: map[string]interface{}{"rg_id": 7, "name": "vm"}}, want: "compute/123"},
+		{name: "new", d: testResource{attrs: map[string]interface{}{"rg_id": 7, "name": "vm"}}, want: "compute/new/7/vm"},
+		{name: "new with unknown parent", d: testResource{attrs: map[string]interface{}{"rg_id": 0, "name": "vm"}}, want: "compute/new/0/vm"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Key("compute", tc.d, "rg_id", "name"); got != tc.want {
+				t.Errorf("Key() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func newTestOwner(kind string, id int, limits limits, reserved Usage) *owner {
+	return &owner{kind: kind, id: id, name: kind, limits: limits, reserved: reserved, planned: map[string]Usage{}}
+}
+
+func TestPlan(t *testing.T) {
+	type step struct {
+		key            string
+		delta          Usage
+		wantViolations int
+	}
+
+	tests := []struct {
+		name        string
+		limits      limits
+		reserved    Usage
+		steps       []step
+		wantPlanned Usage
+	}{
+		{
+			name:     "unlimited",
+			limits:   limits{CPU: -1, RAM: -1, Disk: -1, ExtIPs: -1},
+			reserved: Usage{CPU: 100},
+			steps: []step{
+				{key: "compute/new/1/a", delta: Usage{CPU: 100, RAM: 1 << 20}},
+			},
+			wantPlanned: Usage{CPU: 100, RAM: 1 << 20},
+		},
+		{
+			name:     "within limits",
+			limits:   limits{CPU: 8, RAM: 8192, Disk: 100, ExtIPs: 2},
+			reserved: Usage{CPU: 4, RAM: 4096, Disk: 50},
+			steps: []step{
+				{key: "compute/new/1/a", delta: Usage{CPU: 2, RAM: 2048, Disk: 25}},
+				{key: "compute/new/1/b", delta: Usage{CPU: 2, RAM: 2048, Disk: 25, ExtIPs: 2}},
+			},
+			wantPlanned: Usage{CPU: 4, RAM: 4096, Disk: 50, ExtIPs: 2},
+		},
+		{
+			name:     "exceeded by the sum of resources",
+			limits:   limits{CPU: 8, RAM: -1, Disk: -1, ExtIPs: -1},
+			reserved: Usage{CPU: 4},
+			steps: []step{
+				{key: "compute/new/1/a", delta: Usage{CPU: 3}},
+				{key: "compute/new/1/b", delta: Usage{CPU: 3}, wantViolations: 1},
+			},
+			wantPlanned: Usage{CPU: 6},
+		},
+		{
+			name:     "planning a resource again replaces its usage",
+			limits:   limits{CPU: 8, RAM: -1, Disk: -1, ExtIPs: -1},
+			reserved: Usage{CPU: 4},
+			steps: []step{
+				{key: "compute/123", delta: Usage{CPU: 4}},
+				{key: "compute/123", delta: Usage{CPU: 4}},
+				{key: "compute/123", delta: Usage{CPU: 3}},
+			},
+			wantPlanned: Usage{CPU: 3},
+		},
+		{
+			name:     "several limits exceeded",
+			limits:   limits{CPU: 2, RAM: 1024, Disk: 10, ExtIPs: 0},
+			reserved: Usage{},
+			steps: []step{
+				{key: "compute/123", delta: Usage{CPU: 4, RAM: 2048, Disk: 5, ExtIPs: 1}, wantViolations: 3},
+			},
+			wantPlanned: Usage{CPU: 4, RAM: 2048, Disk: 5, ExtIPs: 1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newTestOwner("rg", 1, tc.limits, tc.reserved)
+			for i, s := range tc.steps {
+				if got := plan(s.key, []*owner{o}, s.delta); len(got) != s.wantViolations {
+					t.Errorf("step %d: plan() = %q, want %d violations", i, got, s.wantViolations)
+				}
+			}
+			if got := o.plannedTotal(); got != tc.wantPlanned {
+				t.Errorf("plannedTotal() = %+v, want %+v", got, tc.wantPlanned)
+			}
+		})
+	}
+}
+
+func TestPlanOwners(t *testing.T) {
+	rg := newTestOwner("rg", 1, limits{CPU: -1, RAM: -1, Disk: -1, ExtIPs: -1}, Usage{})
+	account := newTestOwner("account", 2, limits{CPU: 4, RAM: -1, Disk: -1, ExtIPs: -1}, Usage{CPU: 2})
+
+	if got := plan("compute/new/1/a", []*owner{rg, account}, Usage{CPU: 4}); len(got) != 1 {
+		t.Errorf("plan() = %q, want the account limit exceeded", got)
+	}
+
+	st := &state{
+		owners:   map[string]*owner{"rg/1": rg, "account/2": account},
+		warnings: map[string][]string{"compute/new/1/a": {"exceeded"}},
+	}
+	st.forget("compute/new/1/a")
+	for _, o := range []*owner{rg, account} {
+		if got := o.plannedTotal(); got != (Usage{}) {
+			t.Errorf("%s plannedTotal() after forget = %+v, want none", o.kind, got)
+		}
+	}
+	if len(st.warnings) != 0 {
+		t.Errorf("warnings after forget = %v, want none", st.warnings)
+	}
+}
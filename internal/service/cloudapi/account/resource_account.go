@@ -42,11 +42,10 @@ import (
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
 	"github.com/rudecs/terraform-provider-decort/internal/flattens"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceAccountCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceAccountCreate")
+	logger.Debugf(ctx, "resourceAccountCreate")
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -170,7 +169,7 @@ func resourceAccountCreate(ctx context.Context, d *schema.ResourceData, m interf
 }
 
 func resourceAccountRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceAccountRead")
+	logger.Debugf(ctx, "resourceAccountRead")
 
 	acc, err := utilityAccountCheckPresence(ctx, d, m)
 	if acc == nil {
@@ -209,7 +208,7 @@ func resourceAccountRead(ctx context.Context, d *schema.ResourceData, m interfac
 }
 
 func resourceAccountDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceAccountDelete")
+	logger.Debugf(ctx, "resourceAccountDelete")
 
 	account, err := utilityAccountCheckPresence(ctx, d, m)
 	if account == nil {
@@ -234,7 +233,7 @@ func resourceAccountDelete(ctx context.Context, d *schema.ResourceData, m interf
 }
 
 func resourceAccountEdit(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceAccountEdit")
+	logger.Debugf(ctx, "resourceAccountEdit")
 	c := m.(*controller.ControllerCfg)
 
 	urlValues := &url.Values{}
@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package account
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/constants"
+)
+
+func resourceAccountGroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return resourceAccountAclCreate(ctx, d, m, aclTypeGroup, "group_id")
+}
+
+func resourceAccountGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return resourceAccountAclRead(ctx, d, m, aclTypeGroup, "group_id")
+}
+
+func resourceAccountGroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return resourceAccountAclUpdate(ctx, d, m, aclTypeGroup, "group_id")
+}
+
+func resourceAccountGroupDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return resourceAccountAclDelete(ctx, d, m, aclTypeGroup, "group_id")
+}
+
+func resourceAccountGroupImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	return resourceAccountAclImport(ctx, d, m, aclTypeGroup, "group_id")
+}
+
+func resourceAccountGroupSchemaMake() map[string]*schema.Schema {
+	sch := resourceAccountAclSchemaMake("group_id", "ID of the group to grant access to.")
+	sch["group_id"].Required = true
+	sch["group_id"].Optional = false
+	sch["group_id"].Computed = false
+	return sch
+}
+
+func ResourceAccountGroup() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+
+		CreateContext: resourceAccountGroupCreate,
+		ReadContext:   resourceAccountGroupRead,
+		UpdateContext: resourceAccountGroupUpdate,
+		DeleteContext: resourceAccountGroupDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceAccountGroupImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create:  &constants.Timeout60s,
+			Read:    &constants.Timeout30s,
+			Update:  &constants.Timeout60s,
+			Delete:  &constants.Timeout60s,
+			Default: &constants.Timeout60s,
+		},
+
+		Schema: resourceAccountGroupSchemaMake(),
+	}
+}
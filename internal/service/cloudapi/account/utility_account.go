@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -54,7 +53,7 @@ func utilityAccountCheckPresence(ctx context.Context, d *schema.ResourceData, m
 		urlValues.Add("accountId", d.Id())
 	}
 
-	log.Debugf("utilityAccountCheckPresence: load account")
+	logger.Debugf(ctx, "utilityAccountCheckPresence: load account")
 	accountRaw, err := c.DecortAPICall(ctx, "POST", accountGetAPI, urlValues)
 	if err != nil {
 		return nil, err
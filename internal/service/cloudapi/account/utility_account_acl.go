@@ -0,0 +1,325 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+// This is shared implementation of decort_account_user and decort_account_group resources,
+// each of them manages a single ACL entry of the account. The platform manages users and
+// groups in account ACL through the same API, which records groups granted through it as
+// entries of user type. Entries of group type come from the platform itself.
+
+const (
+	aclTypeUser  = "U"
+	aclTypeGroup = "G"
+)
+
+// aclTypeMatches reports whether an ACL entry of recType belongs to resources of aclType
+func aclTypeMatches(recType string, aclType string) bool {
+	if aclType == aclTypeGroup {
+		return recType == aclTypeGroup || recType == aclTypeUser
+	}
+	return recType == aclType
+}
+
+func utilityAccountAclGet(ctx context.Context, m interface{}, accountId int) ([]AccountAclRecord, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("accountId", strconv.Itoa(accountId))
+
+	accountRaw, err := c.DecortAPICall(ctx, "POST", accountGetAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	account := AccountWithResources{}
+	if err := json.Unmarshal([]byte(accountRaw), &account); err != nil {
+		return nil, err
+	}
+	return account.Acl, nil
+}
+
+func utilityAccountAclFind(acl []AccountAclRecord, aclType string, id string) *AccountAclRecord {
+	for _, rec := range acl {
+		if aclTypeMatches(rec.Type, aclType) && strings.EqualFold(rec.UgroupID, id) {
+			return &rec
+		}
+	}
+	return nil
+}
+
+func parseAccountAclId(id string) (int, string, error) {
+	parameters := strings.Split(id, "#")
+	if len(parameters) != 2 || parameters[1] == "" {
+		return 0, "", fmt.Errorf("invalid id %q: expected <account_id>#<user or group id>", id)
+	}
+
+	accountId, err := strconv.Atoi(parameters[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid id %q: account id must be a number", id)
+	}
+	return accountId, parameters[1], nil
+}
+
+func resourceAccountAclCreate(ctx context.Context, d *schema.ResourceData, m interface{}, aclType string, idKey string) diag.Diagnostics {
+	c := m.(*controller.ControllerCfg)
+	accountId := d.Get("account_id").(int)
+
+	subject := d.Get(idKey).(string)
+	if !d.Get("explicit").(bool) && subject != "" {
+		// access inherited by the subject is enough, the entry is adopted as is
+		acl, err := utilityAccountAclGet(ctx, m, accountId)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if rec := utilityAccountAclFind(acl, aclType, subject); rec != nil && !rec.IsExplicit {
+			logger.Debugf(ctx, "resourceAccountAclCreate: %s already has inherited access to account %d", subject, accountId)
+			d.SetId(fmt.Sprintf("%d#%s", accountId, rec.UgroupID))
+			return resourceAccountAclRead(ctx, d, m, aclType, idKey)
+		}
+	}
+
+	var before []AccountAclRecord
+	if email, ok := d.GetOk("email"); ok {
+		// the platform resolves the email to the user, ID of the user is found
+		// as the entry which appears in the ACL
+		subject = email.(string)
+		acl, err := utilityAccountAclGet(ctx, m, accountId)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		before = acl
+	}
+
+	logger.Debugf(ctx, "resourceAccountAclCreate: granting %s access to account %d for %s", d.Get("access_type").(string), accountId, subject)
+
+	urlValues := &url.Values{}
+	urlValues.Add("accountId", strconv.Itoa(accountId))
+	urlValues.Add("userId", subject)
+	urlValues.Add("accesstype", strings.ToUpper(d.Get("access_type").(string)))
+	if _, err := c.DecortAPICall(ctx, "POST", accountAddUserAPI, urlValues); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if before != nil {
+		acl, err := utilityAccountAclGet(ctx, m, accountId)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for _, rec := range acl {
+			if aclTypeMatches(rec.Type, aclType) && utilityAccountAclFind(before, aclType, rec.UgroupID) == nil {
+				subject = rec.UgroupID
+				break
+			}
+		}
+		if subject == d.Get("email").(string) {
+			return diag.Errorf("access to account %d was granted to %s, but no new ACL entry was found; the user may already have access, import it instead", accountId, subject)
+		}
+		d.Set(idKey, subject)
+	}
+
+	d.SetId(fmt.Sprintf("%d#%s", accountId, subject))
+
+	return resourceAccountAclRead(ctx, d, m, aclType, idKey)
+}
+
+func resourceAccountAclRead(ctx context.Context, d *schema.ResourceData, m interface{}, aclType string, idKey string) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceAccountAclRead: called for %s", d.Id())
+
+	accountId, id, err := parseAccountAclId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	acl, err := utilityAccountAclGet(ctx, m, accountId)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rec := utilityAccountAclFind(acl, aclType, id)
+	if rec == nil {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("account_id", accountId)
+	d.Set(idKey, rec.UgroupID)
+	d.Set("access_type", rec.Rights)
+	// inherited access satisfies explicit = false, so only the lack of an explicit entry is a drift
+	if d.Get("explicit").(bool) {
+		d.Set("explicit", rec.IsExplicit)
+	}
+	d.Set("can_be_deleted", rec.CanBeDeleted)
+	d.Set("status", rec.Status)
+
+	return nil
+}
+
+func resourceAccountAclUpdate(ctx context.Context, d *schema.ResourceData, m interface{}, aclType string, idKey string) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceAccountAclUpdate: called for %s", d.Id())
+
+	if d.HasChange("explicit") && d.Get("explicit").(bool) {
+		// the access was inherited so far, an explicit entry is added with the configured rights
+		c := m.(*controller.ControllerCfg)
+		urlValues := &url.Values{}
+		urlValues.Add("accountId", strconv.Itoa(d.Get("account_id").(int)))
+		urlValues.Add("userId", d.Get(idKey).(string))
+		urlValues.Add("accesstype", strings.ToUpper(d.Get("access_type").(string)))
+		if _, err := c.DecortAPICall(ctx, "POST", accountAddUserAPI, urlValues); err != nil {
+			return diag.FromErr(err)
+		}
+	} else if d.HasChange("access_type") {
+		c := m.(*controller.ControllerCfg)
+		urlValues := &url.Values{}
+		urlValues.Add("accountId", strconv.Itoa(d.Get("account_id").(int)))
+		urlValues.Add("userId", d.Get(idKey).(string))
+		urlValues.Add("accesstype", strings.ToUpper(d.Get("access_type").(string)))
+		if _, err := c.DecortAPICall(ctx, "POST", accountUpdateUserAPI, urlValues); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceAccountAclRead(ctx, d, m, aclType, idKey)
+}
+
+func resourceAccountAclDelete(ctx context.Context, d *schema.ResourceData, m interface{}, aclType string, idKey string) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceAccountAclDelete: called for %s", d.Id())
+
+	acl, err := utilityAccountAclGet(ctx, m, d.Get("account_id").(int))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if rec := utilityAccountAclFind(acl, aclType, d.Get(idKey).(string)); rec == nil || !rec.IsExplicit {
+		// inherited access is managed where it is granted
+		d.SetId("")
+		return nil
+	}
+
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("accountId", strconv.Itoa(d.Get("account_id").(int)))
+	urlValues.Add("userId", d.Get(idKey).(string))
+	urlValues.Add("recursivedelete", strconv.FormatBool(d.Get("recursive_delete").(bool)))
+	if _, err := c.DecortAPICall(ctx, "POST", accountDeleteUserAPI, urlValues); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceAccountAclImport(ctx context.Context, d *schema.ResourceData, m interface{}, aclType string, idKey string) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourceAccountAclImport: called with id %s", d.Id())
+
+	accountId, id, err := parseAccountAclId(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	acl, err := utilityAccountAclGet(ctx, m, accountId)
+	if err != nil {
+		return nil, err
+	}
+	rec := utilityAccountAclFind(acl, aclType, id)
+	if rec == nil {
+		return nil, fmt.Errorf("%s has no access to account %d", id, accountId)
+	}
+
+	d.Set("account_id", accountId)
+	d.Set(idKey, id)
+	d.Set("explicit", rec.IsExplicit)
+	d.Set("recursive_delete", false)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceAccountAclSchemaMake(idKey string, idDescription string) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"account_id": {
+			Type:        schema.TypeInt,
+			Required:    true,
+			ForceNew:    true,
+			Description: "ID of the account to grant access to.",
+		},
+		idKey: {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			ForceNew:    true,
+			Description: idDescription,
+		},
+		"access_type": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.StringInSlice([]string{"R", "RCX", "ARCXDU"}, true),
+			DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+				return strings.EqualFold(old, new)
+			},
+			Description: "Access rights: R (read), RCX (read and write) or ARCXDU (administration).",
+		},
+		"recursive_delete": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Also revoke access to resource groups and other objects of the account when the resource is destroyed.",
+		},
+		"explicit": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Whether the access must be granted on the account explicitly. If false, access the subject already inherits is adopted instead of adding an entry, and it is not revoked when the resource is destroyed.",
+		},
+		"can_be_deleted": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "Whether the ACL entry can be deleted.",
+		},
+		"status": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Status of the ACL entry.",
+		},
+	}
+}
@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -50,7 +49,7 @@ func utilityAccountConsumedUnitsCheckPresence(ctx context.Context, d *schema.Res
 
 	urlValues.Add("accountId", strconv.Itoa(d.Get("account_id").(int)))
 
-	log.Debugf("utilityAccountConsumedUnitsCheckPresence: load account list")
+	logger.Debugf(ctx, "utilityAccountConsumedUnitsCheckPresence: load account list")
 	accountConsumedUnitsRaw, err := c.DecortAPICall(ctx, "POST", accountGetConsumedUnitsAPI, urlValues)
 	if err != nil {
 		return nil, err
@@ -38,7 +38,6 @@ import (
 	"strings"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -50,7 +49,7 @@ func utilityAccountConsumedUnitsByTypeCheckPresence(ctx context.Context, d *sche
 	urlValues.Add("accountId", strconv.Itoa(d.Get("account_id").(int)))
 	urlValues.Add("cutype", strings.ToUpper(d.Get("cu_type").(string)))
 
-	log.Debugf("utilityAccountConsumedUnitsByTypeCheckPresence")
+	logger.Debugf(ctx, "utilityAccountConsumedUnitsByTypeCheckPresence")
 	resultRaw, err := c.DecortAPICall(ctx, "POST", accountGetConsumedUnitsByTypeAPI, urlValues)
 	if err != nil {
 		return 0, err
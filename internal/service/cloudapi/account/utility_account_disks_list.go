@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -50,7 +49,7 @@ func utilityAccountDisksListCheckPresence(ctx context.Context, d *schema.Resourc
 
 	urlValues.Add("accountId", strconv.Itoa(d.Get("account_id").(int)))
 
-	log.Debugf("utilityAccountDisksListCheckPresence: load account list")
+	logger.Debugf(ctx, "utilityAccountDisksListCheckPresence: load account list")
 	accountDisksListRaw, err := c.DecortAPICall(ctx, "POST", accountListDisksAPI, urlValues)
 	if err != nil {
 		return nil, err
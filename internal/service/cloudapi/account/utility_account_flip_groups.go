@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -50,7 +49,7 @@ func utilityAccountFlipGroupsListCheckPresence(ctx context.Context, d *schema.Re
 
 	urlValues.Add("accountId", strconv.Itoa(d.Get("account_id").(int)))
 
-	log.Debugf("utilityAccountFlipGroupsListCheckPresence")
+	logger.Debugf(ctx, "utilityAccountFlipGroupsListCheckPresence")
 	accountFlipGroupsListRaw, err := c.DecortAPICall(ctx, "POST", accountListFlipGroupsAPI, urlValues)
 	if err != nil {
 		return nil, err
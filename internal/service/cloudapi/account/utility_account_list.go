@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -55,7 +54,7 @@ func utilityAccountListCheckPresence(ctx context.Context, d *schema.ResourceData
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityAccountListCheckPresence: load account list")
+	logger.Debugf(ctx, "utilityAccountListCheckPresence: load account list")
 	accountListRaw, err := c.DecortAPICall(ctx, "POST", accountListAPI, urlValues)
 	if err != nil {
 		return nil, err
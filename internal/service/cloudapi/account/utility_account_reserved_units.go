@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -50,7 +49,7 @@ func utilityAccountReservedUnitsCheckPresence(ctx context.Context, d *schema.Res
 
 	urlValues.Add("accountId", strconv.Itoa(d.Get("account_id").(int)))
 
-	log.Debugf("utilityAccountReservedUnitsCheckPresence: load units")
+	logger.Debugf(ctx, "utilityAccountReservedUnitsCheckPresence: load units")
 	accountReservedUnitsRaw, err := c.DecortAPICall(ctx, "POST", accountGetReservedUnitsAPI, urlValues)
 	if err != nil {
 		return nil, err
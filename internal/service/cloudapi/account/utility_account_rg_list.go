@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -50,7 +49,7 @@ func utilityAccountRGListCheckPresence(ctx context.Context, d *schema.ResourceDa
 
 	urlValues.Add("accountId", strconv.Itoa(d.Get("account_id").(int)))
 
-	log.Debugf("utilityAccountRGListCheckPresence: load account list")
+	logger.Debugf(ctx, "utilityAccountRGListCheckPresence: load account list")
 	accountRGListRaw, err := c.DecortAPICall(ctx, "POST", accountListRGAPI, urlValues)
 	if err != nil {
 		return nil, err
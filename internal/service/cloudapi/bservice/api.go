@@ -57,3 +57,10 @@ const bserviceSnapshotListAPI = "/restmachine/cloudapi/bservice/snapshotList"
 const bserviceSnapshotRollbackAPI = "/restmachine/cloudapi/bservice/snapshotRollback"
 const bserviceStartAPI = "/restmachine/cloudapi/bservice/start"
 const bserviceStopAPI = "/restmachine/cloudapi/bservice/stop"
+
+const computeGetAPI = "/restmachine/cloudapi/compute/get"
+const computeRedeployAPI = "/restmachine/cloudapi/compute/redeploy"
+const computeResizeAPI = "/restmachine/cloudapi/compute/resize"
+const computeStartAPI = "/restmachine/cloudapi/compute/start"
+const computeStopAPI = "/restmachine/cloudapi/compute/stop"
+const disksResizeAPI = "/restmachine/cloudapi/disks/resize2"
@@ -92,6 +92,23 @@ type BasicServiceExtend struct {
 	SSHKey     string                `json:"sshKey"`
 }
 
+// BasicServiceComputeStatus is the part of compute/get response the health of a service is computed from
+// and rolling updates of groups work with
+type BasicServiceComputeStatus struct {
+	ID         int                       `json:"id"`
+	CPU        int                       `json:"cpus"`
+	RAM        int                       `json:"ram"`
+	ImageID    int                       `json:"imageId"`
+	Disks      []BasicServiceComputeDisk `json:"disks"`
+	TechStatus string                    `json:"techStatus"`
+}
+
+type BasicServiceComputeDisk struct {
+	ID      int    `json:"id"`
+	SizeMax int    `json:"sizeMax"`
+	Type    string `json:"type"`
+}
+
 type BasicServiceGroupOSUser struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
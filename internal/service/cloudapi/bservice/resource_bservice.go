@@ -35,16 +35,17 @@ import (
 	"context"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceBasicServiceCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceBasicServiceCreate")
+	logger.Debugf(ctx, "resourceBasicServiceCreate")
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -67,6 +68,21 @@ func resourceBasicServiceCreate(ctx context.Context, d *schema.ResourceData, m i
 	d.SetId(serviceId)
 	d.Set("service_id", serviceId)
 
+	if groups := d.Get("group").([]interface{}); len(groups) != 0 {
+		id, _ := strconv.Atoi(serviceId)
+		groups, err = utilityBasicServiceGroupsConfigure(ctx, m, id, nil, groups)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set("group", groups)
+
+		if d.Get("start").(bool) {
+			if err := utilityBasicServiceGroupsStart(ctx, m, id, groups); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	}
+
 	diagnostics := resourceBasicServiceRead(ctx, d, m)
 	if diagnostics != nil {
 		return diagnostics
@@ -76,7 +92,7 @@ func resourceBasicServiceCreate(ctx context.Context, d *schema.ResourceData, m i
 }
 
 func resourceBasicServiceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceBasicServiceRead")
+	logger.Debugf(ctx, "resourceBasicServiceRead")
 
 	bs, err := utilityBasicServiceCheckPresence(ctx, d, m)
 	if bs == nil {
@@ -115,11 +131,30 @@ func resourceBasicServiceRead(ctx context.Context, d *schema.ResourceData, m int
 	d.Set("updated_time", bs.UpdatedTime)
 	d.Set("user_managed", bs.UserManaged)
 
+	computeIds := make([]int, 0, len(bs.Computes))
+	for _, compute := range bs.Computes {
+		computeIds = append(computeIds, compute.ID)
+	}
+	// statuses of the computes of the service also give health of its groups
+	statuses, err := utilityBasicServiceComputeStatuses(ctx, m, computeIds)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("health", bserviceHealth(statuses, computeIds))
+
+	if groups := d.Get("group").([]interface{}); len(groups) != 0 {
+		groups, err = utilityBasicServiceGroupsRead(ctx, m, bs, groups, statuses)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set("group", groups)
+	}
+
 	return nil
 }
 
 func resourceBasicServiceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceBasicServiceDelete")
+	logger.Debugf(ctx, "resourceBasicServiceDelete")
 
 	bs, err := utilityBasicServiceCheckPresence(ctx, d, m)
 	if bs == nil {
@@ -144,7 +179,7 @@ func resourceBasicServiceDelete(ctx context.Context, d *schema.ResourceData, m i
 }
 
 func resourceBasicServiceEdit(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceBasicServiceEdit")
+	logger.Debugf(ctx, "resourceBasicServiceEdit")
 	c := m.(*controller.ControllerCfg)
 
 	urlValues := &url.Values{}
@@ -177,7 +212,29 @@ func resourceBasicServiceEdit(ctx context.Context, d *schema.ResourceData, m int
 		}
 	}
 
-	if d.HasChange("start") {
+	groups := d.Get("group").([]interface{})
+	if d.HasChange("group") {
+		oldGroups, newGroups := d.GetChange("group")
+		var err error
+		groups, err = utilityBasicServiceGroupsConfigure(ctx, m, d.Get("service_id").(int), oldGroups.([]interface{}), newGroups.([]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set("group", groups)
+	}
+
+	if len(groups) != 0 && d.HasChanges("start", "group") {
+		// declared groups are started and stopped one by one in the order of their dependencies
+		var err error
+		if d.Get("start").(bool) {
+			err = utilityBasicServiceGroupsStart(ctx, m, d.Get("service_id").(int), groups)
+		} else if d.HasChange("start") {
+			err = utilityBasicServiceGroupsStop(ctx, m, d.Get("service_id").(int), groups)
+		}
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	} else if d.HasChange("start") {
 		api := bserviceStopAPI
 		start := d.Get("start").(bool)
 		if start {
@@ -287,6 +344,116 @@ func isRollback(els []interface{}, el interface{}) bool {
 	return false
 }
 
+// resourceBasicServiceCustomizeDiff rejects declared groups with duplicate names, unknown
+// dependencies or dependency cycles at plan time
+func resourceBasicServiceCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("group") {
+		return nil
+	}
+	_, err := bserviceGroupsOrder(d.Get("group").([]interface{}))
+	return err
+}
+
+func resourceBasicServiceDeclaredGroupSchemaMake() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "name of the Compute Group, unique within the service",
+		},
+		"comp_count": {
+			Type:        schema.TypeInt,
+			Required:    true,
+			Description: "computes number. Defines how many computes must be there in the group",
+		},
+		"cpu": {
+			Type:        schema.TypeInt,
+			Required:    true,
+			Description: "compute CPU number. All computes in the group have the same CPU count",
+		},
+		"ram": {
+			Type:        schema.TypeInt,
+			Required:    true,
+			Description: "compute RAM volume in MB. All computes in the group have the same RAM volume",
+		},
+		"disk": {
+			Type:        schema.TypeInt,
+			Required:    true,
+			Description: "compute boot disk size in GB",
+		},
+		"image_id": {
+			Type:        schema.TypeInt,
+			Required:    true,
+			Description: "OS image ID to create computes from. Changing it recreates the group",
+		},
+		"driver": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Default:          "KVM_X86",
+			ValidateFunc:     validation.StringInSlice([]string{"KVM_X86", "KVM_PPC"}, true),
+			DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool { return strings.EqualFold(old, new) },
+			Description:      "compute driver like a KVM_X86, KVM_PPC, etc. Changing it recreates the group",
+		},
+		"role": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     "",
+			Description: "group role tag",
+		},
+		"timeout_start": {
+			Type:        schema.TypeInt,
+			Optional:    true,
+			Default:     0,
+			Description: "time in seconds the group is given to become healthy after start before the start of the service fails. Unlimited within the resource timeout if 0",
+		},
+		"vinses": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeInt,
+			},
+			Description: "IDs of ViNSes to connect computes of the group to",
+		},
+		"extnets": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeInt,
+			},
+			Description: "IDs of external networks to connect computes of the group to",
+		},
+		"depends_on_groups": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+			Description: "names of the groups of this service to start before this group. Set as parents of the group",
+		},
+		"compgroup_id": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"tech_status": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"health": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "HEALTHY if all computes of the group are started, DOWN if none, DEGRADED otherwise, UNKNOWN if the group has no computes. Refresh of declared groups costs a group get call per group",
+		},
+		"computes": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeInt,
+			},
+			Description: "IDs of computes of the group",
+		},
+	}
+}
+
 func resourceBasicServiceSchemaMake() map[string]*schema.Schema {
 	return map[string]*schema.Schema{
 		"service_name": {
@@ -333,7 +500,20 @@ func resourceBasicServiceSchemaMake() map[string]*schema.Schema {
 			Type:        schema.TypeBool,
 			Optional:    true,
 			Default:     false,
-			Description: "Start service. Starting a service technically means starting computes from all service groups according to group relations",
+			Description: "Start service. Starting a service technically means starting computes from all service groups according to group relations. If groups are declared in group blocks, they are started one by one in the order of depends_on_groups, each after the groups it depends on are healthy, and stopped in reverse order",
+		},
+		"group": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Compute Groups of the service. Groups are matched by name, do not manage them with decort_bservice_group at the same time",
+			Elem: &schema.Resource{
+				Schema: resourceBasicServiceDeclaredGroupSchemaMake(),
+			},
+		},
+		"health": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "HEALTHY if all computes of the service are started, DOWN if none, DEGRADED otherwise, UNKNOWN if the service has no computes. Every refresh costs a compute get call per compute of the service, as the service does not report status of its computes",
 		},
 		"service_id": {
 			Type:     schema.TypeInt,
@@ -510,6 +690,8 @@ func ResourceBasicService() *schema.Resource {
 		UpdateContext: resourceBasicServiceEdit,
 		DeleteContext: resourceBasicServiceDelete,
 
+		CustomizeDiff: resourceBasicServiceCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
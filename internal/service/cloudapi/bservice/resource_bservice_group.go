@@ -33,6 +33,7 @@ package bservice
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -42,11 +43,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceBasicServiceGroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceBasicServiceGroupCreate")
+	logger.Debugf(ctx, "resourceBasicServiceGroupCreate")
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -115,7 +115,7 @@ func resourceBasicServiceGroupCreate(ctx context.Context, d *schema.ResourceData
 }
 
 func resourceBasicServiceGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceBasicServiceGroupRead")
+	logger.Debugf(ctx, "resourceBasicServiceGroupRead")
 
 	bsg, err := utilityBasicServiceGroupCheckPresence(ctx, d, m)
 	if bsg == nil {
@@ -137,7 +137,12 @@ func resourceBasicServiceGroupRead(ctx context.Context, d *schema.ResourceData,
 	d.Set("extnets", bsg.Extnets)
 	d.Set("gid", bsg.GID)
 	d.Set("guid", bsg.GUID)
-	d.Set("image_id", bsg.ImageId)
+	imageId := bsg.ImageId
+	if _, ok := rollingUpdateConfigGet(d); ok && d.Get("image_id").(int) != bsg.ImageId &&
+		utilityBasicServiceGroupImageApplied(ctx, m, bsg, d.Get("image_id").(int)) {
+		imageId = d.Get("image_id").(int)
+	}
+	d.Set("image_id", imageId)
 	d.Set("milestones", bsg.Milestones)
 	d.Set("compgroup_name", bsg.Name)
 	d.Set("compgroup_id", bsg.ID)
@@ -159,7 +164,7 @@ func resourceBasicServiceGroupRead(ctx context.Context, d *schema.ResourceData,
 }
 
 func resourceBasicServiceGroupDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceBasicServiceGroupDelete")
+	logger.Debugf(ctx, "resourceBasicServiceGroupDelete")
 
 	bsg, err := utilityBasicServiceGroupCheckPresence(ctx, d, m)
 	if bsg == nil {
@@ -184,7 +189,7 @@ func resourceBasicServiceGroupDelete(ctx context.Context, d *schema.ResourceData
 }
 
 func resourceBasicServiceGroupEdit(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceBasicServiceGroupEdit")
+	logger.Debugf(ctx, "resourceBasicServiceGroupEdit")
 	c := m.(*controller.ControllerCfg)
 
 	urlValues := &url.Values{}
@@ -221,7 +226,37 @@ func resourceBasicServiceGroupEdit(ctx context.Context, d *schema.ResourceData,
 		urlValues = &url.Values{}
 	}
 
-	if d.HasChanges("compgroup_name", "ram", "cpu", "disk", "role") {
+	if cfg, ok := rollingUpdateConfigGet(d); ok && d.HasChanges("ram", "cpu", "disk", "image_id") {
+		// computes are updated batch by batch, the group definition is updated without force once
+		// all of them are. Until then the group keeps reporting the old size, so a failed update
+		// is retried on the next apply.
+		if err := utilityBasicServiceGroupRollingUpdate(ctx, d, m, cfg); err != nil {
+			// keep the old size and image in the state and the progress of the computes, so that
+			// the failure is visible
+			for _, key := range []string{"cpu", "ram", "disk", "image_id"} {
+				oldValue, _ := d.GetChange(key)
+				d.Set(key, oldValue)
+			}
+			return diag.FromErr(err)
+		}
+
+		urlValues.Add("name", d.Get("compgroup_name").(string))
+		urlValues.Add("cpu", strconv.Itoa(d.Get("cpu").(int)))
+		urlValues.Add("ram", strconv.Itoa(d.Get("ram").(int)))
+		urlValues.Add("disk", strconv.Itoa(d.Get("disk").(int)))
+		urlValues.Add("role", d.Get("role").(string))
+		urlValues.Add("force", "false")
+
+		urlValues.Add("serviceId", strconv.Itoa(d.Get("service_id").(int)))
+		urlValues.Add("compgroupId", strconv.Itoa(d.Get("compgroup_id").(int)))
+
+		_, err := c.DecortAPICall(ctx, "POST", bserviceGroupUpdateAPI, urlValues)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		urlValues = &url.Values{}
+	} else if d.HasChanges("compgroup_name", "ram", "cpu", "disk", "role") {
 		urlValues.Add("name", d.Get("compgroup_name").(string))
 		urlValues.Add("cpu", strconv.Itoa(d.Get("cpu").(int)))
 		urlValues.Add("ram", strconv.Itoa(d.Get("ram").(int)))
@@ -375,6 +410,19 @@ func isContainsParent(els []interface{}, el interface{}) bool {
 	return false
 }
 
+func resourceBasicServiceGroupCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	rollingUpdateList := d.Get("rolling_update").([]interface{})
+	if len(rollingUpdateList) == 0 || rollingUpdateList[0] == nil {
+		return nil
+	}
+
+	rollingUpdate := rollingUpdateList[0].(map[string]interface{})
+	if rollingUpdate["health_check"].(string) == "tcp" && rollingUpdate["health_check_port"].(int) == 0 {
+		return fmt.Errorf("rolling_update: health_check_port is required with the tcp health check")
+	}
+	return nil
+}
+
 func resourceBasicServiceGroupSchemaMake() map[string]*schema.Schema {
 	return map[string]*schema.Schema{
 		"service_id": {
@@ -472,6 +520,76 @@ func resourceBasicServiceGroupSchemaMake() map[string]*schema.Schema {
 			Default:     false,
 			Description: "force resize Compute Group",
 		},
+		"rolling_update": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "Apply cpu, ram, disk and image_id changes to computes of the group batch by batch instead of all at once. force_update is not used then",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"max_unavailable": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						Default:      1,
+						ValidateFunc: validation.IntAtLeast(1),
+						Description:  "number of computes updated at a time",
+					},
+					"batch_pause": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						Default:      0,
+						ValidateFunc: validation.IntAtLeast(0),
+						Description:  "pause in seconds between batches",
+					},
+					"health_check": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      "tech_status",
+						ValidateFunc: validation.StringInSlice([]string{"tech_status", "tcp"}, false),
+						Description:  "(tech_status;tcp) compute is healthy when it is started or, with tcp, also accepts connections on health_check_port",
+					},
+					"health_check_port": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IsPortNumber,
+						Description:  "TCP port checked on the compute addresses with the tcp health check",
+					},
+					"health_check_timeout": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						Default:      300,
+						ValidateFunc: validation.IntAtLeast(1),
+						Description:  "time in seconds an updated compute is given to become healthy before the update is aborted",
+					},
+				},
+			},
+		},
+		"rolling_update_progress": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "progress of the last rolling update by compute",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"compute_id": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"name": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"status": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "PENDING, UPDATED or FAILED",
+					},
+					"error": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+				},
+			},
+		},
 		"parents": {
 			Type:     schema.TypeList,
 			Optional: true,
@@ -615,6 +733,8 @@ func ResourceBasicServiceGroup() *schema.Resource {
 		UpdateContext: resourceBasicServiceGroupEdit,
 		DeleteContext: resourceBasicServiceGroupDelete,
 
+		CustomizeDiff: resourceBasicServiceGroupCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -61,7 +60,7 @@ func utilityBasicServiceDeletedListCheckPresence(ctx context.Context, d *schema.
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityBasicServiceDeletedListCheckPresence")
+	logger.Debugf(ctx, "utilityBasicServiceDeletedListCheckPresence")
 	basicServiceDeletedListRaw, err := c.DecortAPICall(ctx, "POST", bserviceListDeletedAPI, urlValues)
 	if err != nil {
 		return nil, err
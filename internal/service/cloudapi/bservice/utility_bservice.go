@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -54,7 +53,7 @@ func utilityBasicServiceCheckPresence(ctx context.Context, d *schema.ResourceDat
 		urlValues.Add("serviceId", d.Id())
 	}
 
-	log.Debugf("utilityBasicServiceCheckPresence")
+	logger.Debugf(ctx, "utilityBasicServiceCheckPresence")
 	bserviceRaw, err := c.DecortAPICall(ctx, "POST", bserviceGetAPI, urlValues)
 	if err != nil {
 		return nil, err
@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -55,7 +54,7 @@ func utilityBasicServiceGroupCheckPresence(ctx context.Context, d *schema.Resour
 		urlValues.Add("compgroupId", d.Id())
 	}
 
-	log.Debugf("utilityBasicServiceGroupCheckPresence")
+	logger.Debugf(ctx, "utilityBasicServiceGroupCheckPresence")
 	bserviceGroupRaw, err := c.DecortAPICall(ctx, "POST", bserviceGroupGetAPI, urlValues)
 	if err != nil {
 		return nil, err
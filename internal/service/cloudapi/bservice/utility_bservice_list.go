@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -61,7 +60,7 @@ func utilityBasicServiceListCheckPresence(ctx context.Context, d *schema.Resourc
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityBasicServiceListCheckPresence")
+	logger.Debugf(ctx, "utilityBasicServiceListCheckPresence")
 	basicServiceListRaw, err := c.DecortAPICall(ctx, "POST", bserviceListAPI, urlValues)
 	if err != nil {
 		return nil, err
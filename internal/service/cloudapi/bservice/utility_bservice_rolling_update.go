@@ -0,0 +1,316 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package bservice
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/parallel"
+)
+
+// Progress of a compute in a rolling update
+const (
+	rollingUpdatePending = "PENDING"
+	rollingUpdateUpdated = "UPDATED"
+	rollingUpdateFailed  = "FAILED"
+)
+
+type rollingUpdateConfig struct {
+	maxUnavailable     int
+	batchPause         time.Duration
+	healthCheck        string
+	healthCheckPort    int
+	healthCheckTimeout time.Duration
+}
+
+func rollingUpdateConfigGet(d *schema.ResourceData) (*rollingUpdateConfig, bool) {
+	rollingUpdateList := d.Get("rolling_update").([]interface{})
+	if len(rollingUpdateList) == 0 || rollingUpdateList[0] == nil {
+		return nil, false
+	}
+
+	rollingUpdate := rollingUpdateList[0].(map[string]interface{})
+	return &rollingUpdateConfig{
+		maxUnavailable:     rollingUpdate["max_unavailable"].(int),
+		batchPause:         time.Duration(rollingUpdate["batch_pause"].(int)) * time.Second,
+		healthCheck:        rollingUpdate["health_check"].(string),
+		healthCheckPort:    rollingUpdate["health_check_port"].(int),
+		healthCheckTimeout: time.Duration(rollingUpdate["health_check_timeout"].(int)) * time.Second,
+	}, true
+}
+
+// utilityBasicServiceComputeHealthy checks the compute is started and, with the tcp health check,
+// that the port accepts connections on one of the compute addresses
+func utilityBasicServiceComputeHealthy(ctx context.Context, m interface{}, cfg *rollingUpdateConfig, computeId int, addresses []string) (bool, error) {
+	compute, err := utilityBasicServiceComputeGet(ctx, m, computeId)
+	if err != nil {
+		return false, err
+	}
+	if compute.TechStatus != "STARTED" {
+		return false, nil
+	}
+	if cfg.healthCheck != "tcp" {
+		return true, nil
+	}
+
+	dialer := net.Dialer{Timeout: 5 * time.Second}
+	for _, address := range addresses {
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, strconv.Itoa(cfg.healthCheckPort)))
+		if err == nil {
+			conn.Close()
+			return true, nil
+		}
+		logger.Debugf(ctx, "utilityBasicServiceComputeHealthy: compute ID %d port %d on %s: %v", computeId, cfg.healthCheckPort, address, err)
+	}
+	return false, nil
+}
+
+func utilityBasicServiceComputeWaitHealthy(ctx context.Context, m interface{}, cfg *rollingUpdateConfig, computeId int, addresses []string) error {
+	timer := time.NewTimer(cfg.healthCheckTimeout)
+	defer timer.Stop()
+
+	ctx = controller.WithoutCache(ctx)
+	for {
+		healthy, err := utilityBasicServiceComputeHealthy(ctx, m, cfg, computeId, addresses)
+		if err != nil {
+			return err
+		}
+		if healthy {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return fmt.Errorf("compute is not healthy after %s", cfg.healthCheckTimeout)
+		case <-time.After(bserviceGroupPollInterval):
+		}
+	}
+}
+
+// bserviceComputeDiskResized reports whether the boot disk of the compute is at least of the size
+func bserviceComputeDiskResized(compute *BasicServiceComputeStatus, size int) bool {
+	for _, disk := range compute.Disks {
+		if disk.Type == "B" && disk.SizeMax < size {
+			return false
+		}
+	}
+	return true
+}
+
+// utilityBasicServiceComputeUpdate stops the compute, applies the group size and image to it
+// and starts it again. A compute that was not started is left stopped, the result tells
+// whether the compute is started. Computes that already have the size and image of the group,
+// e.g. updated by a previous failed update, are left as they are.
+func utilityBasicServiceComputeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}, computeId int) (bool, error) {
+	c := m.(*controller.ControllerCfg)
+
+	compute, err := utilityBasicServiceComputeGet(controller.WithoutCache(ctx), m, computeId)
+	if err != nil {
+		return false, err
+	}
+
+	cpu, ram, disk, imageId := d.Get("cpu").(int), d.Get("ram").(int), d.Get("disk").(int), d.Get("image_id").(int)
+	started := compute.TechStatus == "STARTED"
+	if compute.CPU == cpu && compute.RAM == ram && compute.ImageID == imageId && bserviceComputeDiskResized(compute, disk) {
+		logger.Debugf(ctx, "utilityBasicServiceComputeUpdate: compute ID %d is up to date", computeId)
+		return started, nil
+	}
+
+	if started {
+		urlValues := &url.Values{}
+		urlValues.Add("computeId", strconv.Itoa(computeId))
+		urlValues.Add("force", "false")
+		if _, err := c.DecortAPICall(ctx, "POST", computeStopAPI, urlValues); err != nil {
+			return false, err
+		}
+	}
+
+	if compute.CPU != cpu || compute.RAM != ram {
+		urlValues := &url.Values{}
+		urlValues.Add("computeId", strconv.Itoa(computeId))
+		urlValues.Add("cpu", strconv.Itoa(cpu))
+		urlValues.Add("ram", strconv.Itoa(ram))
+		urlValues.Add("force", "false")
+		if _, err := c.DecortAPICall(ctx, "POST", computeResizeAPI, urlValues); err != nil {
+			return false, err
+		}
+	}
+
+	if compute.ImageID != imageId {
+		urlValues := &url.Values{}
+		urlValues.Add("computeId", strconv.Itoa(computeId))
+		urlValues.Add("imageId", strconv.Itoa(imageId))
+		urlValues.Add("diskSize", strconv.Itoa(disk))
+		urlValues.Add("autoStart", "false")
+		if _, err := c.DecortAPICall(ctx, "POST", computeRedeployAPI, urlValues); err != nil {
+			return false, err
+		}
+	} else {
+		for _, computeDisk := range compute.Disks {
+			if computeDisk.Type != "B" || computeDisk.SizeMax >= disk {
+				continue
+			}
+			urlValues := &url.Values{}
+			urlValues.Add("diskId", strconv.Itoa(computeDisk.ID))
+			urlValues.Add("size", strconv.Itoa(disk))
+			if _, err := c.DecortAPICall(ctx, "POST", disksResizeAPI, urlValues); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if !started {
+		return false, nil
+	}
+
+	urlValues := &url.Values{}
+	urlValues.Add("computeId", strconv.Itoa(computeId))
+	if _, err := c.DecortAPICall(ctx, "POST", computeStartAPI, urlValues); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// utilityBasicServiceGroupRollingUpdate applies size and image changes of the group to its computes
+// max_unavailable computes at a time. The next batch starts when the computes of the current one
+// are healthy, and the update stops at the first failure. Progress of every compute is stored
+// in rolling_update_progress, so a failed update shows which computes have been updated.
+// The group definition is not changed here: it is updated by the caller once all computes are.
+func utilityBasicServiceGroupRollingUpdate(ctx context.Context, d *schema.ResourceData, m interface{}, cfg *rollingUpdateConfig) error {
+	bsg, err := utilityBasicServiceGroupCheckPresence(ctx, d, m)
+	if err != nil {
+		return err
+	}
+
+	progress := make([]map[string]interface{}, 0, len(bsg.Computes))
+	for _, compute := range bsg.Computes {
+		progress = append(progress, map[string]interface{}{
+			"compute_id": compute.ID,
+			"name":       compute.Name,
+			"status":     rollingUpdatePending,
+			"error":      "",
+		})
+	}
+	d.Set("rolling_update_progress", progress)
+
+	for start := 0; start < len(bsg.Computes); start += cfg.maxUnavailable {
+		end := start + cfg.maxUnavailable
+		if end > len(bsg.Computes) {
+			end = len(bsg.Computes)
+		}
+
+		if start > 0 && cfg.batchPause > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(cfg.batchPause):
+			}
+		}
+
+		batchErr := error(nil)
+		started := make(map[int]bool, end-start) // computes of the batch that have been updated
+		for i := start; i < end; i++ {
+			compute := bsg.Computes[i]
+			logger.Infof(ctx, "utilityBasicServiceGroupRollingUpdate: updating compute %s ID %d (%d of %d)", compute.Name, compute.ID, i+1, len(bsg.Computes))
+			computeStarted, err := utilityBasicServiceComputeUpdate(ctx, d, m, compute.ID)
+			if err != nil {
+				progress[i]["status"] = rollingUpdateFailed
+				progress[i]["error"] = err.Error()
+				batchErr = fmt.Errorf("rolling update of group %q failed on compute %s ID %d: %v", bsg.Name, compute.Name, compute.ID, err)
+				break
+			}
+			started[i] = computeStarted
+		}
+
+		// computes updated before a failure in the batch are checked as well, so that their
+		// progress is known
+		for i := start; i < end; i++ {
+			compute := bsg.Computes[i]
+			computeStarted, updated := started[i]
+			if !updated {
+				continue
+			}
+			if !computeStarted {
+				// stopped computes are updated but not checked
+				progress[i]["status"] = rollingUpdateUpdated
+				continue
+			}
+			if err := utilityBasicServiceComputeWaitHealthy(ctx, m, cfg, compute.ID, compute.IPAdresses); err != nil {
+				progress[i]["status"] = rollingUpdateFailed
+				progress[i]["error"] = err.Error()
+				if batchErr == nil {
+					batchErr = fmt.Errorf("rolling update of group %q failed on compute %s ID %d: %v", bsg.Name, compute.Name, compute.ID, err)
+				}
+				continue
+			}
+			progress[i]["status"] = rollingUpdateUpdated
+		}
+
+		d.Set("rolling_update_progress", progress)
+		if batchErr != nil {
+			return batchErr
+		}
+	}
+
+	return nil
+}
+
+// utilityBasicServiceGroupImageApplied reports whether all computes of the group run the image.
+// groupUpdate does not change the image of a group, so after a rolling update the group keeps
+// reporting the image it was created from.
+func utilityBasicServiceGroupImageApplied(ctx context.Context, m interface{}, bsg *BasicServiceGroup, imageId int) bool {
+	if len(bsg.Computes) == 0 {
+		return false
+	}
+	errNotApplied := fmt.Errorf("image %d is not applied", imageId)
+	err := parallel.Run(ctx, len(bsg.Computes), parallel.Limit, func(ctx context.Context, i int) error {
+		computeStatus, err := utilityBasicServiceComputeGet(ctx, m, bsg.Computes[i].ID)
+		if err != nil {
+			return err
+		}
+		if computeStatus.ImageID != imageId {
+			return errNotApplied
+		}
+		return nil
+	})
+	return err == nil
+}
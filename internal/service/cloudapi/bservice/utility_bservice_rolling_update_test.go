@@ -0,0 +1,317 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package bservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+type stubCompute struct {
+	cpu        int
+	ram        int
+	imageId    int
+	disk       int
+	techStatus string
+}
+
+// stubController serves the API calls of a rolling update for a group with computes
+// of IDs from 1 to the number of computes
+type stubController struct {
+	sync.Mutex
+	computes   map[int]*stubCompute
+	failResize map[int]bool // compute/resize fails for these computes
+	noStart    map[int]bool // these computes stay stopped after compute/start
+	stopped    map[int]bool // computes stopped by the update
+	stops      map[int]int  // compute/stop calls by compute
+	starts     map[int]int  // compute/start calls by compute
+	maxStopped int          // computes stopped at the same time
+}
+
+func (s *stubController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	computeId, _ := strconv.Atoi(r.Form.Get("computeId"))
+	compute := s.computes[computeId]
+
+	var resp interface{} = true
+	switch r.URL.Path {
+	case "/restmachine/cloudapi/accounts/list":
+		resp = []interface{}{}
+	case bserviceGroupGetAPI:
+		computes := make([]map[string]interface{}, 0, len(s.computes))
+		for id := 1; id <= len(s.computes); id++ {
+			computes = append(computes, map[string]interface{}{"id": id, "name": fmt.Sprintf("web-%d", id)})
+		}
+		resp = map[string]interface{}{"id": 10, "name": "web", "computes": computes}
+	case computeGetAPI:
+		resp = map[string]interface{}{
+			"id":         computeId,
+			"cpus":       compute.cpu,
+			"ram":        compute.ram,
+			"imageId":    compute.imageId,
+			"disks":      []map[string]interface{}{{"id": 100 + computeId, "sizeMax": compute.disk, "type": "B"}},
+			"techStatus": compute.techStatus,
+		}
+	case computeStopAPI:
+		compute.techStatus = "STOPPED"
+		s.stops[computeId]++
+		s.stopped[computeId] = true
+		if len(s.stopped) > s.maxStopped {
+			s.maxStopped = len(s.stopped)
+		}
+	case computeResizeAPI:
+		if s.failResize[computeId] {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "resize failed")
+			return
+		}
+		compute.cpu, _ = strconv.Atoi(r.Form.Get("cpu"))
+		compute.ram, _ = strconv.Atoi(r.Form.Get("ram"))
+	case computeRedeployAPI:
+		compute.imageId, _ = strconv.Atoi(r.Form.Get("imageId"))
+		compute.disk, _ = strconv.Atoi(r.Form.Get("diskSize"))
+	case disksResizeAPI:
+		diskId, _ := strconv.Atoi(r.Form.Get("diskId"))
+		s.computes[diskId-100].disk, _ = strconv.Atoi(r.Form.Get("size"))
+	case computeStartAPI:
+		s.starts[computeId]++
+		if !s.noStart[computeId] {
+			compute.techStatus = "STARTED"
+			delete(s.stopped, computeId)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+// testControllerConfigure configures the controller the way the provider does, against the stub
+func testControllerConfigure(t *testing.T, handler http.Handler) *controller.ControllerCfg {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	providerSchema := map[string]*schema.Schema{
+		"default_tags": {Type: schema.TypeMap, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+	}
+	for _, key := range []string{"authenticator", "controller_url", "jwt", "oauth2_url", "user", "password",
+		"app_id", "app_secret", "trace_file", "name_prefix", "name_pattern", "quota_check"} {
+		providerSchema[key] = &schema.Schema{Type: schema.TypeString, Optional: true}
+	}
+	providerSchema["cache_ttl"] = &schema.Schema{Type: schema.TypeInt, Optional: true}
+	providerSchema["allow_unverified_ssl"] = &schema.Schema{Type: schema.TypeBool, Optional: true}
+
+	d := schema.TestResourceDataRaw(t, providerSchema, map[string]interface{}{
+		"authenticator":  "jwt",
+		"controller_url": srv.URL,
+		"jwt":            "jwt",
+		"oauth2_url":     srv.URL,
+	})
+	c, err := controller.ControllerConfigure(context.Background(), d)
+	if err != nil {
+		t.Fatalf("ControllerConfigure() error = %v", err)
+	}
+	return c
+}
+
+func TestBasicServiceGroupRollingUpdate(t *testing.T) {
+	// the group is resized to 2 CPU, 2048 MB RAM and 20 GB boot disk
+	current := func() stubCompute {
+		return stubCompute{cpu: 1, ram: 1024, imageId: 5, disk: 10, techStatus: "STARTED"}
+	}
+	updated := func() stubCompute {
+		return stubCompute{cpu: 2, ram: 2048, imageId: 5, disk: 20, techStatus: "STARTED"}
+	}
+
+	tests := []struct {
+		name           string
+		computes       []stubCompute
+		maxUnavailable int
+		failResize     []int
+		noStart        []int
+		wantStatuses   []string
+		wantErr        bool
+		wantNotStopped []int // computes left running
+	}{
+		{
+			name:           "all computes",
+			computes:       []stubCompute{current(), current(), current(), current(), current()},
+			maxUnavailable: 2,
+			wantStatuses:   []string{"UPDATED", "UPDATED", "UPDATED", "UPDATED", "UPDATED"},
+		},
+		{
+			name:           "one by one",
+			computes:       []stubCompute{current(), current(), current()},
+			maxUnavailable: 1,
+			wantStatuses:   []string{"UPDATED", "UPDATED", "UPDATED"},
+		},
+		{
+			name:           "up to date computes are not restarted",
+			computes:       []stubCompute{updated(), current(), updated()},
+			maxUnavailable: 2,
+			wantStatuses:   []string{"UPDATED", "UPDATED", "UPDATED"},
+			wantNotStopped: []int{1, 3},
+		},
+		{
+			name:           "stopped computes stay stopped",
+			computes:       []stubCompute{current(), {cpu: 1, ram: 1024, imageId: 5, disk: 10, techStatus: "STOPPED"}},
+			maxUnavailable: 2,
+			wantStatuses:   []string{"UPDATED", "UPDATED"},
+		},
+		{
+			name:           "update fails in the middle of a batch",
+			computes:       []stubCompute{current(), current(), current(), current()},
+			maxUnavailable: 2,
+			failResize:     []int{2},
+			wantStatuses:   []string{"UPDATED", "FAILED", "PENDING", "PENDING"},
+			wantErr:        true,
+			wantNotStopped: []int{3, 4},
+		},
+		{
+			name:           "compute is not healthy",
+			computes:       []stubCompute{current(), current(), current()},
+			maxUnavailable: 1,
+			noStart:        []int{1},
+			wantStatuses:   []string{"FAILED", "PENDING", "PENDING"},
+			wantErr:        true,
+			wantNotStopped: []int{2, 3},
+		},
+		{
+			name:           "unhealthy compute before a failed one",
+			computes:       []stubCompute{current(), current(), current(), current()},
+			maxUnavailable: 3,
+			noStart:        []int{1},
+			failResize:     []int{2},
+			wantStatuses:   []string{"FAILED", "FAILED", "PENDING", "PENDING"},
+			wantErr:        true,
+			wantNotStopped: []int{3, 4},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &stubController{
+				computes:   map[int]*stubCompute{},
+				failResize: map[int]bool{},
+				noStart:    map[int]bool{},
+				stopped:    map[int]bool{},
+				stops:      map[int]int{},
+				starts:     map[int]int{},
+			}
+			initialStatus := map[int]string{}
+			for i := range tc.computes {
+				compute := tc.computes[i]
+				stub.computes[i+1] = &compute
+				initialStatus[i+1] = compute.techStatus
+			}
+			for _, id := range tc.failResize {
+				stub.failResize[id] = true
+			}
+			for _, id := range tc.noStart {
+				stub.noStart[id] = true
+			}
+			c := testControllerConfigure(t, stub)
+
+			d := schema.TestResourceDataRaw(t, resourceBasicServiceGroupSchemaMake(), map[string]interface{}{
+				"service_id":     1,
+				"compgroup_id":   10,
+				"compgroup_name": "web",
+				"cpu":            2,
+				"ram":            2048,
+				"disk":           20,
+				"image_id":       5,
+			})
+			cfg := &rollingUpdateConfig{maxUnavailable: tc.maxUnavailable, healthCheck: "tech_status"}
+
+			err := utilityBasicServiceGroupRollingUpdate(context.Background(), d, c, cfg)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("utilityBasicServiceGroupRollingUpdate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			progress := d.Get("rolling_update_progress").([]interface{})
+			if len(progress) != len(tc.wantStatuses) {
+				t.Fatalf("rolling_update_progress has %d computes, want %d", len(progress), len(tc.wantStatuses))
+			}
+			for i, want := range tc.wantStatuses {
+				record := progress[i].(map[string]interface{})
+				if record["compute_id"].(int) != i+1 || record["status"].(string) != want {
+					t.Errorf("progress of compute %d = %v, want %s", i+1, record, want)
+				}
+				if (want == "FAILED") != (record["error"].(string) != "") {
+					t.Errorf("progress of compute %d = %v, error is expected for failed computes only", i+1, record)
+				}
+				if want != "UPDATED" {
+					continue
+				}
+				compute := *stub.computes[i+1]
+				wantCompute := updated()
+				wantCompute.techStatus = initialStatus[i+1]
+				if compute != wantCompute {
+					t.Errorf("compute %d = %+v, want %+v", i+1, compute, wantCompute)
+				}
+			}
+
+			if stub.maxStopped > tc.maxUnavailable {
+				t.Errorf("%d computes were stopped at the same time, max_unavailable %d", stub.maxStopped, tc.maxUnavailable)
+			}
+			for _, id := range tc.wantNotStopped {
+				if stub.stops[id] != 0 {
+					t.Errorf("compute %d was stopped", id)
+				}
+			}
+			for id, status := range initialStatus {
+				if status != "STARTED" && stub.starts[id] != 0 {
+					t.Errorf("compute %d was not started before the update, but started by it", id)
+				}
+			}
+		})
+	}
+}
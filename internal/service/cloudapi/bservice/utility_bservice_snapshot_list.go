@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -52,7 +51,7 @@ func utilityBasicServiceSnapshotListCheckPresence(ctx context.Context, d *schema
 		urlValues.Add("serviceId", strconv.Itoa(serviceId.(int)))
 	}
 
-	log.Debugf("utilityBasicServiceSnapshotListCheckPresence")
+	logger.Debugf(ctx, "utilityBasicServiceSnapshotListCheckPresence")
 	basicServiceSnapshotListRaw, err := c.DecortAPICall(ctx, "POST", bserviceSnapshotListAPI, urlValues)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,649 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package bservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/parallel"
+)
+
+// Health of a Basic Service or of its group, aggregated from techStatus of member computes
+const (
+	bserviceHealthHealthy  = "HEALTHY"
+	bserviceHealthDegraded = "DEGRADED"
+	bserviceHealthDown     = "DOWN"
+	bserviceHealthUnknown  = "UNKNOWN"
+)
+
+// bserviceGroupPollInterval is how often group health is checked while waiting for a group to start
+const bserviceGroupPollInterval = 5 * time.Second
+
+func bserviceGroupDeps(group map[string]interface{}) []string {
+	deps := make([]string, 0)
+	if depsSet, ok := group["depends_on_groups"].(*schema.Set); ok {
+		for _, dep := range depsSet.List() {
+			deps = append(deps, dep.(string))
+		}
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// bserviceGroupsOrder returns indexes of the declared groups in start order: every group goes
+// after the groups it depends on, otherwise in the order of declaration
+func bserviceGroupsOrder(groups []interface{}) ([]int, error) {
+	index := make(map[string]int, len(groups))
+	for i, groupRaw := range groups {
+		name := groupRaw.(map[string]interface{})["name"].(string)
+		if _, ok := index[name]; ok {
+			return nil, fmt.Errorf("group name %q is declared more than once", name)
+		}
+		index[name] = i
+	}
+
+	deps := make([][]string, len(groups))
+	for i, groupRaw := range groups {
+		group := groupRaw.(map[string]interface{})
+		deps[i] = bserviceGroupDeps(group)
+		for _, dep := range deps[i] {
+			if _, ok := index[dep]; !ok {
+				return nil, fmt.Errorf("group %q depends on group %q which is not declared", group["name"].(string), dep)
+			}
+		}
+	}
+
+	order := make([]int, 0, len(groups))
+	done := make([]bool, len(groups))
+	for len(order) < len(groups) {
+		progress := false
+		for i := range groups {
+			if done[i] {
+				continue
+			}
+			ready := true
+			for _, dep := range deps[i] {
+				if !done[index[dep]] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				done[i] = true
+				order = append(order, i)
+				progress = true
+			}
+		}
+
+		if !progress {
+			cycle := make([]string, 0)
+			for i, groupRaw := range groups {
+				if !done[i] {
+					cycle = append(cycle, groupRaw.(map[string]interface{})["name"].(string))
+				}
+			}
+			return nil, fmt.Errorf("dependency cycle between groups %s", strings.Join(cycle, ", "))
+		}
+	}
+
+	return order, nil
+}
+
+func bserviceIntList(ids []interface{}) string {
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strs = append(strs, strconv.Itoa(id.(int)))
+	}
+	return "[" + strings.Join(strs, ",") + "]"
+}
+
+func utilityBasicServiceGroupGet(ctx context.Context, m interface{}, serviceId, compgroupId int) (*BasicServiceGroup, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("serviceId", strconv.Itoa(serviceId))
+	urlValues.Add("compgroupId", strconv.Itoa(compgroupId))
+
+	logger.Debugf(ctx, "utilityBasicServiceGroupGet: load group ID %d of service ID %d", compgroupId, serviceId)
+	bserviceGroupRaw, err := c.DecortAPICall(ctx, "POST", bserviceGroupGetAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	bserviceGroup := &BasicServiceGroup{}
+	err = json.Unmarshal([]byte(bserviceGroupRaw), bserviceGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	return bserviceGroup, nil
+}
+
+func utilityBasicServiceComputeGet(ctx context.Context, m interface{}, computeId int) (*BasicServiceComputeStatus, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("computeId", strconv.Itoa(computeId))
+
+	computeRaw, err := c.DecortAPICall(ctx, "POST", computeGetAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	compute := &BasicServiceComputeStatus{}
+	err = json.Unmarshal([]byte(computeRaw), compute)
+	if err != nil {
+		return nil, err
+	}
+
+	return compute, nil
+}
+
+// utilityBasicServiceComputeStatuses loads techStatus of the computes, one compute/get call
+// per compute, as neither the service nor its groups report status of their computes
+func utilityBasicServiceComputeStatuses(ctx context.Context, m interface{}, computeIds []int) (map[int]string, error) {
+	statuses := make([]string, len(computeIds))
+	err := parallel.Run(ctx, len(computeIds), parallel.Limit, func(ctx context.Context, i int) error {
+		compute, err := utilityBasicServiceComputeGet(ctx, m, computeIds[i])
+		if err != nil {
+			return err
+		}
+		statuses[i] = compute.TechStatus
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[int]string, len(computeIds))
+	for i, computeId := range computeIds {
+		res[computeId] = statuses[i]
+	}
+	return res, nil
+}
+
+// bserviceHealth aggregates techStatus of the computes: HEALTHY if all of them are started,
+// DOWN if none, DEGRADED otherwise and UNKNOWN if there are no computes
+func bserviceHealth(statuses map[int]string, computeIds []int) string {
+	if len(computeIds) == 0 {
+		return bserviceHealthUnknown
+	}
+
+	started := 0
+	for _, computeId := range computeIds {
+		if statuses[computeId] == "STARTED" {
+			started++
+		}
+	}
+
+	switch started {
+	case len(computeIds):
+		return bserviceHealthHealthy
+	case 0:
+		return bserviceHealthDown
+	default:
+		return bserviceHealthDegraded
+	}
+}
+
+// utilityBasicServiceHealth loads techStatus of the computes and aggregates it by bserviceHealth
+func utilityBasicServiceHealth(ctx context.Context, m interface{}, computeIds []int) (string, error) {
+	statuses, err := utilityBasicServiceComputeStatuses(ctx, m, computeIds)
+	if err != nil {
+		return "", err
+	}
+	return bserviceHealth(statuses, computeIds), nil
+}
+
+func bserviceGroupComputeIds(bsg *BasicServiceGroup) []int {
+	ids := make([]int, 0, len(bsg.Computes))
+	for _, compute := range bsg.Computes {
+		ids = append(ids, compute.ID)
+	}
+	return ids
+}
+
+func utilityBasicServiceGroupAdd(ctx context.Context, m interface{}, serviceId int, group map[string]interface{}) (int, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("serviceId", strconv.Itoa(serviceId))
+	urlValues.Add("name", group["name"].(string))
+	urlValues.Add("count", strconv.Itoa(group["comp_count"].(int)))
+	urlValues.Add("cpu", strconv.Itoa(group["cpu"].(int)))
+	urlValues.Add("ram", strconv.Itoa(group["ram"].(int)))
+	urlValues.Add("disk", strconv.Itoa(group["disk"].(int)))
+	urlValues.Add("imageId", strconv.Itoa(group["image_id"].(int)))
+	urlValues.Add("driver", strings.ToUpper(group["driver"].(string)))
+	if role := group["role"].(string); role != "" {
+		urlValues.Add("role", role)
+	}
+	if timeoutStart := group["timeout_start"].(int); timeoutStart != 0 {
+		urlValues.Add("timeoutStart", strconv.Itoa(timeoutStart))
+	}
+	if vinses := group["vinses"].([]interface{}); len(vinses) != 0 {
+		urlValues.Add("vinses", bserviceIntList(vinses))
+	}
+	if extnets := group["extnets"].([]interface{}); len(extnets) != 0 {
+		urlValues.Add("extnets", bserviceIntList(extnets))
+	}
+
+	logger.Debugf(ctx, "utilityBasicServiceGroupAdd: add group %q to service ID %d", group["name"].(string), serviceId)
+	compgroupId, err := c.DecortAPICall(ctx, "POST", bserviceGroupAddAPI, urlValues)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(compgroupId)
+}
+
+func utilityBasicServiceGroupRemove(ctx context.Context, m interface{}, serviceId, compgroupId int) error {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("serviceId", strconv.Itoa(serviceId))
+	urlValues.Add("compgroupId", strconv.Itoa(compgroupId))
+
+	logger.Debugf(ctx, "utilityBasicServiceGroupRemove: remove group ID %d of service ID %d", compgroupId, serviceId)
+	_, err := c.DecortAPICall(ctx, "POST", bserviceGroupRemoveAPI, urlValues)
+	return err
+}
+
+// utilityBasicServiceGroupUpdate applies changes of a declared group that do not require
+// to recreate it: computes count, name, resources, role and networks
+func utilityBasicServiceGroupUpdate(ctx context.Context, m interface{}, serviceId, compgroupId int, oldGroup, newGroup map[string]interface{}) error {
+	c := m.(*controller.ControllerCfg)
+	groupValues := func() *url.Values {
+		urlValues := &url.Values{}
+		urlValues.Add("serviceId", strconv.Itoa(serviceId))
+		urlValues.Add("compgroupId", strconv.Itoa(compgroupId))
+		return urlValues
+	}
+
+	if oldGroup["comp_count"].(int) != newGroup["comp_count"].(int) {
+		urlValues := groupValues()
+		urlValues.Add("count", strconv.Itoa(newGroup["comp_count"].(int)))
+		urlValues.Add("mode", "ABSOLUTE")
+		if _, err := c.DecortAPICall(ctx, "POST", bserviceGroupResizeAPI, urlValues); err != nil {
+			return err
+		}
+	}
+
+	changed := false
+	for _, key := range []string{"name", "cpu", "ram", "disk", "role"} {
+		if oldGroup[key] != newGroup[key] {
+			changed = true
+		}
+	}
+	if changed {
+		urlValues := groupValues()
+		urlValues.Add("name", newGroup["name"].(string))
+		urlValues.Add("cpu", strconv.Itoa(newGroup["cpu"].(int)))
+		urlValues.Add("ram", strconv.Itoa(newGroup["ram"].(int)))
+		urlValues.Add("disk", strconv.Itoa(newGroup["disk"].(int)))
+		urlValues.Add("role", newGroup["role"].(string))
+		urlValues.Add("force", "false")
+		if _, err := c.DecortAPICall(ctx, "POST", bserviceGroupUpdateAPI, urlValues); err != nil {
+			return err
+		}
+	}
+
+	if bserviceIntList(oldGroup["extnets"].([]interface{})) != bserviceIntList(newGroup["extnets"].([]interface{})) {
+		urlValues := groupValues()
+		urlValues.Add("extnets", bserviceIntList(newGroup["extnets"].([]interface{})))
+		if _, err := c.DecortAPICall(ctx, "POST", bserviceGroupUpdateExtnetAPI, urlValues); err != nil {
+			return err
+		}
+	}
+
+	if bserviceIntList(oldGroup["vinses"].([]interface{})) != bserviceIntList(newGroup["vinses"].([]interface{})) {
+		urlValues := groupValues()
+		urlValues.Add("vinses", bserviceIntList(newGroup["vinses"].([]interface{})))
+		if _, err := c.DecortAPICall(ctx, "POST", bserviceGroupUpdateVinsAPI, urlValues); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// utilityBasicServiceGroupsParents makes parents of every declared group match its depends_on_groups.
+// Parents that are not declared groups, e.g. groups managed by decort_bservice_group, are kept.
+func utilityBasicServiceGroupsParents(ctx context.Context, m interface{}, serviceId int, groups []interface{}) error {
+	c := m.(*controller.ControllerCfg)
+
+	ids := make(map[string]int, len(groups))
+	declared := make(map[int]bool, len(groups))
+	for _, groupRaw := range groups {
+		group := groupRaw.(map[string]interface{})
+		ids[group["name"].(string)] = group["compgroup_id"].(int)
+		declared[group["compgroup_id"].(int)] = true
+	}
+
+	for _, groupRaw := range groups {
+		group := groupRaw.(map[string]interface{})
+		compgroupId := group["compgroup_id"].(int)
+		bsg, err := utilityBasicServiceGroupGet(ctx, m, serviceId, compgroupId)
+		if err != nil {
+			return err
+		}
+
+		wanted := make(map[int]bool)
+		for _, dep := range bserviceGroupDeps(group) {
+			wanted[ids[dep]] = true
+		}
+		current := make(map[int]bool)
+		for _, parentId := range bsg.Parents {
+			current[parentId] = true
+		}
+
+		for _, parentId := range bsg.Parents {
+			if declared[parentId] && !wanted[parentId] {
+				urlValues := &url.Values{}
+				urlValues.Add("serviceId", strconv.Itoa(serviceId))
+				urlValues.Add("compgroupId", strconv.Itoa(compgroupId))
+				urlValues.Add("parentId", strconv.Itoa(parentId))
+				if _, err := c.DecortAPICall(ctx, "POST", bserviceGroupParentRemoveAPI, urlValues); err != nil {
+					return err
+				}
+			}
+		}
+		for _, dep := range bserviceGroupDeps(group) {
+			if !current[ids[dep]] {
+				urlValues := &url.Values{}
+				urlValues.Add("serviceId", strconv.Itoa(serviceId))
+				urlValues.Add("compgroupId", strconv.Itoa(compgroupId))
+				urlValues.Add("parentId", strconv.Itoa(ids[dep]))
+				if _, err := c.DecortAPICall(ctx, "POST", bserviceGroupParentAddAPI, urlValues); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// utilityBasicServiceGroupsConfigure brings the groups of the service to the declared ones: groups
+// no longer declared are removed, new ones are added in start order, changed ones updated.
+// A group whose image or driver changes is recreated. Returns the declared groups with their IDs.
+func utilityBasicServiceGroupsConfigure(ctx context.Context, m interface{}, serviceId int, oldGroups, newGroups []interface{}) ([]interface{}, error) {
+	order, err := bserviceGroupsOrder(newGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	oldByName := make(map[string]map[string]interface{}, len(oldGroups))
+	for _, groupRaw := range oldGroups {
+		group := groupRaw.(map[string]interface{})
+		if group["compgroup_id"].(int) != 0 {
+			oldByName[group["name"].(string)] = group
+		}
+	}
+
+	kept := make(map[string]bool, len(newGroups))
+	for _, groupRaw := range newGroups {
+		group := groupRaw.(map[string]interface{})
+		oldGroup, ok := oldByName[group["name"].(string)]
+		if ok && oldGroup["image_id"] == group["image_id"] && strings.EqualFold(oldGroup["driver"].(string), group["driver"].(string)) {
+			kept[group["name"].(string)] = true
+		}
+	}
+
+	// dependents go first, so remove in reverse start order of the old declaration
+	oldOrder, err := bserviceGroupsOrder(oldGroups)
+	if err != nil {
+		oldOrder = make([]int, 0, len(oldGroups))
+		for i := range oldGroups {
+			oldOrder = append(oldOrder, i)
+		}
+	}
+	for i := len(oldOrder) - 1; i >= 0; i-- {
+		group := oldGroups[oldOrder[i]].(map[string]interface{})
+		if group["compgroup_id"].(int) == 0 || kept[group["name"].(string)] {
+			continue
+		}
+		if err := utilityBasicServiceGroupRemove(ctx, m, serviceId, group["compgroup_id"].(int)); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make([]interface{}, len(newGroups))
+	for _, i := range order {
+		group := make(map[string]interface{})
+		for key, value := range newGroups[i].(map[string]interface{}) {
+			group[key] = value
+		}
+
+		name := group["name"].(string)
+		if kept[name] {
+			group["compgroup_id"] = oldByName[name]["compgroup_id"]
+			if err := utilityBasicServiceGroupUpdate(ctx, m, serviceId, group["compgroup_id"].(int), oldByName[name], group); err != nil {
+				return nil, err
+			}
+		} else {
+			compgroupId, err := utilityBasicServiceGroupAdd(ctx, m, serviceId, group)
+			if err != nil {
+				return nil, err
+			}
+			group["compgroup_id"] = compgroupId
+		}
+		res[i] = group
+	}
+
+	if err := utilityBasicServiceGroupsParents(ctx, m, serviceId, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func utilityBasicServiceGroupWaitHealthy(ctx context.Context, m interface{}, serviceId int, group map[string]interface{}) error {
+	name := group["name"].(string)
+	compgroupId := group["compgroup_id"].(int)
+
+	var deadline <-chan time.Time
+	if timeoutStart := group["timeout_start"].(int); timeoutStart > 0 {
+		timer := time.NewTimer(time.Duration(timeoutStart) * time.Second)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	ctx = controller.WithoutCache(ctx)
+	for {
+		bsg, err := utilityBasicServiceGroupGet(ctx, m, serviceId, compgroupId)
+		if err != nil {
+			return err
+		}
+		health, err := utilityBasicServiceHealth(ctx, m, bserviceGroupComputeIds(bsg))
+		if err != nil {
+			return err
+		}
+		if health == bserviceHealthHealthy {
+			return nil
+		}
+		logger.Debugf(ctx, "utilityBasicServiceGroupWaitHealthy: group %q of service ID %d is %s", name, serviceId, health)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("group %q did not become healthy: %v", name, ctx.Err())
+		case <-deadline:
+			return fmt.Errorf("group %q did not become healthy in %d seconds", name, group["timeout_start"].(int))
+		case <-time.After(bserviceGroupPollInterval):
+		}
+	}
+}
+
+// utilityBasicServiceGroupsStart starts the declared groups in topological order. Every group
+// is started after the groups it depends on are healthy; healthy groups are left as is.
+func utilityBasicServiceGroupsStart(ctx context.Context, m interface{}, serviceId int, groups []interface{}) error {
+	c := m.(*controller.ControllerCfg)
+
+	order, err := bserviceGroupsOrder(groups)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range order {
+		group := groups[i].(map[string]interface{})
+		bsg, err := utilityBasicServiceGroupGet(ctx, m, serviceId, group["compgroup_id"].(int))
+		if err != nil {
+			return err
+		}
+		health, err := utilityBasicServiceHealth(ctx, m, bserviceGroupComputeIds(bsg))
+		if err != nil {
+			return err
+		}
+		if health == bserviceHealthHealthy {
+			continue
+		}
+
+		logger.Debugf(ctx, "utilityBasicServiceGroupsStart: start group %q of service ID %d", group["name"].(string), serviceId)
+		urlValues := &url.Values{}
+		urlValues.Add("serviceId", strconv.Itoa(serviceId))
+		urlValues.Add("compgroupId", strconv.Itoa(group["compgroup_id"].(int)))
+		if _, err := c.DecortAPICall(ctx, "POST", bserviceGroupStartAPI, urlValues); err != nil {
+			return err
+		}
+
+		if err := utilityBasicServiceGroupWaitHealthy(ctx, m, serviceId, group); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// utilityBasicServiceGroupsStop stops the declared groups in reverse topological order,
+// so that groups are stopped before the groups they depend on
+func utilityBasicServiceGroupsStop(ctx context.Context, m interface{}, serviceId int, groups []interface{}) error {
+	c := m.(*controller.ControllerCfg)
+
+	order, err := bserviceGroupsOrder(groups)
+	if err != nil {
+		return err
+	}
+
+	for i := len(order) - 1; i >= 0; i-- {
+		group := groups[order[i]].(map[string]interface{})
+
+		logger.Debugf(ctx, "utilityBasicServiceGroupsStop: stop group %q of service ID %d", group["name"].(string), serviceId)
+		urlValues := &url.Values{}
+		urlValues.Add("serviceId", strconv.Itoa(serviceId))
+		urlValues.Add("compgroupId", strconv.Itoa(group["compgroup_id"].(int)))
+		urlValues.Add("force", "false")
+		if _, err := c.DecortAPICall(ctx, "POST", bserviceGroupStopAPI, urlValues); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// utilityBasicServiceGroupsRead refreshes the declared groups. Groups removed outside of
+// Terraform are dropped, so that they are added back on the next apply.
+func utilityBasicServiceGroupsRead(ctx context.Context, m interface{}, bs *BasicServiceExtend, groups []interface{}, statuses map[int]string) ([]interface{}, error) {
+	existing := make(map[int]bool, len(bs.Groups))
+	for _, compgroupId := range bs.Groups {
+		existing[compgroupId] = true
+	}
+
+	names := make(map[int]string, len(groups))
+	for _, groupRaw := range groups {
+		group := groupRaw.(map[string]interface{})
+		names[group["compgroup_id"].(int)] = group["name"].(string)
+	}
+
+	compgroupIds := make([]int, 0, len(groups))
+	for _, groupRaw := range groups {
+		group := groupRaw.(map[string]interface{})
+		compgroupId := group["compgroup_id"].(int)
+		if !existing[compgroupId] {
+			logger.Debugf(ctx, "utilityBasicServiceGroupsRead: group %q ID %d of service ID %d not found", group["name"].(string), compgroupId, bs.ID)
+			continue
+		}
+		compgroupIds = append(compgroupIds, compgroupId)
+	}
+
+	bsgs := make([]*BasicServiceGroup, len(compgroupIds))
+	err := parallel.Run(ctx, len(compgroupIds), parallel.Limit, func(ctx context.Context, i int) error {
+		bsg, err := utilityBasicServiceGroupGet(ctx, m, bs.ID, compgroupIds[i])
+		if err != nil {
+			return err
+		}
+		bsgs[i] = bsg
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]interface{}, 0, len(bsgs))
+	for _, bsg := range bsgs {
+		computeIds := bserviceGroupComputeIds(bsg)
+		health := bserviceHealth(statuses, computeIds)
+
+		deps := make([]interface{}, 0, len(bsg.Parents))
+		for _, parentId := range bsg.Parents {
+			if name, ok := names[parentId]; ok {
+				deps = append(deps, name)
+			}
+		}
+
+		res = append(res, map[string]interface{}{
+			"name":              bsg.Name,
+			"comp_count":        len(bsg.Computes),
+			"cpu":               bsg.CPU,
+			"ram":               bsg.RAM,
+			"disk":              bsg.Disk,
+			"image_id":          bsg.ImageId,
+			"driver":            bsg.Driver,
+			"role":              bsg.Role,
+			"timeout_start":     bsg.TimeoutStart,
+			"vinses":            bsg.Vinses,
+			"extnets":           bsg.Extnets,
+			"depends_on_groups": deps,
+			"compgroup_id":      bsg.ID,
+			"tech_status":       bsg.TechStatus,
+			"health":            health,
+			"computes":          computeIds,
+		})
+	}
+
+	return res, nil
+}
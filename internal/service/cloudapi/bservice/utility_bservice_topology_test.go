@@ -0,0 +1,151 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package bservice
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testBserviceGroup(name string, deps ...string) interface{} {
+	depsRaw := make([]interface{}, 0, len(deps))
+	for _, dep := range deps {
+		depsRaw = append(depsRaw, dep)
+	}
+	return map[string]interface{}{
+		"name":              name,
+		"depends_on_groups": schema.NewSet(schema.HashString, depsRaw),
+	}
+}
+
+func TestBserviceGroupsOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  []interface{}
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "no groups",
+			want: []int{},
+		},
+		{
+			name:   "independent groups keep declaration order",
+			groups: []interface{}{testBserviceGroup("web"), testBserviceGroup("db"), testBserviceGroup("cache")},
+			want:   []int{0, 1, 2},
+		},
+		{
+			name: "dependencies go first",
+			groups: []interface{}{
+				testBserviceGroup("web", "app"),
+				testBserviceGroup("app", "db", "cache"),
+				testBserviceGroup("db"),
+				testBserviceGroup("cache"),
+			},
+			want: []int{2, 3, 1, 0},
+		},
+		{
+			name: "diamond",
+			groups: []interface{}{
+				testBserviceGroup("lb", "web1", "web2"),
+				testBserviceGroup("web1", "db"),
+				testBserviceGroup("web2", "db"),
+				testBserviceGroup("db"),
+			},
+			want: []int{3, 1, 2, 0},
+		},
+		{
+			name:    "self dependency",
+			groups:  []interface{}{testBserviceGroup("web", "web")},
+			wantErr: true,
+		},
+		{
+			name: "cycle",
+			groups: []interface{}{
+				testBserviceGroup("db"),
+				testBserviceGroup("web", "app"),
+				testBserviceGroup("app", "queue"),
+				testBserviceGroup("queue", "web"),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "undeclared dependency",
+			groups:  []interface{}{testBserviceGroup("web", "db")},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate name",
+			groups:  []interface{}{testBserviceGroup("web"), testBserviceGroup("web")},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := bserviceGroupsOrder(tc.groups)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("bserviceGroupsOrder() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("bserviceGroupsOrder() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBserviceHealth(t *testing.T) {
+	statuses := map[int]string{1: "STARTED", 2: "STARTED", 3: "STOPPED", 4: "DOWN"}
+
+	tests := []struct {
+		name       string
+		computeIds []int
+		want       string
+	}{
+		{name: "no computes", want: bserviceHealthUnknown},
+		{name: "all started", computeIds: []int{1, 2}, want: bserviceHealthHealthy},
+		{name: "some started", computeIds: []int{1, 3}, want: bserviceHealthDegraded},
+		{name: "none started", computeIds: []int{3, 4}, want: bserviceHealthDown},
+		{name: "status not loaded", computeIds: []int{1, 5}, want: bserviceHealthDegraded},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := bserviceHealth(statuses, tc.computeIds); got != tc.want {
+				t.Errorf("bserviceHealth() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
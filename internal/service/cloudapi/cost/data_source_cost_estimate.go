@@ -0,0 +1,206 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package cost
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/rudecs/terraform-provider-decort/internal/constants"
+)
+
+func dataSourceCostEstimateRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	prices := utilityCostEstimatePrices(d)
+	usage, err := utilityCostEstimateCheckPresence(ctx, d, m, prices)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	items, total := prices.Estimate(usage)
+
+	itemList := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		itemList = append(itemList, map[string]interface{}{
+			"cu_type":    item.CUType,
+			"sep_id":     item.SepID,
+			"pool":       item.Pool,
+			"quantity":   item.Quantity,
+			"unit_price": item.UnitPrice,
+			"cost":       item.Cost,
+		})
+	}
+
+	id := uuid.New()
+	d.SetId(id.String())
+	d.Set("items", itemList)
+	d.Set("total", total)
+	d.Set("currency", prices.Currency)
+
+	return nil
+}
+
+func dataSourceCostEstimateSchemaMake() map[string]*schema.Schema {
+	res := map[string]*schema.Schema{
+		"account_id": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ExactlyOneOf: []string{"account_id", "rg_id"},
+			Description:  "ID of the account to estimate the cost of",
+		},
+		"rg_id": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ExactlyOneOf: []string{"account_id", "rg_id"},
+			Description:  "ID of the resource group to estimate the cost of",
+		},
+		"usage": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "reserved",
+			ValidateFunc: validation.StringInSlice([]string{"reserved", "current"}, false),
+			Description:  "Usage to estimate: reserved (allocated to computes and disks) or current (consumed units reported by the controller)",
+		},
+		"prices": {
+			Type:        schema.TypeList,
+			Required:    true,
+			MaxItems:    1,
+			Description: "Price table of consumed units. Prices are per unit for the period of your choice, e.g. a month",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"currency": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "Currency of the prices, copied to the result",
+					},
+					"units": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem: &schema.Schema{
+							Type: schema.TypeFloat,
+						},
+						Description: "Price of a unit by CU type: CU_C per vCPU, CU_M per MB of RAM, CU_D per GB of disk in pools not listed in pool blocks, CU_I per external IP address, other CU types reported by the controller for current usage of accounts",
+					},
+					"pool": {
+						Type:        schema.TypeList,
+						Optional:    true,
+						Description: "Price of a GB of disk (CU_D) in the pool of the SEP",
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"sep_id": {
+									Type:        schema.TypeInt,
+									Required:    true,
+									Description: "ID of the SEP",
+								},
+								"pool": {
+									Type:        schema.TypeString,
+									Optional:    true,
+									Description: "Name of the pool, all pools of the SEP if empty",
+								},
+								"price": {
+									Type:        schema.TypeFloat,
+									Required:    true,
+									Description: "Price of a GB of disk",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"items": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Cost by CU type, CU_D by SEP pool",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"cu_type": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "CU type, e.g. CU_C, CU_M, CU_D or CU_I",
+					},
+					"sep_id": {
+						Type:        schema.TypeInt,
+						Computed:    true,
+						Description: "ID of the SEP for CU_D, 0 for disk space which pool is not known",
+					},
+					"pool": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "Name of the pool for CU_D",
+					},
+					"quantity": {
+						Type:        schema.TypeFloat,
+						Computed:    true,
+						Description: "Amount of consumed units: vCPUs, MB of RAM, GB of disk, IP addresses",
+					},
+					"unit_price": {
+						Type:     schema.TypeFloat,
+						Computed: true,
+					},
+					"cost": {
+						Type:     schema.TypeFloat,
+						Computed: true,
+					},
+				},
+			},
+		},
+		"total": {
+			Type:        schema.TypeFloat,
+			Computed:    true,
+			Description: "Total cost",
+		},
+		"currency": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+	return res
+}
+
+func DataSourceCostEstimate() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+
+		ReadContext: dataSourceCostEstimateRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read:    &constants.Timeout30s,
+			Default: &constants.Timeout60s,
+		},
+
+		Schema: dataSourceCostEstimateSchemaMake(),
+	}
+}
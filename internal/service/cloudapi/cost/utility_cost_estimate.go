@@ -0,0 +1,259 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/cost"
+	"github.com/rudecs/terraform-provider-decort/internal/parallel"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/account"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/rg"
+)
+
+func utilityCostEstimatePrices(d *schema.ResourceData) cost.Prices {
+	prices := cost.Prices{Units: map[string]float64{}}
+	pricesList := d.Get("prices").([]interface{})
+	if len(pricesList) == 0 || pricesList[0] == nil {
+		return prices
+	}
+
+	pricesMap := pricesList[0].(map[string]interface{})
+	prices.Currency = pricesMap["currency"].(string)
+	for cuType, price := range pricesMap["units"].(map[string]interface{}) {
+		prices.Units[strings.ToUpper(cuType)] = price.(float64)
+	}
+	for _, poolItem := range pricesMap["pool"].([]interface{}) {
+		poolMap := poolItem.(map[string]interface{})
+		prices.Pools = append(prices.Pools, cost.PoolPrice{
+			SepID: poolMap["sep_id"].(int),
+			Pool:  poolMap["pool"].(string),
+			Price: poolMap["price"].(float64),
+		})
+	}
+	return prices
+}
+
+// utilityCostEstimateDisks converts disk space by SEP ID and pool name reported by
+// account/get or rg/get into cost.DiskUsage
+func utilityCostEstimateDisks(ctx context.Context, seps map[string]map[string]float64) []cost.DiskUsage {
+	disks := []cost.DiskUsage{}
+	for sepId, pools := range seps {
+		id, err := strconv.Atoi(sepId)
+		if err != nil {
+			logger.Debugf(ctx, "utilityCostEstimateDisks: skip SEP with unexpected ID %q", sepId)
+			continue
+		}
+		for pool, size := range pools {
+			disks = append(disks, cost.DiskUsage{SepID: id, Pool: pool, Size: size})
+		}
+	}
+	return disks
+}
+
+// utilityCostEstimateAccountSEPs returns disk space of the account by SEP pool, used
+// space for the current usage and reserved space for the reserved one
+func utilityCostEstimateAccountSEPs(resource account.Resource, reserved bool) map[string]map[string]float64 {
+	seps := map[string]map[string]float64{}
+	for sepId, pools := range resource.SEPs {
+		seps[sepId] = map[string]float64{}
+		for pool, sep := range pools {
+			seps[sepId][pool] = sep.DiskSize
+			if reserved {
+				seps[sepId][pool] = float64(sep.DiskSizeMax)
+			}
+		}
+	}
+	return seps
+}
+
+// utilityCostEstimateResourceUsage converts resources reported by account/get or rg/get
+// into consumed units. Disk space is counted by SEP pool when the controller reports it.
+func utilityCostEstimateResourceUsage(ctx context.Context, cpu, ram, extIPs int, diskSize float64, seps map[string]map[string]float64) cost.Usage {
+	usage := cost.Usage{}
+	usage.Add(cost.CUCPU, float64(cpu))
+	usage.Add(cost.CURAM, float64(ram))
+	usage.Add(cost.CUExtIP, float64(extIPs))
+	if len(seps) == 0 {
+		usage.Add(cost.CUDisk, diskSize)
+		return usage
+	}
+	usage.Disks = utilityCostEstimateDisks(ctx, seps)
+	return usage
+}
+
+func utilityCostEstimateAccountGet(ctx context.Context, m interface{}, accountId int) (*account.AccountWithResources, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("accountId", strconv.Itoa(accountId))
+
+	logger.Debugf(ctx, "utilityCostEstimateAccountGet: load account ID %d", accountId)
+	res, err := c.DecortAPICall(ctx, "POST", accountGetAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	acc := &account.AccountWithResources{}
+	if err := json.Unmarshal([]byte(res), acc); err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+// utilityCostEstimateAccountConsumedUnits loads consumed units of the account for CU_C,
+// CU_M, CU_D, CU_I and every other CU type of the price table. When the price table has
+// pool prices, CU_D is split by SEP pool as reported by account/get and the rest of it
+// is priced by the CU_D price.
+func utilityCostEstimateAccountConsumedUnits(ctx context.Context, m interface{}, accountId int, prices cost.Prices) (cost.Usage, error) {
+	c := m.(*controller.ControllerCfg)
+
+	cuTypes := []string{cost.CUCPU, cost.CURAM, cost.CUDisk, cost.CUExtIP}
+	for cuType := range prices.Units {
+		if cuType != cost.CUCPU && cuType != cost.CURAM && cuType != cost.CUDisk && cuType != cost.CUExtIP {
+			cuTypes = append(cuTypes, cuType)
+		}
+	}
+	sort.Strings(cuTypes)
+
+	units := make([]float64, len(cuTypes))
+	err := parallel.Run(ctx, len(cuTypes), parallel.Limit, func(ctx context.Context, i int) error {
+		urlValues := &url.Values{}
+		urlValues.Add("accountId", strconv.Itoa(accountId))
+		urlValues.Add("cutype", cuTypes[i])
+
+		logger.Debugf(ctx, "utilityCostEstimateAccountConsumedUnits: load %s of account ID %d", cuTypes[i], accountId)
+		res, err := c.DecortAPICall(ctx, "POST", accountGetConsumedUnitsByTypeAPI, urlValues)
+		if err != nil {
+			return err
+		}
+		units[i], err = strconv.ParseFloat(res, 64)
+		if err != nil {
+			return fmt.Errorf("cannot parse consumed units %s of account ID %d: %w", cuTypes[i], accountId, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return cost.Usage{}, err
+	}
+
+	usage := cost.Usage{}
+	for i, cuType := range cuTypes {
+		usage.Add(cuType, units[i])
+	}
+	if len(prices.Pools) == 0 {
+		return usage, nil
+	}
+
+	acc, err := utilityCostEstimateAccountGet(ctx, m, accountId)
+	if err != nil {
+		return cost.Usage{}, err
+	}
+	usage.Disks = utilityCostEstimateDisks(ctx, utilityCostEstimateAccountSEPs(acc.Resources.Current, false))
+	rest := usage.Units[cost.CUDisk]
+	for _, disk := range usage.Disks {
+		rest -= disk.Size
+	}
+	if rest < 0 {
+		rest = 0
+	}
+	usage.Units[cost.CUDisk] = rest
+	return usage, nil
+}
+
+func utilityCostEstimateAccountUsage(ctx context.Context, m interface{}, accountId int, reserved bool, prices cost.Prices) (cost.Usage, error) {
+	if !reserved {
+		return utilityCostEstimateAccountConsumedUnits(ctx, m, accountId, prices)
+	}
+
+	acc, err := utilityCostEstimateAccountGet(ctx, m, accountId)
+	if err != nil {
+		return cost.Usage{}, err
+	}
+	resource := acc.Resources.Reserved
+	seps := utilityCostEstimateAccountSEPs(resource, true)
+	return utilityCostEstimateResourceUsage(ctx, resource.CPU, resource.RAM, resource.Extips, resource.Disksize, seps), nil
+}
+
+// utilityCostEstimateRGUsage counts consumed units of the resource group by rg/get, the
+// controller reports consumed units by CU type for accounts only
+func utilityCostEstimateRGUsage(ctx context.Context, m interface{}, rgId int, reserved bool) (cost.Usage, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("rgId", strconv.Itoa(rgId))
+
+	logger.Debugf(ctx, "utilityCostEstimateRGUsage: load resource group ID %d", rgId)
+	res, err := c.DecortAPICall(ctx, "POST", rgGetAPI, urlValues)
+	if err != nil {
+		return cost.Usage{}, err
+	}
+
+	rgData := rg.ResgroupGetResp{}
+	if err := json.Unmarshal([]byte(res), &rgData); err != nil {
+		return cost.Usage{}, err
+	}
+
+	resource := rgData.Resources.Current
+	if reserved {
+		resource = rgData.Resources.Reserved
+	}
+	seps := map[string]map[string]float64{}
+	for sepId, pools := range resource.SEPs {
+		seps[sepId] = map[string]float64{}
+		for pool, sep := range pools {
+			seps[sepId][pool] = sep.DiskSize
+			if reserved {
+				seps[sepId][pool] = float64(sep.DiskSizeMax)
+			}
+		}
+	}
+	return utilityCostEstimateResourceUsage(ctx, resource.CPU, resource.RAM, resource.Extips, resource.Disksize, seps), nil
+}
+
+func utilityCostEstimateCheckPresence(ctx context.Context, d *schema.ResourceData, m interface{}, prices cost.Prices) (cost.Usage, error) {
+	reserved := d.Get("usage").(string) == "reserved"
+	if accountId, ok := d.GetOk("account_id"); ok {
+		return utilityCostEstimateAccountUsage(ctx, m, accountId.(int), reserved, prices)
+	}
+	if rgId, ok := d.GetOk("rg_id"); ok {
+		return utilityCostEstimateRGUsage(ctx, m, rgId.(int), reserved)
+	}
+	return cost.Usage{}, fmt.Errorf("either account_id or rg_id must be set")
+}
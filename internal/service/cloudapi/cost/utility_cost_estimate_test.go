@@ -0,0 +1,164 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/cost"
+)
+
+// stubController serves account/get and consumed units of the account by CU type
+type stubController struct {
+	sync.Mutex
+	units   map[string]float64
+	seps    map[string]map[string]map[string]float64
+	cuTypes []string // CU types requested from the controller
+}
+
+func (s *stubController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/restmachine/cloudapi/accounts/list":
+		json.NewEncoder(w).Encode([]interface{}{})
+	case accountGetAPI:
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":        7,
+			"Resources": map[string]interface{}{"Current": map[string]interface{}{"seps": s.seps}},
+		})
+	case accountGetConsumedUnitsByTypeAPI:
+		cuType := r.Form.Get("cutype")
+		s.cuTypes = append(s.cuTypes, cuType)
+		fmt.Fprint(w, s.units[cuType])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// testControllerConfigure configures the controller the way the provider does, against the stub
+func testControllerConfigure(t *testing.T, handler http.Handler) *controller.ControllerCfg {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	providerSchema := map[string]*schema.Schema{
+		"default_tags": {Type: schema.TypeMap, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+	}
+	for _, key := range []string{"authenticator", "controller_url", "jwt", "oauth2_url", "user", "password",
+		"app_id", "app_secret", "trace_file", "name_prefix", "name_pattern", "quota_check"} {
+		providerSchema[key] = &schema.Schema{Type: schema.TypeString, Optional: true}
+	}
+	providerSchema["cache_ttl"] = &schema.Schema{Type: schema.TypeInt, Optional: true}
+	providerSchema["allow_unverified_ssl"] = &schema.Schema{Type: schema.TypeBool, Optional: true}
+
+	d := schema.TestResourceDataRaw(t, providerSchema, map[string]interface{}{
+		"authenticator":  "jwt",
+		"controller_url": srv.URL,
+		"jwt":            "jwt",
+		"oauth2_url":     srv.URL,
+	})
+	c, err := controller.ControllerConfigure(context.Background(), d)
+	if err != nil {
+		t.Fatalf("ControllerConfigure() error = %v", err)
+	}
+	return c
+}
+
+func TestCostEstimateAccountConsumedUnits(t *testing.T) {
+	tests := []struct {
+		name        string
+		prices      cost.Prices
+		seps        map[string]map[string]map[string]float64
+		wantCUTypes []string
+		want        cost.Usage
+	}{
+		{
+			name:        "CU types of the price table",
+			prices:      cost.Prices{Units: map[string]float64{cost.CUCPU: 500, "CU_NP": 10}},
+			wantCUTypes: []string{"CU_C", "CU_D", "CU_I", "CU_M", "CU_NP"},
+			want:        cost.Usage{Units: map[string]float64{"CU_C": 4, "CU_D": 100, "CU_I": 1, "CU_M": 8192, "CU_NP": 3}},
+		},
+		{
+			name:   "CU_D split by pool",
+			prices: cost.Prices{Pools: []cost.PoolPrice{{SepID: 1, Pool: "ssd", Price: 12}}},
+			seps: map[string]map[string]map[string]float64{
+				"1": {"ssd": {"disksize": 30, "disksizemax": 50}},
+			},
+			wantCUTypes: []string{"CU_C", "CU_D", "CU_I", "CU_M"},
+			want: cost.Usage{
+				Units: map[string]float64{"CU_C": 4, "CU_D": 70, "CU_I": 1, "CU_M": 8192},
+				Disks: []cost.DiskUsage{{SepID: 1, Pool: "ssd", Size: 30}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &stubController{
+				units: map[string]float64{"CU_C": 4, "CU_M": 8192, "CU_D": 100, "CU_I": 1, "CU_NP": 3},
+				seps:  tc.seps,
+			}
+			c := testControllerConfigure(t, stub)
+
+			usage, err := utilityCostEstimateAccountConsumedUnits(context.Background(), c, 7, tc.prices)
+			if err != nil {
+				t.Fatalf("utilityCostEstimateAccountConsumedUnits() error = %v", err)
+			}
+			if !reflect.DeepEqual(usage, tc.want) {
+				t.Errorf("utilityCostEstimateAccountConsumedUnits() = %+v, want %+v", usage, tc.want)
+			}
+			sort.Strings(stub.cuTypes)
+			if !reflect.DeepEqual(stub.cuTypes, tc.wantCUTypes) {
+				t.Errorf("requested CU types %v, want %v", stub.cuTypes, tc.wantCUTypes)
+			}
+		})
+	}
+}
@@ -44,7 +44,6 @@ import (
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
 	"github.com/rudecs/terraform-provider-decort/internal/flattens"
-	log "github.com/sirupsen/logrus"
 )
 
 func utilityDiskListUnattachedCheckPresence(ctx context.Context, d *schema.ResourceData, m interface{}) (UnattachedList, error) {
@@ -55,7 +54,7 @@ func utilityDiskListUnattachedCheckPresence(ctx context.Context, d *schema.Resou
 		urlValues.Add("accountId", strconv.Itoa(accountId.(int)))
 	}
 
-	log.Debugf("utilityDiskListUnattachedCheckPresence: load disk Unattached list")
+	logger.Debugf(ctx, "utilityDiskListUnattachedCheckPresence: load disk Unattached list")
 	unattachedListRaw, err := c.DecortAPICall(ctx, "POST", disksListUnattachedAPI, urlValues)
 	if err != nil {
 		return nil, err
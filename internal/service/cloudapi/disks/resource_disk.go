@@ -43,8 +43,9 @@ import (
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
 	"github.com/rudecs/terraform-provider-decort/internal/dc"
+	"github.com/rudecs/terraform-provider-decort/internal/location"
+	"github.com/rudecs/terraform-provider-decort/internal/quota"
 	"github.com/rudecs/terraform-provider-decort/internal/status"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -55,8 +56,14 @@ func resourceDiskCreate(ctx context.Context, d *schema.ResourceData, m interface
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 
+	gridId, err := location.UtilityLocationResourceGridID(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("gid", gridId)
+
 	urlValues.Add("accountId", fmt.Sprintf("%d", d.Get("account_id").(int)))
-	urlValues.Add("gid", fmt.Sprintf("%d", d.Get("gid").(int)))
+	urlValues.Add("gid", fmt.Sprintf("%d", gridId))
 	urlValues.Add("name", d.Get("disk_name").(string))
 	urlValues.Add("size", fmt.Sprintf("%d", d.Get("size_max").(int)))
 	if typeRaw, ok := d.GetOk("type"); ok {
@@ -235,7 +242,7 @@ func resourceDiskUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	if d.HasChange("size_max") {
 		oldSize, newSize := d.GetChange("size_max")
 		if oldSize.(int) < newSize.(int) {
-			log.Debugf("resourceDiskUpdate: resizing disk ID %s - %d GB -> %d GB",
+			logger.Debugf(ctx, "resourceDiskUpdate: resizing disk ID %s - %d GB -> %d GB",
 				d.Id(), oldSize.(int), newSize.(int))
 			urlValues.Add("diskId", d.Id())
 			urlValues.Add("size", fmt.Sprintf("%d", newSize.(int)))
@@ -308,6 +315,24 @@ func resourceDiskUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	return resourceDiskRead(ctx, d, m)
 }
 
+func resourceDiskCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c := m.(*controller.ControllerCfg)
+
+	// naming policy applies to new and renamed objects only
+	if (d.Id() == "" || d.HasChange("disk_name")) && d.NewValueKnown("disk_name") {
+		if err := c.CheckName(d.Get("disk_name").(string)); err != nil {
+			return err
+		}
+	}
+
+	oldSize, newSize := d.GetChange("size_max")
+	return quota.Check(ctx, m, diskQuotaKey(d), d.Get("account_id").(int), 0, quota.Usage{Disk: newSize.(int) - oldSize.(int)})
+}
+
+func diskQuotaKey(d quota.Resource) string {
+	return quota.Key("disk", d, "account_id", "disk_name")
+}
+
 func resourceDiskDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	disk, err := utilityDiskCheckPresence(ctx, d, m)
 	if disk == nil {
@@ -352,11 +377,14 @@ func resourceDiskSchemaMake() map[string]*schema.Schema {
 			Description: "Size in GB",
 		},
 		"gid": {
-			Type:        schema.TypeInt,
-			Required:    true,
-			ForceNew:    true,
-			Description: "ID of the grid (platform)",
-		},
+			Type:          schema.TypeInt,
+			Optional:      true,
+			Computed:      true,
+			ForceNew:      true,
+			ConflictsWith: []string{"location"},
+			Description:   "ID of the grid (platform). If neither gid nor location is set, the default grid of the provider is used.",
+		},
+		"location": location.ResourceLocationSchemaMake(),
 		"pool": {
 			Type:        schema.TypeString,
 			Optional:    true,
@@ -727,11 +755,13 @@ func ResourceDisk() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
 
-		CreateContext: resourceDiskCreate,
+		CreateContext: quota.WithWarnings(resourceDiskCreate, diskQuotaKey),
 		ReadContext:   resourceDiskRead,
-		UpdateContext: resourceDiskUpdate,
+		UpdateContext: quota.WithWarnings(resourceDiskUpdate, diskQuotaKey),
 		DeleteContext: resourceDiskDelete,
 
+		CustomizeDiff: resourceDiskCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
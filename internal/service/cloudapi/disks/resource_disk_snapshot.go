@@ -34,14 +34,15 @@ package disks
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceDiskSnapshotCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -70,7 +71,7 @@ func resourceDiskSnapshotCreate(ctx context.Context, d *schema.ResourceData, m i
 		urlValues.Add("diskId", strconv.Itoa(d.Get("disk_id").(int)))
 		urlValues.Add("label", label)
 		urlValues.Add("timestamp", strconv.Itoa(d.Get("timestamp").(int)))
-		log.Debugf("resourceDiskCreate: Snapshot rollback with label", label)
+		logger.Debugf(ctx, "resourceDiskCreate: Snapshot rollback with label %s", label)
 		_, err := c.DecortAPICall(ctx, "POST", disksSnapshotRollbackAPI, urlValues)
 		if err != nil {
 			return diag.FromErr(err)
@@ -136,7 +137,7 @@ func resourceDiskSnapshotUpdate(ctx context.Context, d *schema.ResourceData, m i
 		urlValues.Add("diskId", strconv.Itoa(d.Get("disk_id").(int)))
 		urlValues.Add("label", label)
 		urlValues.Add("timestamp", strconv.Itoa(d.Get("timestamp").(int)))
-		log.Debugf("resourceDiskUpdtae: Snapshot rollback with label", label)
+		logger.Debugf(ctx, "resourceDiskUpdate: Snapshot rollback with label %s", label)
 		_, err := c.DecortAPICall(ctx, "POST", disksSnapshotRollbackAPI, urlValues)
 		if err != nil {
 			return diag.FromErr(err)
@@ -188,6 +189,7 @@ func resourceDiskSnapshotSchemaMake() map[string]*schema.Schema {
 			Type:        schema.TypeBool,
 			Optional:    true,
 			Default:     false,
+			Deprecated:  "use decort_snapshot_rollback resource instead",
 			Description: "Needed in order to make a snapshot rollback",
 		},
 		"guid": {
@@ -220,6 +222,38 @@ func resourceDiskSnapshotSchemaMake() map[string]*schema.Schema {
 	return rets
 }
 
+func resourceDiskSnapshotImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourceDiskSnapshotImport: called with id %s", d.Id())
+
+	parameters := strings.SplitN(d.Id(), "#", 2)
+	if len(parameters) != 2 || parameters[1] == "" {
+		return nil, fmt.Errorf("invalid import id %q: expected <disk_id>#<label>", d.Id())
+	}
+
+	diskId, err := strconv.Atoi(parameters[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid import id %q: disk id must be a number", d.Id())
+	}
+
+	d.Set("disk_id", diskId)
+	d.Set("label", parameters[1])
+	d.Set("rollback", false)
+
+	disk, err := utilityDiskCheckPresence(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sn := range disk.Snapshots {
+		if sn.Label == parameters[1] {
+			d.SetId(sn.Label)
+			return []*schema.ResourceData{d}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("snapshot with label %q not found on disk %d", parameters[1], diskId)
+}
+
 func ResourceDiskSnapshot() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
@@ -230,7 +264,7 @@ func ResourceDiskSnapshot() *schema.Resource {
 		DeleteContext: resourceDiskSnapshotDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceDiskSnapshotImport,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -39,7 +39,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -56,7 +55,7 @@ func utilityDiskCheckPresence(ctx context.Context, d *schema.ResourceData, m int
 		urlValues.Add("diskId", strconv.Itoa(d.Get("disk_id").(int)))
 	}
 
-	log.Debugf("utilityDiskCheckPresence: load disk")
+	logger.Debugf(ctx, "utilityDiskCheckPresence: load disk")
 	diskRaw, err := c.DecortAPICall(ctx, "POST", disksGetAPI, urlValues)
 	if err != nil {
 		return nil, err
@@ -39,7 +39,6 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func utilityDiskListTypesCheckPresence(ctx context.Context, d *schema.ResourceData, m interface{}) (TypesList, error) {
@@ -47,7 +46,7 @@ func utilityDiskListTypesCheckPresence(ctx context.Context, d *schema.ResourceDa
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 	urlValues.Add("detailed", "false")
-	log.Debugf("utilityDiskListTypesCheckPresence: load disk list Types Detailed")
+	logger.Debugf(ctx, "utilityDiskListTypesCheckPresence: load disk list Types Detailed")
 	diskListRaw, err := c.DecortAPICall(ctx, "POST", disksListTypesAPI, urlValues)
 	if err != nil {
 		return nil, err
@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -50,7 +49,7 @@ func utilityExtnetCheckPresence(ctx context.Context, d *schema.ResourceData, m i
 
 	urlValues.Add("net_id", strconv.Itoa(d.Get("net_id").(int)))
 
-	log.Debugf("utilityExtnetCheckPresence")
+	logger.Debugf(ctx, "utilityExtnetCheckPresence")
 	extnetRaw, err := c.DecortAPICall(ctx, "POST", extnetGetAPI, urlValues)
 	if err != nil {
 		return nil, err
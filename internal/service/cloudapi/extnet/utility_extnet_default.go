@@ -36,14 +36,13 @@ import (
 	"net/url"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func utilityExtnetDefaultCheckPresence(ctx context.Context, m interface{}) (string, error) {
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 
-	log.Debugf("utilityExtnetDefaultCheckPresence")
+	logger.Debugf(ctx, "utilityExtnetDefaultCheckPresence")
 	res, err := c.DecortAPICall(ctx, "POST", extnetGetDefaultAPI, urlValues)
 	if err != nil {
 		return "", err
@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -58,7 +57,7 @@ func utilityExtnetListCheckPresence(ctx context.Context, d *schema.ResourceData,
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityExtnetListCheckPresence")
+	logger.Debugf(ctx, "utilityExtnetListCheckPresence")
 	extnetListRaw, err := c.DecortAPICall(ctx, "POST", extnetListAPI, urlValues)
 	if err != nil {
 		return nil, err
@@ -35,7 +35,9 @@ package image
 const imageCreateAPI = "/restmachine/cloudapi/image/create"
 const imageCreateVirtualAPI = "/restmachine/cloudapi/image/createVirtual"
 const imageGetAPI = "/restmachine/cloudapi/image/get"
+const imageTaskGetAPI = "/restmachine/cloudapi/tasks/get"
 const imageListGetAPI = "/restmachine/cloudapi/image/list"
 const imageDeleteAPI = "/restmachine/cloudapi/image/delete"
 const imageEditNameAPI = "/restmachine/cloudapi/image/rename"
 const imageLinkAPI = "/restmachine/cloudapi/image/link"
+const imageShareAPI = "/restmachine/cloudapi/image/share"
@@ -35,6 +35,7 @@ package image
 import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/rudecs/terraform-provider-decort/internal/location"
 )
 
 func resourceImageSchemaMake(sch map[string]*schema.Schema) map[string]*schema.Schema {
@@ -46,17 +47,85 @@ func resourceImageSchemaMake(sch map[string]*schema.Schema) map[string]*schema.S
 	}
 
 	sch["url"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ExactlyOneOf: []string{"url", "source_file"},
+		Description:  "URL where to download media from",
+	}
+
+	sch["source_file"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ForceNew:     true,
+		ExactlyOneOf: []string{"url", "source_file"},
+		Description:  "Path to the local image file. The file is served over HTTP from this host until the controller downloads it.",
+	}
+
+	sch["source_file_listen"] = &schema.Schema{
 		Type:        schema.TypeString,
-		Required:    true,
-		Description: "URL where to download media from",
+		Optional:    true,
+		Default:     "0.0.0.0:0",
+		Description: "Address to listen on while serving source_file. Random port by default.",
+	}
+
+	sch["source_file_address"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Host name or IP address the controller reaches this host by. Detected from the route to the controller if not set.",
+	}
+
+	sch["shared_with_accounts"] = &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Elem:        &schema.Schema{Type: schema.TypeInt},
+		Description: "IDs of the accounts to share the image and its replicas with. Authoritative: accounts not listed lose access, and the image is not shared with anyone if the set is empty or not set. Do not combine with decort_image_access for the same image.",
+	}
+
+	sch["replicate_to_grids"] = &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Elem:        &schema.Schema{Type: schema.TypeInt},
+		Description: "IDs of other grids to create linked copies of the image in. Copies are virtual images linked to the image, shared with the same accounts and deleted together with the image.",
+	}
+
+	sch["replicas"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "Copies of the image in other grids.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"gid": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "grid ID of the copy",
+				},
+				"image_id": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "image ID of the copy",
+				},
+			},
+		},
+	}
+
+	sch["checksum"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ForceNew:     true,
+		ValidateFunc: validateImageChecksum,
+		Description:  "Checksum of the image in <algorithm>:<hex digest> form, sha256 and md5 are supported. Verified before the image is created, the content of url is downloaded to verify it by the host running Terraform.",
 	}
 
 	sch["gid"] = &schema.Schema{
-		Type:        schema.TypeInt,
-		Required:    true,
-		Description: "grid (platform) ID where this template should be create in",
+		Type:          schema.TypeInt,
+		Optional:      true,
+		Computed:      true,
+		ConflictsWith: []string{"location"},
+		Description:   "grid (platform) ID where this template should be create in. If neither gid nor location is set, the default grid of the provider is used.",
 	}
 
+	sch["location"] = location.ResourceLocationSchemaMake()
+
 	sch["image_id"] = &schema.Schema{
 		Type:        schema.TypeInt,
 		Optional:    true,
@@ -110,6 +110,15 @@ type History struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
+// ImageTask is the task of an operation on the image, as reported by tasks/get
+type ImageTask struct {
+	AuditID   string   `json:"auditId"`
+	Completed bool     `json:"completed"`
+	Error     string   `json:"error"`
+	Log       []string `json:"log"`
+	Status    string   `json:"status"`
+}
+
 type ImageExtend struct {
 	UNCPath       string      `json:"UNCPath"`
 	CKey          string      `json:"_ckey"`
@@ -34,7 +34,6 @@ package image
 
 import (
 	"context"
-	"encoding/json"
 	"net/url"
 	"strconv"
 
@@ -42,17 +41,76 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
+	"github.com/rudecs/terraform-provider-decort/internal/location"
 )
 
 func resourceImageCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceImageCreate: called for image %s", d.Get("name").(string))
+	logger.Debugf(ctx, "resourceImageCreate: called for image %s", d.Get("name").(string))
+
+	gridId, err := location.UtilityLocationResourceGridID(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("gid", gridId)
+
+	imageId, err := resourceImageCreateInGrid(ctx, d, m, gridId)
+	if imageId != 0 {
+		// keep the image in the state even if it failed, so that it is replaced on the next run
+		d.SetId(strconv.Itoa(imageId))
+		d.Set("image_id", imageId)
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := utilityImageSharesConfigure(ctx, d, m); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := utilityImageReplicasConfigure(ctx, d, m); err != nil {
+		return diag.FromErr(err)
+	}
+
+	diagnostics := resourceImageRead(ctx, d, m)
+	if diagnostics != nil {
+		return diagnostics
+	}
+
+	return nil
+}
+
+// resourceImageCreateInGrid creates the image in the grid from url or source_file and waits
+// until it is ready
+func resourceImageCreateInGrid(ctx context.Context, d *schema.ResourceData, m interface{}, gid int) (int, error) {
+	logger.Debugf(ctx, "resourceImageCreateInGrid: creating image %s in grid %d", d.Get("name").(string), gid)
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 	urlValues.Add("name", d.Get("name").(string))
-	urlValues.Add("url", d.Get("url").(string))
-	urlValues.Add("gid", strconv.Itoa(d.Get("gid").(int)))
+
+	imageURL := d.Get("url").(string)
+	checksum := d.Get("checksum").(string)
+	if sourceFile, ok := d.GetOk("source_file"); ok {
+		if checksum != "" {
+			if err := utilityImageVerifyFileChecksum(ctx, sourceFile.(string), checksum); err != nil {
+				return 0, err
+			}
+		}
+
+		fileURL, stop, err := utilityImageServeFile(ctx, c, sourceFile.(string),
+			d.Get("source_file_listen").(string), d.Get("source_file_address").(string))
+		if err != nil {
+			return 0, err
+		}
+		// the file is served until the controller has downloaded it
+		defer stop()
+		imageURL = fileURL
+	} else if checksum != "" {
+		if err := utilityImageVerifyURLChecksum(ctx, m, d, checksum); err != nil {
+			return 0, err
+		}
+	}
+	urlValues.Add("url", imageURL)
+	urlValues.Add("gid", strconv.Itoa(gid))
 	urlValues.Add("boottype", d.Get("boot_type").(string))
 	urlValues.Add("imagetype", d.Get("type").(string))
 
@@ -96,44 +154,25 @@ func resourceImageCreate(ctx context.Context, d *schema.ResourceData, m interfac
 	if architecture, ok := d.GetOk("architecture"); ok {
 		urlValues.Add("architecture", architecture.(string))
 	}
-	/* uncomment then OK
-	imageId, err := c.DecortAPICall(ctx, "POST", imageCreateAPI, urlValues)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	*/
-	//innovation
 	res, err := c.DecortAPICall(ctx, "POST", imageCreateAPI, urlValues)
 	if err != nil {
-		return diag.FromErr(err)
+		return 0, err
 	}
 
-	i := make([]interface{}, 0)
-	err = json.Unmarshal([]byte(res), &i)
+	imageId, err := utilityImageParseCreateResp(res)
 	if err != nil {
-		return diag.FromErr(err)
+		return 0, err
 	}
-	imageId := strconv.Itoa(int(i[1].(float64)))
-	// end innovation
 
-	d.SetId(imageId)
-	d.Set("image_id", imageId)
-
-	_, err = utilityImageCheckPresence(ctx, d, m)
-	if err != nil {
-		return diag.FromErr(err)
+	if err := utilityImageWaitReady(ctx, m, imageId); err != nil {
+		return imageId, err
 	}
 
-	diagnostics := resourceImageRead(ctx, d, m)
-	if diagnostics != nil {
-		return diagnostics
-	}
-
-	return nil
+	return imageId, nil
 }
 
 func resourceImageRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceImageRead: called for %s id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceImageRead: called for %s id: %s", d.Get("name").(string), d.Id())
 
 	img, err := utilityImageCheckPresence(ctx, d, m)
 	if img == nil {
@@ -170,6 +209,7 @@ func resourceImageRead(ctx context.Context, d *schema.ResourceData, m interface{
 	d.Set("rescuecd", img.RescueCD)
 	d.Set("sep_id", img.SepId)
 	d.Set("shared_with", img.SharedWith)
+	d.Set("shared_with_accounts", img.SharedWith)
 	d.Set("size", img.Size)
 	d.Set("status", img.Status)
 	d.Set("tech_status", img.TechStatus)
@@ -177,11 +217,18 @@ func resourceImageRead(ctx context.Context, d *schema.ResourceData, m interface{
 	d.Set("username", img.Username)
 	d.Set("version", img.Version)
 
+	// replicas are tracked by decort_image only, decort_image_virtual shares this function
+	if _, ok := d.GetOk("replicas"); ok {
+		if err := utilityImageReplicasRead(ctx, d, m); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	return nil
 }
 
 func resourceImageDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceImageDelete: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceImageDelete: called for %s, id: %s", d.Get("name").(string), d.Id())
 
 	image, err := utilityImageCheckPresence(ctx, d, m)
 	if image == nil {
@@ -191,6 +238,10 @@ func resourceImageDelete(ctx context.Context, d *schema.ResourceData, m interfac
 		return nil
 	}
 
+	if err := utilityImageReplicasDelete(ctx, d, m); err != nil {
+		return diag.FromErr(err)
+	}
+
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 	urlValues.Add("imageId", strconv.Itoa(d.Get("image_id").(int)))
@@ -209,7 +260,7 @@ func resourceImageDelete(ctx context.Context, d *schema.ResourceData, m interfac
 }
 
 func resourceImageEditName(ctx context.Context, d *schema.ResourceData, m interface{}) error {
-	log.Debugf("resourceImageEditName: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceImageEditName: called for %s, id: %s", d.Get("name").(string), d.Id())
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 	urlValues.Add("imageId", strconv.Itoa(d.Get("image_id").(int)))
@@ -223,7 +274,7 @@ func resourceImageEditName(ctx context.Context, d *schema.ResourceData, m interf
 }
 
 func resourceImageEdit(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceImageEdit: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceImageEdit: called for %s, id: %s", d.Get("name").(string), d.Id())
 
 	if d.HasChange("name") {
 		err := resourceImageEditName(ctx, d, m)
@@ -232,6 +283,16 @@ func resourceImageEdit(ctx context.Context, d *schema.ResourceData, m interface{
 		}
 	}
 
+	if d.HasChange("replicate_to_grids") {
+		if err := utilityImageReplicasConfigure(ctx, d, m); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err := utilityImageSharesConfigure(ctx, d, m); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return resourceImageRead(ctx, d, m)
 }
 
@@ -249,7 +310,7 @@ func ResourceImage() *schema.Resource {
 		},
 
 		Timeouts: &schema.ResourceTimeout{
-			Create:  &constants.Timeout600s,
+			Create:  &constants.Timeout30m,
 			Read:    &constants.Timeout300s,
 			Update:  &constants.Timeout300s,
 			Delete:  &constants.Timeout300s,
@@ -0,0 +1,190 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/constants"
+)
+
+// image/share replaces the whole list of accounts, so concurrent changes of access
+// to the same image from several decort_image_access resources are serialized
+var imageAccessMutex sync.Mutex
+
+// resourceImageAccessChange adds the account to or removes it from the accounts the image is shared with
+func resourceImageAccessChange(ctx context.Context, m interface{}, imageId int, accountId int, grant bool) error {
+	imageAccessMutex.Lock()
+	defer imageAccessMutex.Unlock()
+
+	img, err := utilityImageGet(ctx, m, imageId)
+	if err != nil {
+		return err
+	}
+
+	accounts := make([]int, 0, len(img.SharedWith)+1)
+	for _, accId := range img.SharedWith {
+		if accId != accountId {
+			accounts = append(accounts, accId)
+		}
+	}
+	if grant {
+		accounts = append(accounts, accountId)
+	}
+
+	return utilityImageShare(ctx, m, imageId, accounts)
+}
+
+func resourceImageAccessCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	imageId, accountId := d.Get("image_id").(int), d.Get("account_id").(int)
+	logger.Debugf(ctx, "resourceImageAccessCreate: called for image %d, account %d", imageId, accountId)
+
+	if err := resourceImageAccessChange(ctx, m, imageId, accountId, true); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%d#%d", imageId, accountId))
+
+	return resourceImageAccessRead(ctx, d, m)
+}
+
+func resourceImageAccessRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceImageAccessRead: called for %s", d.Id())
+
+	img, err := utilityImageGet(ctx, m, d.Get("image_id").(int))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	for _, accId := range img.SharedWith {
+		if accId == d.Get("account_id").(int) {
+			d.Set("image_name", img.Name)
+			d.Set("gid", img.GridId)
+			return nil
+		}
+	}
+
+	// access was revoked outside of terraform
+	d.SetId("")
+	return nil
+}
+
+func resourceImageAccessDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceImageAccessDelete: called for %s", d.Id())
+
+	if err := resourceImageAccessChange(ctx, m, d.Get("image_id").(int), d.Get("account_id").(int), false); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceImageAccessImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourceImageAccessImport: called with id %s", d.Id())
+
+	parameters := strings.Split(d.Id(), "#")
+	if len(parameters) != 2 {
+		return nil, fmt.Errorf("invalid import id %q: expected <image_id>#<account_id>", d.Id())
+	}
+
+	imageId, err := strconv.Atoi(parameters[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid import id %q: image id must be a number", d.Id())
+	}
+	accountId, err := strconv.Atoi(parameters[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid import id %q: account id must be a number", d.Id())
+	}
+
+	d.Set("image_id", imageId)
+	d.Set("account_id", accountId)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceImageAccessSchemaMake() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"image_id": {
+			Type:        schema.TypeInt,
+			Required:    true,
+			ForceNew:    true,
+			Description: "ID of the image to share.",
+		},
+		"account_id": {
+			Type:        schema.TypeInt,
+			Required:    true,
+			ForceNew:    true,
+			Description: "ID of the account to share the image with.",
+		},
+		"image_name": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Name of the image.",
+		},
+		"gid": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "Grid ID of the image.",
+		},
+	}
+}
+
+func ResourceImageAccess() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+
+		CreateContext: resourceImageAccessCreate,
+		ReadContext:   resourceImageAccessRead,
+		DeleteContext: resourceImageAccessDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceImageAccessImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create:  &constants.Timeout60s,
+			Read:    &constants.Timeout30s,
+			Delete:  &constants.Timeout60s,
+			Default: &constants.Timeout60s,
+		},
+
+		Schema: resourceImageAccessSchemaMake(),
+	}
+}
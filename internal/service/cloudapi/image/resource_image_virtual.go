@@ -41,11 +41,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceImageVirtualCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceImageVirtualCreate: called for image %s", d.Get("name").(string))
+	logger.Debugf(ctx, "resourceImageVirtualCreate: called for image %s", d.Get("name").(string))
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -74,7 +73,7 @@ func resourceImageVirtualCreate(ctx context.Context, d *schema.ResourceData, m i
 }
 
 func resourceImageVirtualEdit(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceImageEdit: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceImageEdit: called for %s, id: %s", d.Get("name").(string), d.Id())
 
 	if d.HasChange("name") {
 		err := resourceImageEditName(ctx, d, m)
@@ -94,7 +93,7 @@ func resourceImageVirtualEdit(ctx context.Context, d *schema.ResourceData, m int
 }
 
 func resourceImageVirtualLink(ctx context.Context, d *schema.ResourceData, m interface{}) error {
-	log.Debugf("resourceVirtualImageLink: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceVirtualImageLink: called for %s, id: %s", d.Get("name").(string), d.Id())
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 	urlValues.Add("imageId", strconv.Itoa(d.Get("image_id").(int)))
@@ -74,3 +74,21 @@ func utilityImageCheckPresence(ctx context.Context, d *schema.ResourceData, m in
 
 	return image, nil
 }
+
+func utilityImageGet(ctx context.Context, m interface{}, imageId int) (*ImageExtend, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("imageId", strconv.Itoa(imageId))
+
+	resp, err := c.DecortAPICall(ctx, "POST", imageGetAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	image := &ImageExtend{}
+	if err := json.Unmarshal([]byte(resp), image); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal image %d: %w", imageId, err)
+	}
+
+	return image, nil
+}
@@ -0,0 +1,246 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+// utilityImageShare sets the full list of accounts the image is shared with
+func utilityImageShare(ctx context.Context, m interface{}, imageId int, accounts []int) error {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("imageId", strconv.Itoa(imageId))
+
+	sort.Ints(accounts)
+	accIds := make([]string, 0, len(accounts))
+	for _, accId := range accounts {
+		accIds = append(accIds, strconv.Itoa(accId))
+	}
+	urlValues.Add("accounts", "["+strings.Join(accIds, ",")+"]")
+
+	logger.Debugf(ctx, "utilityImageShare: sharing image %d with accounts %v", imageId, accounts)
+	if _, err := c.DecortAPICall(ctx, "POST", imageShareAPI, urlValues); err != nil {
+		return fmt.Errorf("cannot share image %d with accounts %v: %w", imageId, accounts, err)
+	}
+	return nil
+}
+
+func utilityImageDelete(ctx context.Context, m interface{}, imageId int, permanently bool) error {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("imageId", strconv.Itoa(imageId))
+	urlValues.Add("permanently", strconv.FormatBool(permanently))
+
+	if _, err := c.DecortAPICall(ctx, "POST", imageDeleteAPI, urlValues); err != nil {
+		return fmt.Errorf("cannot delete image %d: %w", imageId, err)
+	}
+	return nil
+}
+
+func imageSharedAccounts(d *schema.ResourceData) []int {
+	accounts := make([]int, 0)
+	for _, accId := range d.Get("shared_with_accounts").(*schema.Set).List() {
+		accounts = append(accounts, accId.(int))
+	}
+	return accounts
+}
+
+// utilityImageSharesConfigure shares the image and its replicas with shared_with_accounts.
+// The list is authoritative: accounts not listed lose access.
+func utilityImageSharesConfigure(ctx context.Context, d *schema.ResourceData, m interface{}) error {
+	if !d.HasChange("shared_with_accounts") {
+		return nil
+	}
+
+	accounts := imageSharedAccounts(d)
+	if err := utilityImageShare(ctx, m, d.Get("image_id").(int), accounts); err != nil {
+		return err
+	}
+
+	for _, replicaRaw := range d.Get("replicas").([]interface{}) {
+		replica := replicaRaw.(map[string]interface{})
+		if err := utilityImageShare(ctx, m, replica["image_id"].(int), accounts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// utilityImageReplicaCreate creates a virtual image in the grid linked to the image
+func utilityImageReplicaCreate(ctx context.Context, d *schema.ResourceData, m interface{}, gid int) (int, error) {
+	logger.Debugf(ctx, "utilityImageReplicaCreate: linking image %d to grid %d", d.Get("image_id").(int), gid)
+
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("name", d.Get("name").(string))
+	urlValues.Add("targetId", strconv.Itoa(d.Get("image_id").(int)))
+	urlValues.Add("gid", strconv.Itoa(gid))
+	if accountId, ok := d.GetOk("account_id"); ok {
+		urlValues.Add("accountId", strconv.Itoa(accountId.(int)))
+	}
+
+	res, err := c.DecortAPICall(ctx, "POST", imageCreateVirtualAPI, urlValues)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(res)
+}
+
+// utilityImageReplicaLink links the replica back to the image if it points elsewhere
+func utilityImageReplicaLink(ctx context.Context, d *schema.ResourceData, m interface{}, replicaId int) error {
+	replica, err := utilityImageGet(ctx, m, replicaId)
+	if err != nil {
+		return err
+	}
+
+	imageId := d.Get("image_id").(int)
+	if replica.LinkTo == imageId {
+		return nil
+	}
+
+	logger.Debugf(ctx, "utilityImageReplicaLink: replica %d is linked to %d, relinking to %d", replicaId, replica.LinkTo, imageId)
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("imageId", strconv.Itoa(replicaId))
+	urlValues.Add("targetId", strconv.Itoa(imageId))
+	if _, err := c.DecortAPICall(ctx, "POST", imageLinkAPI, urlValues); err != nil {
+		return fmt.Errorf("cannot link replica %d to image %d: %w", replicaId, imageId, err)
+	}
+	return nil
+}
+
+// utilityImageReplicasConfigure converges linked copies of the image in other grids to
+// replicate_to_grids. Replicas are virtual images linked to the image and are shared
+// with the same accounts.
+func utilityImageReplicasConfigure(ctx context.Context, d *schema.ResourceData, m interface{}) error {
+	desired := map[int]bool{}
+	for _, gid := range d.Get("replicate_to_grids").(*schema.Set).List() {
+		if gid.(int) == d.Get("gid").(int) {
+			logger.Debugf(ctx, "utilityImageReplicasConfigure: grid %d is the grid of the image itself, skipping", gid.(int))
+			continue
+		}
+		desired[gid.(int)] = true
+	}
+
+	replicas := make([]interface{}, 0)
+	existing := map[int]bool{}
+	for _, replicaRaw := range d.Get("replicas").([]interface{}) {
+		replica := replicaRaw.(map[string]interface{})
+		if desired[replica["gid"].(int)] {
+			if err := utilityImageReplicaLink(ctx, d, m, replica["image_id"].(int)); err != nil {
+				return err
+			}
+			existing[replica["gid"].(int)] = true
+			replicas = append(replicas, replica)
+			continue
+		}
+
+		logger.Debugf(ctx, "utilityImageReplicasConfigure: deleting replica %d in grid %d", replica["image_id"].(int), replica["gid"].(int))
+		if err := utilityImageDelete(ctx, m, replica["image_id"].(int), d.Get("permanently").(bool)); err != nil {
+			return err
+		}
+	}
+	d.Set("replicas", replicas)
+
+	grids := make([]int, 0)
+	for gid := range desired {
+		if !existing[gid] {
+			grids = append(grids, gid)
+		}
+	}
+	sort.Ints(grids)
+
+	accounts := imageSharedAccounts(d)
+	for _, gid := range grids {
+		imageId, err := utilityImageReplicaCreate(ctx, d, m, gid)
+		if imageId != 0 {
+			replicas = append(replicas, map[string]interface{}{
+				"gid":      gid,
+				"image_id": imageId,
+			})
+			d.Set("replicas", replicas)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot replicate image to grid %d: %w", gid, err)
+		}
+
+		if len(accounts) != 0 {
+			if err := utilityImageShare(ctx, m, imageId, accounts); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// utilityImageReplicasRead refreshes replicas, dropping the ones deleted outside of terraform
+func utilityImageReplicasRead(ctx context.Context, d *schema.ResourceData, m interface{}) error {
+	replicas := make([]interface{}, 0)
+	for _, replicaRaw := range d.Get("replicas").([]interface{}) {
+		replica := replicaRaw.(map[string]interface{})
+		img, err := utilityImageGet(ctx, m, replica["image_id"].(int))
+		if err != nil {
+			return err
+		}
+		if imageFailedStatuses[img.Status] {
+			logger.Debugf(ctx, "utilityImageReplicasRead: replica %d in grid %d is %s", img.Id, img.GridId, img.Status)
+			continue
+		}
+		replicas = append(replicas, map[string]interface{}{
+			"gid":      img.GridId,
+			"image_id": img.Id,
+		})
+	}
+	return d.Set("replicas", replicas)
+}
+
+func utilityImageReplicasDelete(ctx context.Context, d *schema.ResourceData, m interface{}) error {
+	for _, replicaRaw := range d.Get("replicas").([]interface{}) {
+		replica := replicaRaw.(map[string]interface{})
+		if err := utilityImageDelete(ctx, m, replica["image_id"].(int), d.Get("permanently").(bool)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
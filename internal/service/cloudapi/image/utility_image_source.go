@@ -0,0 +1,339 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package image
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+// image statuses the platform reports while the image is being downloaded or created
+var imagePendingStatuses = map[string]bool{
+	"DOWNLOADING": true,
+	"CREATING":    true,
+}
+
+// image statuses meaning that the image could not be created
+var imageFailedStatuses = map[string]bool{
+	"ERROR":     true,
+	"DESTROYED": true,
+	"PURGED":    true,
+	"DELETED":   true,
+}
+
+// parseImageChecksum splits checksum in <algorithm>:<hex digest> form
+func parseImageChecksum(checksum string) (string, string, error) {
+	parts := strings.SplitN(checksum, ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("checksum %q must be in <algorithm>:<hex digest> form, e.g. sha256:9f86d0...", checksum)
+	}
+
+	algorithm, digest := strings.ToLower(parts[0]), strings.ToLower(parts[1])
+	length := 0
+	switch algorithm {
+	case "sha256":
+		length = sha256.Size
+	case "md5":
+		length = md5.Size
+	default:
+		return "", "", fmt.Errorf("checksum algorithm %q is not supported, use sha256 or md5", parts[0])
+	}
+
+	if raw, err := hex.DecodeString(digest); err != nil || len(raw) != length {
+		return "", "", fmt.Errorf("checksum %q is not a valid %s hex digest", checksum, algorithm)
+	}
+	return algorithm, digest, nil
+}
+
+func validateImageChecksum(v interface{}, k string) ([]string, []error) {
+	if _, _, err := parseImageChecksum(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %w", k, err)}
+	}
+	return nil, nil
+}
+
+// utilityImageVerifyChecksum calculates the digest of the content read from r
+// and compares it with the expected checksum
+func utilityImageVerifyChecksum(ctx context.Context, r io.Reader, checksum string, source string) error {
+	algorithm, digest, err := parseImageChecksum(checksum)
+	if err != nil {
+		return err
+	}
+
+	var h hash.Hash
+	if algorithm == "md5" {
+		h = md5.New()
+	} else {
+		h = sha256.New()
+	}
+
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return fmt.Errorf("cannot read %s to verify checksum: %w", source, err)
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if actual != digest {
+		return fmt.Errorf("checksum mismatch for %s: expected %s:%s, got %s:%s", source, algorithm, digest, algorithm, actual)
+	}
+
+	logger.Debugf(ctx, "utilityImageVerifyChecksum: %s (%d bytes) matches %s checksum", source, size, algorithm)
+	return nil
+}
+
+func utilityImageVerifyFileChecksum(ctx context.Context, path string, checksum string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return utilityImageVerifyChecksum(ctx, f, checksum, path)
+}
+
+// utilityImageVerifyURLChecksum streams the image from the url without storing it
+// to verify the content before the controller is asked to download it. The image is
+// downloaded by the host running Terraform, so the check covers the URL as this host
+// sees it, not as the controller does.
+func utilityImageVerifyURLChecksum(ctx context.Context, m interface{}, d *schema.ResourceData, checksum string) error {
+	c := m.(*controller.ControllerCfg)
+	imageURL := d.Get("url").(string)
+	req, err := http.NewRequestWithContext(ctx, "GET", imageURL, nil)
+	if err != nil {
+		return err
+	}
+	if username, ok := d.GetOk("username_dl"); ok {
+		req.SetBasicAuth(username.(string), d.Get("password_dl").(string))
+	}
+
+	resp, err := c.HTTPClient(d.Timeout(schema.TimeoutCreate)).Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot download %s to verify checksum: %w", imageURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot download %s to verify checksum: %s", imageURL, resp.Status)
+	}
+
+	return utilityImageVerifyChecksum(ctx, resp.Body, checksum, imageURL)
+}
+
+// utilityImageSourceAddress returns the address the controller can reach this host by.
+// Unless set explicitly, it is the local address of the route to the controller.
+func utilityImageSourceAddress(c *controller.ControllerCfg, address string) (string, error) {
+	if address != "" {
+		return address, nil
+	}
+
+	controllerURL, err := url.Parse(c.GetControllerURL())
+	if err != nil {
+		return "", err
+	}
+	port := controllerURL.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	// no packets are sent, dialing UDP only selects the route
+	conn, err := net.Dial("udp", net.JoinHostPort(controllerURL.Hostname(), port))
+	if err != nil {
+		return "", fmt.Errorf("cannot detect address to serve source_file from, set source_file_address: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
+
+// utilityImageServeFile serves the file over HTTP for the controller to download it
+// and returns its URL. The URL contains random token, only the file is served.
+// The server must be stopped by calling the returned function.
+func utilityImageServeFile(ctx context.Context, c *controller.ControllerCfg, path string, listen string, address string) (string, func(), error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if info.IsDir() {
+		return "", nil, fmt.Errorf("source_file %s is a directory", path)
+	}
+
+	address, err = utilityImageSourceAddress(c, address)
+	if err != nil {
+		return "", nil, err
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot listen on %s to serve source_file: %w", listen, err)
+	}
+
+	filePath := "/" + uuid.New().String() + "/" + url.PathEscape(filepath.Base(path))
+	mux := http.NewServeMux()
+	mux.HandleFunc(filePath, func(w http.ResponseWriter, r *http.Request) {
+		logger.Debugf(ctx, "utilityImageServeFile: %s requested %s by %s", r.RemoteAddr, path, r.Method)
+		f, err := os.Open(path)
+		if err != nil {
+			http.Error(w, "file is not available", http.StatusInternalServerError)
+			return
+		}
+		defer f.Close()
+		http.ServeContent(w, r, filepath.Base(path), info.ModTime(), f)
+	})
+
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Errorf(ctx, "utilityImageServeFile: serving %s failed: %v", path, err)
+		}
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	fileURL := "http://" + net.JoinHostPort(address, strconv.Itoa(port)) + filePath
+	logger.Debugf(ctx, "utilityImageServeFile: serving %s at %s", path, fileURL)
+
+	stop := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(ctx) //nolint:errcheck
+	}
+	return fileURL, stop, nil
+}
+
+// utilityImageParseCreateResp extracts ID of the new image from image/create response,
+// which is either the ID itself or an array with the ID as the last number
+func utilityImageParseCreateResp(resp string) (int, error) {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(resp), &parsed); err != nil {
+		return 0, fmt.Errorf("cannot parse image/create response %q: %w", resp, err)
+	}
+
+	switch v := parsed.(type) {
+	case float64:
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	case []interface{}:
+		for i := len(v) - 1; i >= 0; i-- {
+			if id, ok := v[i].(float64); ok {
+				return int(id), nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("image/create response %q contains no image ID", resp)
+}
+
+// utilityImageFailureReason returns what the controller reports about the last operation on
+// the image: the error or the last log message of its task. The reason only complements the
+// failed status, so an empty string is returned if the task cannot be loaded.
+func utilityImageFailureReason(ctx context.Context, m interface{}, img *ImageExtend) string {
+	if len(img.History) == 0 {
+		return ""
+	}
+	last := img.History[0]
+	for _, item := range img.History[1:] {
+		if item.Timestamp > last.Timestamp {
+			last = item
+		}
+	}
+
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("auditId", last.Guid)
+	resp, err := c.DecortAPICall(ctx, "POST", imageTaskGetAPI, urlValues)
+	if err != nil {
+		logger.Debugf(ctx, "utilityImageFailureReason: cannot get task %s of image %d: %v", last.Guid, img.Id, err)
+		return ""
+	}
+
+	task := ImageTask{}
+	if err := json.Unmarshal([]byte(resp), &task); err != nil {
+		logger.Debugf(ctx, "utilityImageFailureReason: cannot unmarshal task %s of image %d: %v", last.Guid, img.Id, err)
+		return ""
+	}
+	if task.Error != "" {
+		return task.Error
+	}
+	if len(task.Log) != 0 {
+		return task.Log[len(task.Log)-1]
+	}
+	return ""
+}
+
+// utilityImageWaitReady waits until the image leaves DOWNLOADING/CREATING statuses
+func utilityImageWaitReady(ctx context.Context, m interface{}, imageId int) error {
+	ctx = controller.WithoutCache(ctx)
+	for {
+		img, err := utilityImageGet(ctx, m, imageId)
+		if err != nil {
+			return err
+		}
+
+		if imageFailedStatuses[img.Status] {
+			if reason := utilityImageFailureReason(ctx, m, img); reason != "" {
+				return fmt.Errorf("image %d failed to be created: status %s, tech status %s: %s", img.Id, img.Status, img.TechStatus, reason)
+			}
+			return fmt.Errorf("image %d failed to be created: status %s, tech status %s", img.Id, img.Status, img.TechStatus)
+		}
+		if !imagePendingStatuses[img.Status] {
+			logger.Debugf(ctx, "utilityImageWaitReady: image %d is %s, tech status %s", img.Id, img.Status, img.TechStatus)
+			return nil
+		}
+
+		logger.Infof(ctx, "utilityImageWaitReady: image %d is %s, size %d", img.Id, img.Status, img.Size)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("image %d is still %s: %w", img.Id, img.Status, ctx.Err())
+		case <-time.After(time.Second * 10):
+		}
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+// digests of "test"
+const (
+	testSHA256 = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	testMD5    = "098f6bcd4621d373cade4e832627b4f6"
+)
+
+func TestParseImageChecksum(t *testing.T) {
+	tests := []struct {
+		checksum      string
+		wantAlgorithm string
+		wantDigest    string
+		wantErr       bool
+	}{
+		{checksum: "sha256:" + testSHA256, wantAlgorithm: "sha256", wantDigest: testSHA256},
+		{checksum: "md5:" + testMD5, wantAlgorithm: "md5", wantDigest: testMD5},
+		{checksum: "SHA256:" + strings.ToUpper(testSHA256), wantAlgorithm: "sha256", wantDigest: testSHA256},
+		{checksum: testSHA256, wantErr: true},
+		{checksum: "sha1:a94a8fe5ccb19ba61c4c0873d391e987982fbbd3", wantErr: true},
+		{checksum: "sha256:" + testMD5, wantErr: true},
+		{checksum: "md5:" + testSHA256, wantErr: true},
+		{checksum: "sha256:" + testSHA256[:63] + "z", wantErr: true},
+		{checksum: "sha256:", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.checksum, func(t *testing.T) {
+			algorithm, digest, err := parseImageChecksum(tc.checksum)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseImageChecksum() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if algorithm != tc.wantAlgorithm || digest != tc.wantDigest {
+				t.Errorf("parseImageChecksum() = %q, %q, want %q, %q", algorithm, digest, tc.wantAlgorithm, tc.wantDigest)
+			}
+		})
+	}
+}
+
+func TestUtilityImageVerifyChecksum(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		checksum string
+		wantErr  bool
+	}{
+		{name: "sha256", content: "test", checksum: "sha256:" + testSHA256},
+		{name: "md5", content: "test", checksum: "md5:" + testMD5},
+		{name: "sha256 mismatch", content: "test2", checksum: "sha256:" + testSHA256, wantErr: true},
+		{name: "md5 mismatch", content: "", checksum: "md5:" + testMD5, wantErr: true},
+		{name: "invalid checksum", content: "test", checksum: "test", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := utilityImageVerifyChecksum(context.Background(), strings.NewReader(tc.content), tc.checksum, "test.qcow2")
+			if (err != nil) != tc.wantErr {
+				t.Errorf("utilityImageVerifyChecksum() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// testControllerConfigure configures the controller the way the provider does, against the stub
+func testControllerConfigure(t *testing.T, handler http.Handler, allowUnverifiedSSL bool) *controller.ControllerCfg {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	providerSchema := map[string]*schema.Schema{
+		"default_tags": {Type: schema.TypeMap, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+	}
+	for _, key := range []string{"authenticator", "controller_url", "jwt", "oauth2_url", "user", "password",
+		"app_id", "app_secret", "trace_file", "name_prefix", "name_pattern", "quota_check"} {
+		providerSchema[key] = &schema.Schema{Type: schema.TypeString, Optional: true}
+	}
+	providerSchema["cache_ttl"] = &schema.Schema{Type: schema.TypeInt, Optional: true}
+	providerSchema["allow_unverified_ssl"] = &schema.Schema{Type: schema.TypeBool, Optional: true}
+
+	d := schema.TestResourceDataRaw(t, providerSchema, map[string]interface{}{
+		"authenticator":        "jwt",
+		"controller_url":       srv.URL,
+		"jwt":                  "jwt",
+		"oauth2_url":           srv.URL,
+		"allow_unverified_ssl": allowUnverifiedSSL,
+	})
+	c, err := controller.ControllerConfigure(context.Background(), d)
+	if err != nil {
+		t.Fatalf("ControllerConfigure() error = %v", err)
+	}
+	return c
+}
+
+func TestUtilityImageVerifyURLChecksum(t *testing.T) {
+	image := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "test")
+	}))
+	defer image.Close()
+
+	tests := []struct {
+		name               string
+		allowUnverifiedSSL bool
+		wantErr            bool
+	}{
+		{name: "allow_unverified_ssl", allowUnverifiedSSL: true},
+		{name: "self-signed certificate", allowUnverifiedSSL: false, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := testControllerConfigure(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "[]")
+			}), tc.allowUnverifiedSSL)
+			d := schema.TestResourceDataRaw(t, resourceImageSchemaMake(dataSourceImageExtendSchemaMake()), map[string]interface{}{"url": image.URL + "/test.qcow2"})
+
+			err := utilityImageVerifyURLChecksum(context.Background(), c, d, "sha256:"+testSHA256)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("utilityImageVerifyURLChecksum() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUtilityImageWaitReadyFailureReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		task    interface{}
+		wantErr string
+	}{
+		{
+			name:    "task error",
+			task:    map[string]interface{}{"completed": true, "error": "cannot download image: 404 Not Found", "log": []string{"downloading"}},
+			wantErr: "image 7 failed to be created: status DESTROYED, tech status FAILED: cannot download image: 404 Not Found",
+		},
+		{
+			name:    "last log message",
+			task:    map[string]interface{}{"completed": true, "log": []string{"downloading", "image is corrupted"}},
+			wantErr: "image 7 failed to be created: status DESTROYED, tech status FAILED: image is corrupted",
+		},
+		{
+			name:    "task not found",
+			wantErr: "image 7 failed to be created: status DESTROYED, tech status FAILED",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := testControllerConfigure(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm() //nolint:errcheck
+				switch r.URL.Path {
+				case imageGetAPI:
+					json.NewEncoder(w).Encode(map[string]interface{}{
+						"id":         7,
+						"status":     "DESTROYED",
+						"techStatus": "FAILED",
+						"history":    []map[string]interface{}{{"guid": "new", "timestamp": 200}, {"guid": "old", "timestamp": 100}},
+					})
+				case imageTaskGetAPI:
+					if tc.task == nil || r.Form.Get("auditId") != "new" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					json.NewEncoder(w).Encode(tc.task)
+				default:
+					fmt.Fprint(w, "[]")
+				}
+			}), false)
+
+			err := utilityImageWaitReady(context.Background(), c, 7)
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("utilityImageWaitReady() error = %v, want %s", err, tc.wantErr)
+			}
+		})
+	}
+}
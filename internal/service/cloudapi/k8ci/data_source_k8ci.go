@@ -0,0 +1,192 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8ci
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/constants"
+)
+
+func flattenK8CI(k8ci *K8CIRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"k8ci_id":          k8ci.ID,
+		"name":             k8ci.Name,
+		"version":          k8ci.Version,
+		"desc":             k8ci.Description,
+		"status":           k8ci.Status,
+		"gid":              k8ci.GID,
+		"guid":             k8ci.GUID,
+		"master_driver":    k8ci.MasterDriver,
+		"master_image_id":  k8ci.MasterImageID,
+		"worker_driver":    k8ci.WorkerDriver,
+		"worker_image_id":  k8ci.WorkerImageID,
+		"lb_image_id":      k8ci.LBImageID,
+		"max_master_count": k8ci.MaxMasterCount,
+		"max_worker_count": k8ci.MaxWorkerCount,
+		"shared_with":      k8ci.SharedWith,
+	}
+}
+
+func dataSourceK8CIRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	k8ci, err := utilityK8CICheckPresence(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(k8ci.ID))
+	for key, value := range flattenK8CI(k8ci) {
+		d.Set(key, value)
+	}
+
+	return nil
+}
+
+// k8ciSchemaMake returns attributes of a catalog item, computed in the items of decort_k8ci_list
+// and in decort_k8ci, which also takes k8ci_id or version
+func k8ciSchemaMake() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"k8ci_id": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"name": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"version": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Kubernetes version",
+		},
+		"desc": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"status": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"gid": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"guid": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"master_driver": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"master_image_id": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "ID of the OS image of master nodes",
+		},
+		"worker_driver": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"worker_image_id": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "ID of the OS image of worker nodes",
+		},
+		"lb_image_id": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "ID of the OS image of the load balancer",
+		},
+		"max_master_count": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"max_worker_count": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"shared_with": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeInt,
+			},
+		},
+	}
+}
+
+func dataSourceK8CISchemaMake() map[string]*schema.Schema {
+	res := k8ciSchemaMake()
+
+	res["k8ci_id"] = &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"k8ci_id", "version"},
+		Description:  "ID of the K8s catalog item",
+	}
+	res["version"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"k8ci_id", "version"},
+		Description:  "Kubernetes version to look the catalog item up by, with or without the v prefix",
+	}
+	res["include_disabled"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "look up by version among disabled catalog items too",
+	}
+
+	return res
+}
+
+func DataSourceK8CI() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+
+		ReadContext: dataSourceK8CIRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read:    &constants.Timeout30s,
+			Default: &constants.Timeout60s,
+		},
+
+		Schema: dataSourceK8CISchemaMake(),
+	}
+}
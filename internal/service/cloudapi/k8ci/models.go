@@ -0,0 +1,55 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8ci
+
+// K8CIRecord is a K8s catalog item: a Kubernetes version with the images and drivers
+// of the nodes clusters of this version are created from
+type K8CIRecord struct {
+	Description    string `json:"desc"`
+	GID            int    `json:"gid"`
+	GUID           int    `json:"guid"`
+	ID             int    `json:"id"`
+	LBImageID      int    `json:"lbImageId"`
+	MasterDriver   string `json:"masterDriver"`
+	MasterImageID  int    `json:"masterImageId"`
+	MaxMasterCount int    `json:"maxMasterCount"`
+	MaxWorkerCount int    `json:"maxWorkerCount"`
+	Name           string `json:"name"`
+	SharedWith     []int  `json:"sharedWith"`
+	Status         string `json:"status"`
+	Version        string `json:"version"`
+	WorkerDriver   string `json:"workerDriver"`
+	WorkerImageID  int    `json:"workerImageId"`
+}
+
+type K8CIList []K8CIRecord
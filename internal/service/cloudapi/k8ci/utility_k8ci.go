@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8ci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+// K8CIGet loads the K8s catalog item
+func K8CIGet(ctx context.Context, m interface{}, k8ciId int) (*K8CIRecord, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("k8ciId", strconv.Itoa(k8ciId))
+
+	logger.Debugf(ctx, "K8CIGet: load k8ci ID %d", k8ciId)
+	k8ciRaw, err := c.DecortAPICall(ctx, "POST", K8CIGetAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	k8ci := &K8CIRecord{}
+	if err := json.Unmarshal([]byte(k8ciRaw), k8ci); err != nil {
+		return nil, err
+	}
+
+	return k8ci, nil
+}
+
+// parseVersion parses Kubernetes version like v1.22.3 or 1.22 into major, minor and patch numbers
+func parseVersion(version string) ([3]int, error) {
+	res := [3]int{}
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return res, fmt.Errorf("cannot parse version %q", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return res, fmt.Errorf("cannot parse version %q", version)
+		}
+		res[i] = n
+	}
+	return res, nil
+}
+
+// CheckUpgrade verifies a cluster of the catalog item from can be upgraded to the catalog item to.
+// Kubernetes supports upgrades to the next minor version only, so skipping a minor version,
+// downgrades and changes of the major version are rejected.
+func CheckUpgrade(from, to *K8CIRecord) error {
+	if to.Status != "ENABLED" {
+		return fmt.Errorf("k8ci %s (ID %d) is %s, cannot upgrade to it", to.Name, to.ID, to.Status)
+	}
+	if from.GID != to.GID {
+		return fmt.Errorf("k8ci %s (ID %d) is in grid %d, the cluster is in grid %d", to.Name, to.ID, to.GID, from.GID)
+	}
+
+	fromVersion, err := parseVersion(from.Version)
+	if err != nil {
+		return err
+	}
+	toVersion, err := parseVersion(to.Version)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case fromVersion[0] != toVersion[0]:
+		return fmt.Errorf("upgrade from version %s to %s changes the major version, which is not supported", from.Version, to.Version)
+	case toVersion[1] < fromVersion[1] || toVersion[1] == fromVersion[1] && toVersion[2] < fromVersion[2]:
+		return fmt.Errorf("version %s of k8ci %s (ID %d) is older than the cluster version %s, downgrades are not supported", to.Version, to.Name, to.ID, from.Version)
+	case toVersion[1] > fromVersion[1]+1:
+		return fmt.Errorf("upgrade from version %s to %s skips a minor version, upgrade to version %d.%d first",
+			from.Version, to.Version, fromVersion[0], fromVersion[1]+1)
+	}
+
+	return nil
+}
+
+func utilityK8CICheckPresence(ctx context.Context, d *schema.ResourceData, m interface{}) (*K8CIRecord, error) {
+	if k8ciId, ok := d.GetOk("k8ci_id"); ok {
+		return K8CIGet(ctx, m, k8ciId.(int))
+	}
+
+	version := d.Get("version").(string)
+	k8ciList, err := utilityK8CIListGet(ctx, m, d.Get("include_disabled").(bool))
+	if err != nil {
+		return nil, err
+	}
+
+	var found *K8CIRecord
+	for i, k8ci := range k8ciList {
+		if k8ci.Version != version && strings.TrimPrefix(k8ci.Version, "v") != strings.TrimPrefix(version, "v") {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("several k8ci have version %q: IDs %d and %d, use k8ci_id", version, found.ID, k8ci.ID)
+		}
+		found = &k8ciList[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("k8ci with version %q not found", version)
+	}
+
+	return found, nil
+}
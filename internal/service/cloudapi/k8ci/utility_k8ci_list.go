@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8ci
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+func utilityK8CIListGet(ctx context.Context, m interface{}, includeDisabled bool) (K8CIList, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("includeDisabled", strconv.FormatBool(includeDisabled))
+
+	logger.Debugf(ctx, "utilityK8CIListGet")
+	k8ciListRaw, err := c.DecortAPICall(ctx, "POST", K8CIListAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	k8ciList := K8CIList{}
+	if err := json.Unmarshal([]byte(k8ciListRaw), &k8ciList); err != nil {
+		return nil, err
+	}
+
+	return k8ciList, nil
+}
+
+func utilityK8CIListCheckPresence(ctx context.Context, d *schema.ResourceData, m interface{}) (K8CIList, error) {
+	k8ciList, err := utilityK8CIListGet(ctx, m, d.Get("include_disabled").(bool))
+	if err != nil {
+		return nil, err
+	}
+
+	res := K8CIList{}
+	for _, k8ci := range k8ciList {
+		if status, ok := d.GetOk("status"); ok && k8ci.Status != status.(string) {
+			continue
+		}
+		if gid, ok := d.GetOk("gid"); ok && k8ci.GID != gid.(int) {
+			continue
+		}
+		res = append(res, k8ci)
+	}
+
+	return res, nil
+}
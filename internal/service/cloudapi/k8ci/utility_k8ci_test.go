@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8ci
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    [3]int
+		wantErr bool
+	}{
+		{version: "v1.22.3", want: [3]int{1, 22, 3}},
+		{version: "1.22.3", want: [3]int{1, 22, 3}},
+		{version: "1.22", want: [3]int{1, 22, 0}},
+		{version: " v1.23.1 ", want: [3]int{1, 23, 1}},
+		{version: "1", wantErr: true},
+		{version: "1.22.3.4", wantErr: true},
+		{version: "v1.22.x", wantErr: true},
+		{version: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			got, err := parseVersion(tc.version)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseVersion() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && got != tc.want {
+				t.Errorf("parseVersion() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckUpgrade(t *testing.T) {
+	from := &K8CIRecord{ID: 1, Name: "k8s-1.22", GID: 212, Status: "ENABLED", Version: "v1.22.3"}
+
+	tests := []struct {
+		name    string
+		to      K8CIRecord
+		wantErr bool
+	}{
+		{name: "patch", to: K8CIRecord{ID: 2, GID: 212, Status: "ENABLED", Version: "v1.22.5"}},
+		{name: "next minor", to: K8CIRecord{ID: 2, GID: 212, Status: "ENABLED", Version: "v1.23.1"}},
+		{name: "next minor without patch", to: K8CIRecord{ID: 2, GID: 212, Status: "ENABLED", Version: "1.23"}},
+		{name: "same version", to: K8CIRecord{ID: 2, GID: 212, Status: "ENABLED", Version: "v1.22.3"}},
+		{name: "skips minor", to: K8CIRecord{ID: 2, GID: 212, Status: "ENABLED", Version: "v1.24.0"}, wantErr: true},
+		{name: "older patch", to: K8CIRecord{ID: 2, GID: 212, Status: "ENABLED", Version: "v1.22.1"}, wantErr: true},
+		{name: "older minor", to: K8CIRecord{ID: 2, GID: 212, Status: "ENABLED", Version: "v1.21.9"}, wantErr: true},
+		{name: "major", to: K8CIRecord{ID: 2, GID: 212, Status: "ENABLED", Version: "v2.0.0"}, wantErr: true},
+		{name: "disabled", to: K8CIRecord{ID: 2, GID: 212, Status: "DISABLED", Version: "v1.23.1"}, wantErr: true},
+		{name: "other grid", to: K8CIRecord{ID: 2, GID: 213, Status: "ENABLED", Version: "v1.23.1"}, wantErr: true},
+		{name: "invalid version", to: K8CIRecord{ID: 2, GID: 212, Status: "ENABLED", Version: "latest"}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := CheckUpgrade(from, &tc.to); (err != nil) != tc.wantErr {
+				t.Errorf("CheckUpgrade() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
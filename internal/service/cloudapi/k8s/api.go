@@ -39,9 +39,11 @@ const (
 	K8sDeleteAPI      = "/restmachine/cloudapi/k8s/delete"
 	K8sListAPI        = "/restmachine/cloudapi/k8s/list"
 	K8sListDeletedAPI = "/restmachine/cloudapi/k8s/listDeleted"
+	K8sUpgradeAPI     = "/restmachine/cloudapi/k8s/upgrade"
 
 	K8sWgCreateAPI = "/restmachine/cloudapi/k8s/workersGroupAdd"
 	K8sWgDeleteAPI = "/restmachine/cloudapi/k8s/workersGroupDelete"
+	K8sWgUpdateAPI = "/restmachine/cloudapi/k8s/workersGroupUpdate"
 
 	K8sWorkerAddAPI    = "/restmachine/cloudapi/k8s/workerAdd"
 	K8sWorkerDeleteAPI = "/restmachine/cloudapi/k8s/deleteWorkerFromGroup"
@@ -50,5 +52,7 @@ const (
 
 	LbGetAPI = "/restmachine/cloudapi/lb/get"
 
+	ComputeRebootAPI = "/restmachine/cloudapi/compute/reboot"
+
 	AsyncTaskGetAPI = "/restmachine/cloudapi/tasks/get"
 )
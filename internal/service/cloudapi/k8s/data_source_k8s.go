@@ -34,17 +34,11 @@ package k8s
 
 import (
 	"context"
-	"encoding/json"
-	"net/url"
 	"strconv"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
-	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
-
-	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/kvmvm"
 )
 
 func dataSourceK8sRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -54,61 +48,23 @@ func dataSourceK8sRead(ctx context.Context, d *schema.ResourceData, m interface{
 	}
 	d.SetId(strconv.FormatUint(k8s.ID, 10))
 
-	k8sList, err := utilityK8sListCheckPresence(ctx, d, m, K8sListAPI)
+	d.Set("vins_id", k8s.VINSID)
+
+	masterComputeList, workersComputeList, err := utilityK8sNodesComputesGet(ctx, m, k8s)
 	if err != nil {
-		d.SetId("")
 		return diag.FromErr(err)
 	}
-	curK8s := K8SItem{}
-	for _, k8sCluster := range k8sList {
-		if k8sCluster.ID == k8s.ID {
-			curK8s = k8sCluster
-		}
-	}
-	if curK8s.ID == 0 {
-		return diag.Errorf("Cluster with id %d not found in List clusters", k8s.ID)
-	}
-	d.Set("vins_id", curK8s.VINSID)
-
-	masterComputeList := make([]kvmvm.ComputeGetResp, 0, len(k8s.K8SGroups.Masters.DetailedInfo))
-	workersComputeList := make([]kvmvm.ComputeGetResp, 0, len(k8s.K8SGroups.Workers))
-	for _, masterNode := range k8s.K8SGroups.Masters.DetailedInfo {
-		compute, err := utilityComputeCheckPresence(ctx, d, m, masterNode.ID)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		masterComputeList = append(masterComputeList, *compute)
-	}
-	for _, worker := range k8s.K8SGroups.Workers {
-		for _, info := range worker.DetailedInfo {
-			compute, err := utilityComputeCheckPresence(ctx, d, m, info.ID)
-			if err != nil {
-				return diag.FromErr(err)
-			}
-			workersComputeList = append(workersComputeList, *compute)
-		}
-	}
 
-	c := m.(*controller.ControllerCfg)
-	urlValues := &url.Values{}
-	urlValues.Add("k8sId", d.Id())
-	kubeconfig, err := c.DecortAPICall(ctx, "POST", K8sGetConfigAPI, urlValues)
+	kubeconfig, err := utilityK8sKubeconfigGet(ctx, m, d.Id())
 	if err != nil {
-		log.Warnf("could not get kubeconfig: %v", err)
+		logger.Warnf(ctx, "could not get kubeconfig: %v", err)
 	}
 	d.Set("kubeconfig", kubeconfig)
 
-	urlValues = &url.Values{}
-	urlValues.Add("lbId", strconv.FormatUint(k8s.LBID, 10))
-	resp, err := c.DecortAPICall(ctx, "POST", LbGetAPI, urlValues)
+	lb, err := utilityK8sLbGet(ctx, m, k8s.LBID)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-
-	var lb LbRecord
-	if err := json.Unmarshal([]byte(resp), &lb); err != nil {
-		return diag.FromErr(err)
-	}
 	d.Set("extnet_id", lb.ExtNetID)
 	d.Set("lb_ip", lb.PrimaryNode.FrontendIP)
 
@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8s
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/rudecs/terraform-provider-decort/internal/constants"
+)
+
+func dataSourceK8sNodesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	logger.Debugf(ctx, "dataSourceK8sNodesRead: called with k8s id %d", d.Get("k8s_id").(int))
+
+	k8s, err := utilityDataK8sCheckPresence(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	nodes, err := utilityK8sNodesInfo(ctx, m, k8s)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	lbIP := ""
+	if k8s.LBID != 0 {
+		lb, err := utilityK8sLbGet(ctx, m, k8s.LBID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		lbIP = lb.PrimaryNode.FrontendIP
+	}
+
+	inventory := ""
+	if d.Get("format").(string) == "ansible_inventory" {
+		inventory, err = k8sAnsibleInventory(nodes, lbIP, d.Get("inventory_format").(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId(strconv.FormatUint(k8s.ID, 10))
+	d.Set("nodes", flattenK8sNodes(nodes))
+	d.Set("lb_ip", lbIP)
+	d.Set("inventory", inventory)
+
+	return nil
+}
+
+func dataSourceK8sNodesSchemaMake() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"k8s_id": {
+			Type:        schema.TypeInt,
+			Required:    true,
+			Description: "ID of k8s instance.",
+		},
+		"format": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice([]string{"ansible_inventory"}, false),
+			Description:  "Set to ansible_inventory to render the inventory attribute.",
+		},
+		"inventory_format": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "ini",
+			ValidateFunc: validation.StringInSlice([]string{"ini", "yaml"}, false),
+			Description:  "Format of the Ansible inventory: ini or yaml.",
+		},
+		"nodes": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: k8sNodesSchemaMake(),
+			},
+			Description: "Master and worker nodes of the cluster sorted by name.",
+		},
+		"lb_ip": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Frontend IP address of the load balancer of the cluster, empty if the cluster has no load balancer.",
+		},
+		"inventory": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Ansible inventory with masters and workers groups, workers are grouped by worker group.",
+		},
+	}
+}
+
+func DataSourceK8sNodes() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+
+		ReadContext: dataSourceK8sNodesRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read:    &constants.Timeout60s,
+			Default: &constants.Timeout60s,
+		},
+
+		Schema: dataSourceK8sNodesSchemaMake(),
+	}
+}
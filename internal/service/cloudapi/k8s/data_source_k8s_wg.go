@@ -39,12 +39,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
-	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/kvmvm"
-	log "github.com/sirupsen/logrus"
 )
 
 func dataSourceK8sWgRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("dataSourceK8sWgRead: called with k8s id %d", d.Get("k8s_id").(int))
+	logger.Debugf(ctx, "dataSourceK8sWgRead: called with k8s id %d", d.Get("k8s_id").(int))
 
 	k8s, err := utilityDataK8sCheckPresence(ctx, d, m)
 	if err != nil {
@@ -73,13 +71,9 @@ func dataSourceK8sWgRead(ctx context.Context, d *schema.ResourceData, m interfac
 		return diag.Errorf("Not found wg with id: %v in k8s cluster: %v", id, k8s.ID)
 	}
 
-	workersComputeList := make([]kvmvm.ComputeGetResp, 0, 0)
-	for _, info := range curWg.DetailedInfo {
-		compute, err := utilityComputeCheckPresence(ctx, d, m, info.ID)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		workersComputeList = append(workersComputeList, *compute)
+	workersComputeList, err := utilityK8sWgComputesGet(ctx, m, curWg)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
 	flattenWgData(d, curWg, workersComputeList)
@@ -78,14 +78,11 @@ func dataSourceK8sWgListRead(ctx context.Context, d *schema.ResourceData, m inte
 
 	workersComputeList := make(map[uint64][]kvmvm.ComputeGetResp)
 	for _, worker := range wgList {
-		workersComputeList[worker.ID] = make([]kvmvm.ComputeGetResp, 0, len(worker.DetailedInfo))
-		for _, info := range worker.DetailedInfo {
-			compute, err := utilityComputeCheckPresence(ctx, d, m, info.ID)
-			if err != nil {
-				return diag.FromErr(err)
-			}
-			workersComputeList[worker.ID] = append(workersComputeList[worker.ID], *compute)
+		computes, err := utilityK8sWgComputesGet(ctx, m, worker)
+		if err != nil {
+			return diag.FromErr(err)
 		}
+		workersComputeList[worker.ID] = computes
 	}
 	flattenItemsWg(d, wgList, workersComputeList)
 	return nil
@@ -317,3 +317,22 @@ func flattenWgList(wgList K8SGroupList, computesMap map[uint64][]kvmvm.ComputeGe
 func flattenItemsWg(d *schema.ResourceData, wgList K8SGroupList, computes map[uint64][]kvmvm.ComputeGetResp) {
 	d.Set("items", flattenWgList(wgList, computes))
 }
+
+func flattenK8sNodes(nodes []k8sNodeInfo) []map[string]interface{} {
+	res := make([]map[string]interface{}, 0, len(nodes))
+	for _, node := range nodes {
+		temp := map[string]interface{}{
+			"name":        node.Name,
+			"role":        node.Role,
+			"group":       node.Group,
+			"group_id":    node.GroupID,
+			"compute_id":  node.ComputeID,
+			"internal_ip": node.InternalIP,
+			"external_ip": node.ExternalIP,
+			"status":      node.Status,
+			"tech_status": node.TechStatus,
+		}
+		res = append(res, temp)
+	}
+	return res
+}
@@ -183,6 +183,7 @@ type K8SRecord struct {
 	TechStatus  string    `json:"techStatus"`
 	UpdatedBy   string    `json:"updatedBy"`
 	UpdatedTime uint64    `json:"updatedTime"`
+	VINSID      uint64    `json:"vinsId"`
 }
 
 type K8SRecordList []K8SRecord
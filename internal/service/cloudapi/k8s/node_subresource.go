@@ -178,3 +178,51 @@ func workersSchemaMake() map[string]*schema.Schema {
 	}
 	return workers
 }
+
+func k8sNodesSchemaMake() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Node name.",
+		},
+		"role": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Node role: master or worker.",
+		},
+		"group": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Name of the masters or workers group of the node.",
+		},
+		"group_id": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "ID of the masters or workers group of the node.",
+		},
+		"compute_id": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "Compute ID of the node.",
+		},
+		"internal_ip": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "IP address of the node in the ViNS of the cluster.",
+		},
+		"external_ip": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "IP address of the node in an external network, empty if the node is not connected to one.",
+		},
+		"status": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"tech_status": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+}
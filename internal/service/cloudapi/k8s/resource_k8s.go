@@ -34,23 +34,21 @@ package k8s
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/url"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/kvmvm"
-	log "github.com/sirupsen/logrus"
+	"github.com/rudecs/terraform-provider-decort/internal/quota"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/k8ci"
 )
 
 func resourceK8sCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sCreate: called with name %s, rg %d", d.Get("name").(string), d.Get("rg_id").(int))
+	logger.Debugf(ctx, "resourceK8sCreate: called with name %s, rg %d", d.Get("name").(string), d.Get("rg_id").(int))
+	defer k8sCacheInvalidate(m)
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -127,33 +125,13 @@ func resourceK8sCreate(ctx context.Context, d *schema.ResourceData, m interface{
 		return diag.FromErr(err)
 	}
 
-	urlValues = &url.Values{}
-	urlValues.Add("auditId", strings.Trim(resp, `"`))
-
-	for {
-		resp, err := c.DecortAPICall(ctx, "POST", AsyncTaskGetAPI, urlValues)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-
-		task := AsyncTask{}
-		if err := json.Unmarshal([]byte(resp), &task); err != nil {
-			return diag.FromErr(err)
-		}
-		log.Debugf("resourceK8sCreate: instance creating - %s", task.Stage)
-
-		if task.Completed {
-			if task.Error != "" {
-				return diag.FromErr(fmt.Errorf("cannot create k8s instance: %v", task.Error))
-			}
-
-			d.SetId(strconv.Itoa(int(task.Result)))
-			break
-		}
-
-		time.Sleep(time.Second * 10)
+	task, err := utilityK8sWaitTask(ctx, m, resp, "create")
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	d.SetId(strconv.Itoa(int(task.Result)))
 
+	k8sCacheInvalidate(m)
 	return resourceK8sRead(ctx, d, m)
 }
 
@@ -164,64 +142,26 @@ func resourceK8sRead(ctx context.Context, d *schema.ResourceData, m interface{})
 		d.SetId("")
 		return diag.FromErr(err)
 	}
-	k8sList, err := utilityK8sListCheckPresence(ctx, d, m, K8sListAPI)
+	d.Set("vins_id", k8s.VINSID)
+
+	masterComputeList, workersComputeList, err := utilityK8sNodesComputesGet(ctx, m, k8s)
 	if err != nil {
-		d.SetId("")
 		return diag.FromErr(err)
 	}
-	curK8s := K8SItem{}
-	for _, k8sCluster := range k8sList {
-		if k8sCluster.ID == k8s.ID {
-			curK8s = k8sCluster
-		}
-	}
-	if curK8s.ID == 0 {
-		return diag.Errorf("Cluster with id %d not found", k8s.ID)
-	}
-	d.Set("vins_id", curK8s.VINSID)
-
-	masterComputeList := make([]kvmvm.ComputeGetResp, 0, len(k8s.K8SGroups.Masters.DetailedInfo))
-	workersComputeList := make([]kvmvm.ComputeGetResp, 0, len(k8s.K8SGroups.Workers))
-	for _, masterNode := range k8s.K8SGroups.Masters.DetailedInfo {
-		compute, err := utilityComputeCheckPresence(ctx, d, m, masterNode.ID)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		masterComputeList = append(masterComputeList, *compute)
-	}
-	for _, worker := range k8s.K8SGroups.Workers {
-		for _, info := range worker.DetailedInfo {
-			compute, err := utilityComputeCheckPresence(ctx, d, m, info.ID)
-			if err != nil {
-				return diag.FromErr(err)
-			}
-			workersComputeList = append(workersComputeList, *compute)
-		}
-	}
 
 	flattenResourceK8s(d, *k8s, masterComputeList, workersComputeList)
+	d.Set("nodes", flattenK8sNodes(k8sNodesInfo(k8s, masterComputeList, workersComputeList)))
 
-	c := m.(*controller.ControllerCfg)
-	urlValues := &url.Values{}
-	urlValues.Add("lbId", strconv.FormatUint(k8s.LBID, 10))
-
-	resp, err := c.DecortAPICall(ctx, "POST", LbGetAPI, urlValues)
+	lb, err := utilityK8sLbGet(ctx, m, k8s.LBID)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-
-	var lb LbRecord
-	if err := json.Unmarshal([]byte(resp), &lb); err != nil {
-		return diag.FromErr(err)
-	}
 	d.Set("extnet_id", lb.ExtNetID)
 	d.Set("lb_ip", lb.PrimaryNode.FrontendIP)
 
-	urlValues = &url.Values{}
-	urlValues.Add("k8sId", d.Id())
-	kubeconfig, err := c.DecortAPICall(ctx, "POST", K8sGetConfigAPI, urlValues)
+	kubeconfig, err := utilityK8sKubeconfigGet(ctx, m, d.Id())
 	if err != nil {
-		log.Warnf("could not get kubeconfig: %v", err)
+		logger.Warnf(ctx, "could not get kubeconfig: %v", err)
 	}
 	d.Set("kubeconfig", kubeconfig)
 
@@ -229,7 +169,8 @@ func resourceK8sRead(ctx context.Context, d *schema.ResourceData, m interface{})
 }
 
 func resourceK8sUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sUpdate: called with id %s, rg %d", d.Id(), d.Get("rg_id").(int))
+	logger.Debugf(ctx, "resourceK8sUpdate: called with id %s, rg %d", d.Id(), d.Get("rg_id").(int))
+	defer k8sCacheInvalidate(m)
 
 	c := m.(*controller.ControllerCfg)
 
@@ -244,6 +185,21 @@ func resourceK8sUpdate(ctx context.Context, d *schema.ResourceData, m interface{
 		}
 	}
 
+	if d.HasChange("k8sci_id") {
+		// the platform upgrades the control plane first and then the worker groups
+		urlValues := &url.Values{}
+		urlValues.Add("k8sId", d.Id())
+		urlValues.Add("k8ciId", strconv.Itoa(d.Get("k8sci_id").(int)))
+
+		resp, err := c.DecortAPICall(ctx, "POST", K8sUpgradeAPI, urlValues)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if _, err := utilityK8sWaitTask(ctx, m, resp, "upgrade"); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	if d.HasChange("workers") {
 		k8s, err := utilityK8sCheckPresence(ctx, d, m)
 		if err != nil {
@@ -272,11 +228,29 @@ func resourceK8sUpdate(ctx context.Context, d *schema.ResourceData, m interface{
 		}
 	}
 
-	return nil
+	if d.HasChange("restart_masters") {
+		if err := utilityK8sMastersRestart(ctx, d, m, k8sNodesAdded(d, "restart_masters")); err != nil {
+			// keep the old sets in the state, so the restart and the replacement that has not
+			// run yet are retried by the next apply
+			k8sNodesRollback(d, "restart_masters", "replace_nodes")
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("replace_nodes") {
+		if err := utilityK8sNodesReplace(ctx, d, m, k8sNodesAdded(d, "replace_nodes")); err != nil {
+			k8sNodesRollback(d, "replace_nodes")
+			return diag.FromErr(err)
+		}
+	}
+
+	k8sCacheInvalidate(m)
+	return resourceK8sRead(ctx, d, m)
 }
 
 func resourceK8sDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sDelete: called with id %s, rg %d", d.Id(), d.Get("rg_id").(int))
+	logger.Debugf(ctx, "resourceK8sDelete: called with id %s, rg %d", d.Id(), d.Get("rg_id").(int))
+	defer k8sCacheInvalidate(m)
 
 	k8s, err := utilityK8sCheckPresence(ctx, d, m)
 	if k8s == nil {
@@ -315,8 +289,7 @@ func resourceK8sSchemaMake() map[string]*schema.Schema {
 		"k8sci_id": {
 			Type:        schema.TypeInt,
 			Required:    true,
-			ForceNew:    true,
-			Description: "ID of the k8s catalog item to base this instance on.",
+			Description: "ID of the k8s catalog item to base this instance on. Changing it on an existing cluster upgrades the cluster to the next minor version, other changes are rejected at plan time.",
 		},
 		"wg_name": {
 			Type:        schema.TypeString,
@@ -366,6 +339,22 @@ func resourceK8sSchemaMake() map[string]*schema.Schema {
 			},
 			Description: "Worker node(s) configuration.",
 		},
+		"replace_nodes": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+			Description: "Compute IDs or names of worker nodes to repair. Each node added to the set is cordoned, deleted from its worker group and replaced with a new node.",
+		},
+		"restart_masters": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+			Description: "Compute IDs or names of master nodes to restart. Each node added to the set is restarted, one node at a time: the next node is restarted after the API server of the cluster reports the previous one booted again and ready, so the API server must be reachable from the host running Terraform.",
+		},
 		"with_lb": {
 			Type:        schema.TypeBool,
 			Optional:    true,
@@ -464,6 +453,14 @@ func resourceK8sSchemaMake() map[string]*schema.Schema {
 			Computed:    true,
 			Description: "Kubeconfig for cluster access.",
 		},
+		"nodes": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: k8sNodesSchemaMake(),
+			},
+			Description: "Master and worker nodes of the cluster sorted by name.",
+		},
 		"vins_id": {
 			Type:        schema.TypeInt,
 			Computed:    true,
@@ -472,15 +469,74 @@ func resourceK8sSchemaMake() map[string]*schema.Schema {
 	}
 }
 
+// k8sQuotaUsage returns resources of all nodes of masters or workers block
+func k8sQuotaUsage(nodesRaw interface{}) quota.Usage {
+	usage := quota.Usage{}
+	for _, nodeRaw := range nodesRaw.([]interface{}) {
+		node, ok := nodeRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		num := node["num"].(int)
+		usage.CPU += num * node["cpu"].(int)
+		usage.RAM += num * node["ram"].(int)
+		usage.Disk += num * node["disk"].(int)
+	}
+	return usage
+}
+
+// resourceK8sCustomizeDiff checks resources of the planned nodes, and the external IP address
+// of the load balancer of a new cluster, against the account and resource group limits.
+// A change of the catalog item of an existing cluster is checked to be a supported upgrade.
+func resourceK8sCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" && d.HasChange("k8sci_id") && d.NewValueKnown("k8sci_id") {
+		oldK8ciId, newK8ciId := d.GetChange("k8sci_id")
+		from, err := k8ci.K8CIGet(ctx, m, oldK8ciId.(int))
+		if err != nil {
+			return fmt.Errorf("cannot check upgrade of the cluster: %v", err)
+		}
+		to, err := k8ci.K8CIGet(ctx, m, newK8ciId.(int))
+		if err != nil {
+			return fmt.Errorf("cannot check upgrade of the cluster: %v", err)
+		}
+		if err := k8ci.CheckUpgrade(from, to); err != nil {
+			return fmt.Errorf("k8sci_id cannot be changed from %d to %d: %v", oldK8ciId.(int), newK8ciId.(int), err)
+		}
+	}
+
+	oldMasters, newMasters := d.GetChange("masters")
+	oldWorkers, newWorkers := d.GetChange("workers")
+	oldUsage := k8sQuotaUsage(oldMasters)
+	newUsage := k8sQuotaUsage(newMasters)
+	oldWorkersUsage, newWorkersUsage := k8sQuotaUsage(oldWorkers), k8sQuotaUsage(newWorkers)
+
+	delta := quota.Usage{
+		CPU:  newUsage.CPU + newWorkersUsage.CPU - oldUsage.CPU - oldWorkersUsage.CPU,
+		RAM:  newUsage.RAM + newWorkersUsage.RAM - oldUsage.RAM - oldWorkersUsage.RAM,
+		Disk: newUsage.Disk + newWorkersUsage.Disk - oldUsage.Disk - oldWorkersUsage.Disk,
+	}
+	if d.Id() == "" && d.Get("with_lb").(bool) {
+		delta.ExtIPs = 1
+	}
+
+	return quota.Check(ctx, m, k8sQuotaKey(d), 0, d.Get("rg_id").(int), delta)
+}
+
+func k8sQuotaKey(d quota.Resource) string {
+	return quota.Key("k8s", d, "rg_id", "name")
+}
+
 func ResourceK8s() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
 
-		CreateContext: resourceK8sCreate,
+		CreateContext: quota.WithWarnings(resourceK8sCreate, k8sQuotaKey),
 		ReadContext:   resourceK8sRead,
-		UpdateContext: resourceK8sUpdate,
+		UpdateContext: quota.WithWarnings(resourceK8sUpdate, k8sQuotaKey),
 		DeleteContext: resourceK8sDelete,
 
+		CustomizeDiff: resourceK8sCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -488,7 +544,7 @@ func ResourceK8s() *schema.Resource {
 		Timeouts: &schema.ResourceTimeout{
 			Create:  &constants.Timeout30m,
 			Read:    &constants.Timeout300s,
-			Update:  &constants.Timeout300s,
+			Update:  &constants.Timeout30m,
 			Delete:  &constants.Timeout300s,
 			Default: &constants.Timeout300s,
 		},
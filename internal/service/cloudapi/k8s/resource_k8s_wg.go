@@ -34,20 +34,21 @@ package k8s
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/kvmvm"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceK8sWgCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sWgCreate: called with k8s id %d", d.Get("k8s_id").(int))
+	logger.Debugf(ctx, "resourceK8sWgCreate: called with k8s id %d", d.Get("k8s_id").(int))
+	defer k8sCacheInvalidate(m)
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -57,6 +58,9 @@ func resourceK8sWgCreate(ctx context.Context, d *schema.ResourceData, m interfac
 	urlValues.Add("workerCpu", strconv.Itoa(d.Get("cpu").(int)))
 	urlValues.Add("workerRam", strconv.Itoa(d.Get("ram").(int)))
 	urlValues.Add("workerDisk", strconv.Itoa(d.Get("disk").(int)))
+	urlValues.Add("workerSepId", strconv.Itoa(d.Get("sep_id").(int)))
+	urlValues.Add("workerSepPool", d.Get("sep_pool").(string))
+	k8sWgMetaAdd(urlValues, d)
 
 	resp, err := c.DecortAPICall(ctx, "POST", K8sWgCreateAPI, urlValues)
 	if err != nil {
@@ -94,11 +98,12 @@ func resourceK8sWgCreate(ctx context.Context, d *schema.ResourceData, m interfac
 	//time.Sleep(time.Second * 5)
 	//}
 
+	k8sCacheInvalidate(m)
 	return resourceK8sWgRead(ctx, d, m)
 }
 
 func resourceK8sWgRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sWgRead: called with %v", d.Id())
+	logger.Debugf(ctx, "resourceK8sWgRead: called with %v", d.Id())
 
 	k8s, err := utilityDataK8sCheckPresence(ctx, d, m)
 	if err != nil {
@@ -126,25 +131,26 @@ func resourceK8sWgRead(ctx context.Context, d *schema.ResourceData, m interface{
 		return diag.Errorf("Not found wg with id: %v in k8s cluster: %v", id, k8s.ID)
 	}
 
-	workersComputeList := make([]kvmvm.ComputeGetResp, 0, 0)
-	for _, info := range curWg.DetailedInfo {
-		compute, err := utilityComputeCheckPresence(ctx, d, m, info.ID)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		workersComputeList = append(workersComputeList, *compute)
+	workersComputeList, err := utilityK8sWgComputesGet(ctx, m, curWg)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
 	d.SetId(strings.Split(d.Id(), "#")[0])
 	d.Set("k8s_id", k8s.ID)
 	d.Set("wg_id", curWg.ID)
+	d.Set("autoscaler_node_group", k8sWgAutoscalerNodeGroup(curWg.ID, d.Get("min_num").(int), d.Get("max_num").(int)))
 	flattenWgData(d, curWg, workersComputeList)
+	d.Set("labels", k8sWgMetaFilter(d.Get("labels").([]interface{}), curWg.Labels))
+	d.Set("taints", k8sWgMetaFilter(d.Get("taints").([]interface{}), curWg.Taints))
+	d.Set("annotations", k8sWgMetaFilter(d.Get("annotations").([]interface{}), curWg.Annotations))
 
 	return nil
 }
 
 func resourceK8sWgUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sWgUpdate: called with k8s id %d", d.Get("k8s_id").(int))
+	logger.Debugf(ctx, "resourceK8sWgUpdate: called with k8s id %d", d.Get("k8s_id").(int))
+	defer k8sCacheInvalidate(m)
 
 	c := m.(*controller.ControllerCfg)
 
@@ -153,31 +159,63 @@ func resourceK8sWgUpdate(ctx context.Context, d *schema.ResourceData, m interfac
 		return diag.FromErr(err)
 	}
 
-	urlValues := &url.Values{}
-	urlValues.Add("k8sId", strconv.Itoa(d.Get("k8s_id").(int)))
-	urlValues.Add("workersGroupId", d.Id())
+	if d.HasChanges("labels", "taints", "annotations", "cpu", "ram", "disk", "sep_id", "sep_pool") {
+		urlValues := &url.Values{}
+		urlValues.Add("k8sId", strconv.Itoa(d.Get("k8s_id").(int)))
+		urlValues.Add("workersGroupId", d.Id())
+		urlValues.Add("workerCpu", strconv.Itoa(d.Get("cpu").(int)))
+		urlValues.Add("workerRam", strconv.Itoa(d.Get("ram").(int)))
+		urlValues.Add("workerDisk", strconv.Itoa(d.Get("disk").(int)))
+		urlValues.Add("workerSepId", strconv.Itoa(d.Get("sep_id").(int)))
+		urlValues.Add("workerSepPool", d.Get("sep_pool").(string))
+		k8sWgMetaAdd(urlValues, d)
+
+		_, err := c.DecortAPICall(ctx, "POST", K8sWgUpdateAPI, urlValues)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
-	if newNum := d.Get("num").(int); uint64(newNum) > wg.Num {
-		urlValues.Add("num", strconv.FormatUint(uint64(newNum)-wg.Num, 10))
-		_, err := c.DecortAPICall(ctx, "POST", K8sWorkerAddAPI, urlValues)
+	if d.HasChanges("cpu", "ram", "disk", "sep_id", "sep_pool") {
+		// existing nodes keep their size and placement, they are replaced with nodes of the new configuration
+		if err := utilityK8sWgReplaceWorkers(ctx, d, m, wg); err != nil {
+			return diag.FromErr(err)
+		}
+		wg, err = utilityK8sWgCheckPresence(ctx, d, m)
 		if err != nil {
 			return diag.FromErr(err)
 		}
-	} else {
-		for i := int(wg.Num) - 1; i >= newNum; i-- {
-			urlValues.Set("workerId", strconv.FormatUint(wg.DetailedInfo[i].ID, 10))
-			_, err := c.DecortAPICall(ctx, "POST", K8sWorkerDeleteAPI, urlValues)
+	}
+
+	if d.HasChange("num") {
+		urlValues := &url.Values{}
+		urlValues.Add("k8sId", strconv.Itoa(d.Get("k8s_id").(int)))
+		urlValues.Add("workersGroupId", d.Id())
+
+		if newNum := d.Get("num").(int); uint64(newNum) > wg.Num {
+			urlValues.Add("num", strconv.FormatUint(uint64(newNum)-wg.Num, 10))
+			_, err := c.DecortAPICall(ctx, "POST", K8sWorkerAddAPI, urlValues)
 			if err != nil {
 				return diag.FromErr(err)
 			}
+		} else {
+			for i := int(wg.Num) - 1; i >= newNum; i-- {
+				urlValues.Set("workerId", strconv.FormatUint(wg.DetailedInfo[i].ID, 10))
+				_, err := c.DecortAPICall(ctx, "POST", K8sWorkerDeleteAPI, urlValues)
+				if err != nil {
+					return diag.FromErr(err)
+				}
+			}
 		}
 	}
 
-	return nil
+	k8sCacheInvalidate(m)
+	return resourceK8sWgRead(ctx, d, m)
 }
 
 func resourceK8sWgDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sWgDelete: called with k8s id %d", d.Get("k8s_id").(int))
+	logger.Debugf(ctx, "resourceK8sWgDelete: called with k8s id %d", d.Get("k8s_id").(int))
+	defer k8sCacheInvalidate(m)
 
 	wg, err := utilityK8sWgCheckPresence(ctx, d, m)
 	if wg == nil {
@@ -217,34 +255,68 @@ func resourceK8sWgSchemaMake() map[string]*schema.Schema {
 		},
 
 		"num": {
-			Type:        schema.TypeInt,
-			Optional:    true,
-			Default:     1,
-			Description: "Number of worker nodes to create.",
+			Type:             schema.TypeInt,
+			Optional:         true,
+			Default:          1,
+			DiffSuppressFunc: resourceK8sWgNumDiffSuppress,
+			Description:      "Number of worker nodes to create. Changes are ignored while the current number is within min_num and max_num bounds.",
+		},
+
+		"min_num": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			ValidateFunc: validation.IntAtLeast(0),
+			Description:  "Minimum number of worker nodes the cluster autoscaler may scale the group down to.",
+		},
+
+		"max_num": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			ValidateFunc: validation.IntAtLeast(0),
+			Description:  "Maximum number of worker nodes the cluster autoscaler may scale the group up to. Autoscaling is disabled if 0.",
+		},
+
+		"autoscaler_node_group": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Node group specification <min_num>:<max_num>:<wg_id> to pass to the -node-group flag of decort-autoscaler, so the bounds of the autoscaler follow min_num and max_num. Empty if autoscaling is disabled.",
 		},
 
 		"cpu": {
 			Type:        schema.TypeInt,
 			Optional:    true,
-			ForceNew:    true,
 			Default:     1,
-			Description: "Worker node CPU count.",
+			Description: "Worker node CPU count. Changing it replaces worker nodes one by one.",
 		},
 
 		"ram": {
 			Type:        schema.TypeInt,
 			Optional:    true,
-			ForceNew:    true,
 			Default:     1024,
-			Description: "Worker node RAM in MB.",
+			Description: "Worker node RAM in MB. Changing it replaces worker nodes one by one.",
 		},
 
 		"disk": {
 			Type:        schema.TypeInt,
 			Optional:    true,
-			ForceNew:    true,
 			Default:     0,
-			Description: "Worker node boot disk size. If unspecified or 0, size is defined by OS image size.",
+			Description: "Worker node boot disk size. If unspecified or 0, size is defined by OS image size. Changing it replaces worker nodes one by one.",
+		},
+
+		"sep_id": {
+			Type:        schema.TypeInt,
+			Optional:    true,
+			Default:     0,
+			Description: "Storage Endpoint ID for boot disks of worker nodes. Chosen by the platform if 0. Changing it replaces worker nodes one by one.",
+		},
+
+		"sep_pool": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     "",
+			Description: "Pool of the Storage Endpoint for boot disks of worker nodes. Changing it replaces worker nodes one by one.",
 		},
 		"wg_id": {
 			Type:        schema.TypeInt,
@@ -260,10 +332,11 @@ func resourceK8sWgSchemaMake() map[string]*schema.Schema {
 		},
 		"labels": {
 			Type:     schema.TypeList,
-			Computed: true,
+			Optional: true,
 			Elem: &schema.Schema{
 				Type: schema.TypeString,
 			},
+			Description: "Labels of worker nodes in key=value form. Labels added by the platform are not shown.",
 		},
 		"guid": {
 			Type:     schema.TypeString,
@@ -271,21 +344,99 @@ func resourceK8sWgSchemaMake() map[string]*schema.Schema {
 		},
 		"annotations": {
 			Type:     schema.TypeList,
-			Computed: true,
+			Optional: true,
 			Elem: &schema.Schema{
 				Type: schema.TypeString,
 			},
+			Description: "Annotations of worker nodes in key=value form.",
 		},
 		"taints": {
 			Type:     schema.TypeList,
-			Computed: true,
+			Optional: true,
 			Elem: &schema.Schema{
 				Type: schema.TypeString,
 			},
+			Description: "Taints of worker nodes in key=value:effect form.",
 		},
 	}
 }
 
+func resourceK8sWgImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourceK8sWgImport: called with id %s", d.Id())
+
+	parameters := strings.Split(d.Id(), "#")
+	if len(parameters) != 2 {
+		return nil, fmt.Errorf("invalid import id %q: expected <k8s_id>#<wg_id>", d.Id())
+	}
+
+	k8sId, err := strconv.Atoi(parameters[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid import id %q: k8s id must be a number", d.Id())
+	}
+	wgId, err := strconv.Atoi(parameters[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid import id %q: worker group id must be a number", d.Id())
+	}
+
+	d.SetId(parameters[1])
+	d.Set("k8s_id", k8sId)
+	d.Set("wg_id", wgId)
+
+	wg, err := utilityK8sWgCheckPresence(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+	if wg == nil {
+		return nil, fmt.Errorf("worker group %d not found in k8s cluster %d", wgId, k8sId)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// k8sWgAutoscalerNodeGroup returns the node group specification of decort-autoscaler for the
+// worker group. min_num and max_num are the single source of the autoscaling bounds: the
+// autoscaler gets them from this specification, and num changes are suppressed within them.
+func k8sWgAutoscalerNodeGroup(wgId uint64, minNum int, maxNum int) string {
+	if maxNum == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d:%d", minNum, maxNum, wgId)
+}
+
+// resourceK8sWgNumDiffSuppress ignores changes of num while the group is managed by the cluster
+// autoscaler and its current size is within the autoscaling bounds
+func resourceK8sWgNumDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	maxNum := d.Get("max_num").(int)
+	if old == "" || maxNum == 0 {
+		return false
+	}
+
+	num, err := strconv.Atoi(old)
+	if err != nil {
+		return false
+	}
+	return num >= d.Get("min_num").(int) && num <= maxNum
+}
+
+func resourceK8sWgCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	minNum, maxNum := d.Get("min_num").(int), d.Get("max_num").(int)
+	if maxNum == 0 {
+		if minNum != 0 {
+			return fmt.Errorf("min_num requires max_num to be set")
+		}
+		return nil
+	}
+
+	if minNum > maxNum {
+		return fmt.Errorf("min_num %d is greater than max_num %d", minNum, maxNum)
+	}
+	if num := d.Get("num").(int); num < minNum || num > maxNum {
+		return fmt.Errorf("num %d is out of min_num %d and max_num %d bounds", num, minNum, maxNum)
+	}
+
+	return nil
+}
+
 func ResourceK8sWg() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
@@ -296,9 +447,11 @@ func ResourceK8sWg() *schema.Resource {
 		DeleteContext: resourceK8sWgDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceK8sWgImport,
 		},
 
+		CustomizeDiff: resourceK8sWgCustomizeDiff,
+
 		Timeouts: &schema.ResourceTimeout{
 			Create:  &constants.Timeout600s,
 			Read:    &constants.Timeout300s,
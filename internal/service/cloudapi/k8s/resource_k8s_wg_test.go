@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8s
+
+import "testing"
+
+func TestK8sWgAutoscalerNodeGroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		wgId   uint64
+		minNum int
+		maxNum int
+		want   string
+	}{
+		{name: "not autoscaled", wgId: 456, want: ""},
+		{name: "not autoscaled with min", wgId: 456, minNum: 2, want: ""},
+		{name: "autoscaled", wgId: 456, minNum: 1, maxNum: 5, want: "1:5:456"},
+		{name: "from zero", wgId: 456, maxNum: 3, want: "0:3:456"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := k8sWgAutoscalerNodeGroup(tc.wgId, tc.minNum, tc.maxNum); got != tc.want {
+				t.Errorf("k8sWgAutoscalerNodeGroup() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
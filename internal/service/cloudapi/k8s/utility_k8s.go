@@ -35,15 +35,55 @@ package k8s
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
 	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/kvmvm"
 )
 
+// utilityK8sWaitTask waits for the async task with the audit ID returned by an API call to complete
+// and reports its progress to the log
+func utilityK8sWaitTask(ctx context.Context, m interface{}, auditId string, action string) (*AsyncTask, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("auditId", strings.Trim(auditId, `"`))
+
+	stage := ""
+	for {
+		resp, err := c.DecortAPICall(ctx, "POST", AsyncTaskGetAPI, urlValues)
+		if err != nil {
+			return nil, err
+		}
+
+		task := &AsyncTask{}
+		if err := json.Unmarshal([]byte(resp), task); err != nil {
+			return nil, err
+		}
+		if task.Stage != stage {
+			logger.Infof(ctx, "utilityK8sWaitTask: k8s %s - %s", action, task.Stage)
+			stage = task.Stage
+		}
+
+		if task.Completed {
+			if task.Error != "" {
+				return nil, fmt.Errorf("cannot %s k8s instance: %v", action, task.Error)
+			}
+			return task, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("cannot %s k8s instance: %v, last stage %q", action, ctx.Err(), task.Stage)
+		case <-time.After(time.Second * 10):
+		}
+	}
+}
+
 func utilityK8sCheckPresence(ctx context.Context, d *schema.ResourceData, m interface{}) (*K8SRecord, error) {
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
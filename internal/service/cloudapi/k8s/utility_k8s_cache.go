@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/parallel"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/kvmvm"
+)
+
+// k8sCacheInvalidate drops cached responses of the controller, it is called after changes of
+// clusters and worker groups so the following reads get their actual state
+func k8sCacheInvalidate(m interface{}) {
+	m.(*controller.ControllerCfg).CacheInvalidate()
+}
+
+// utilityK8sComputesGet gets the computes of the nodes concurrently, in the order of the IDs
+func utilityK8sComputesGet(ctx context.Context, m interface{}, computeIds []uint64) ([]kvmvm.ComputeGetResp, error) {
+	c := m.(*controller.ControllerCfg)
+	computes := make([]kvmvm.ComputeGetResp, len(computeIds))
+	err := parallel.Run(ctx, len(computeIds), parallel.Limit, func(ctx context.Context, i int) error {
+		urlValues := &url.Values{}
+		urlValues.Add("computeId", strconv.FormatUint(computeIds[i], 10))
+
+		computeRaw, err := c.DecortAPICall(ctx, "POST", kvmvm.ComputeGetAPI, urlValues)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(computeRaw), &computes[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return computes, nil
+}
+
+// utilityK8sWgComputesGet gets the computes of worker nodes of the group
+func utilityK8sWgComputesGet(ctx context.Context, m interface{}, wg K8SGroup) ([]kvmvm.ComputeGetResp, error) {
+	ids := make([]uint64, 0, len(wg.DetailedInfo))
+	for _, info := range wg.DetailedInfo {
+		ids = append(ids, info.ID)
+	}
+	return utilityK8sComputesGet(ctx, m, ids)
+}
+
+// utilityK8sNodesComputesGet gets the computes of master nodes and of worker nodes of all worker groups
+func utilityK8sNodesComputesGet(ctx context.Context, m interface{}, k8s *K8SRecord) ([]kvmvm.ComputeGetResp, []kvmvm.ComputeGetResp, error) {
+	ids := make([]uint64, 0, len(k8s.K8SGroups.Masters.DetailedInfo))
+	for _, info := range k8s.K8SGroups.Masters.DetailedInfo {
+		ids = append(ids, info.ID)
+	}
+	mastersNum := len(ids)
+	for _, wg := range k8s.K8SGroups.Workers {
+		for _, info := range wg.DetailedInfo {
+			ids = append(ids, info.ID)
+		}
+	}
+
+	computes, err := utilityK8sComputesGet(ctx, m, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	return computes[:mastersNum], computes[mastersNum:], nil
+}
+
+// utilityK8sKubeconfigGet gets the kubeconfig of the cluster. It holds the credentials of the
+// cluster, so it is never kept in the cache of read calls.
+func utilityK8sKubeconfigGet(ctx context.Context, m interface{}, k8sId string) (string, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("k8sId", k8sId)
+	return c.DecortAPICall(controller.WithoutCache(ctx), "POST", K8sGetConfigAPI, urlValues)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+// stubController counts the calls of each API
+type stubController struct {
+	sync.Mutex
+	calls map[string]int
+}
+
+func (s *stubController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.calls[r.URL.Path]++
+	switch r.URL.Path {
+	case "/restmachine/cloudapi/accounts/list":
+		w.Write([]byte("[]"))
+	case K8sGetAPI:
+		w.Write([]byte(`{"id": 5}`))
+	case K8sGetConfigAPI:
+		w.Write([]byte("apiVersion: v1"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// testControllerConfigure configures the controller the way the provider does, against the stub
+func testControllerConfigure(t *testing.T, handler http.Handler, cacheTTL int) *controller.ControllerCfg {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	providerSchema := map[string]*schema.Schema{
+		"default_tags": {Type: schema.TypeMap, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+	}
+	for _, key := range []string{"authenticator", "controller_url", "jwt", "oauth2_url", "user", "password",
+		"app_id", "app_secret", "trace_file", "name_prefix", "name_pattern", "quota_check"} {
+		providerSchema[key] = &schema.Schema{Type: schema.TypeString, Optional: true}
+	}
+	providerSchema["cache_ttl"] = &schema.Schema{Type: schema.TypeInt, Optional: true}
+	providerSchema["allow_unverified_ssl"] = &schema.Schema{Type: schema.TypeBool, Optional: true}
+
+	d := schema.TestResourceDataRaw(t, providerSchema, map[string]interface{}{
+		"authenticator":  "jwt",
+		"controller_url": srv.URL,
+		"jwt":            "jwt",
+		"oauth2_url":     srv.URL,
+		"cache_ttl":      cacheTTL,
+	})
+	c, err := controller.ControllerConfigure(context.Background(), d)
+	if err != nil {
+		t.Fatalf("ControllerConfigure() error = %v", err)
+	}
+	return c
+}
+
+func TestK8sReadCache(t *testing.T) {
+	tests := []struct {
+		name          string
+		cacheTTL      int
+		wantGets      int
+		wantGetConfig int
+	}{
+		{name: "cache disabled", cacheTTL: 0, wantGets: 2, wantGetConfig: 2},
+		{name: "cache_ttl set", cacheTTL: 60, wantGets: 1, wantGetConfig: 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &stubController{calls: map[string]int{}}
+			c := testControllerConfigure(t, stub, tc.cacheTTL)
+			d := schema.TestResourceDataRaw(t, dataSourceK8sSchemaMake(), map[string]interface{}{"k8s_id": 5})
+
+			for i := 0; i < 2; i++ {
+				if _, err := utilityDataK8sCheckPresence(context.Background(), d, c); err != nil {
+					t.Fatalf("utilityDataK8sCheckPresence() error = %v", err)
+				}
+				if _, err := utilityK8sKubeconfigGet(context.Background(), c, "5"); err != nil {
+					t.Fatalf("utilityK8sKubeconfigGet() error = %v", err)
+				}
+			}
+
+			if got := stub.calls[K8sGetAPI]; got != tc.wantGets {
+				t.Errorf("k8s/get calls = %d, want %d", got, tc.wantGets)
+			}
+			if got := stub.calls[K8sGetConfigAPI]; got != tc.wantGetConfig {
+				t.Errorf("k8s/getConfig calls = %d, want %d", got, tc.wantGetConfig)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/kvmvm"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	k8sNodeRoleMaster = "master"
+	k8sNodeRoleWorker = "worker"
+)
+
+// k8sNodeInfo is a master or worker node of the cluster with its addresses
+type k8sNodeInfo struct {
+	Name       string
+	Role       string
+	Group      string
+	GroupID    uint64
+	ComputeID  uint64
+	InternalIP string
+	ExternalIP string
+	Status     string
+	TechStatus string
+}
+
+// k8sNodesInfo returns nodes of the cluster sorted by name, computes of the workers are in the order
+// of the worker groups and their nodes
+func k8sNodesInfo(k8s *K8SRecord, masters []kvmvm.ComputeGetResp, workers []kvmvm.ComputeGetResp) []k8sNodeInfo {
+	nodes := make([]k8sNodeInfo, 0, len(masters)+len(workers))
+	for i, info := range k8s.K8SGroups.Masters.DetailedInfo {
+		node := k8sNodeInfo{
+			Name:       info.Name,
+			Role:       k8sNodeRoleMaster,
+			Group:      k8s.K8SGroups.Masters.Name,
+			GroupID:    k8s.K8SGroups.Masters.ID,
+			ComputeID:  info.ID,
+			Status:     info.Status,
+			TechStatus: info.TechStatus,
+		}
+		if i < len(masters) {
+			node.InternalIP, node.ExternalIP = k8sNodeAddresses(masters[i])
+		}
+		nodes = append(nodes, node)
+	}
+
+	i := 0
+	for _, wg := range k8s.K8SGroups.Workers {
+		for _, info := range wg.DetailedInfo {
+			node := k8sNodeInfo{
+				Name:       info.Name,
+				Role:       k8sNodeRoleWorker,
+				Group:      wg.Name,
+				GroupID:    wg.ID,
+				ComputeID:  info.ID,
+				Status:     info.Status,
+				TechStatus: info.TechStatus,
+			}
+			if i < len(workers) {
+				node.InternalIP, node.ExternalIP = k8sNodeAddresses(workers[i])
+			}
+			nodes = append(nodes, node)
+			i++
+		}
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
+
+// k8sNodeAddresses returns the first ViNS and the first external network addresses of the compute
+func k8sNodeAddresses(compute kvmvm.ComputeGetResp) (string, string) {
+	internalIP, externalIP := "", ""
+	for _, iface := range compute.Interfaces {
+		switch {
+		case iface.NetType == "VINS" && internalIP == "":
+			internalIP = iface.IPAddress
+		case iface.NetType == "EXTNET" && externalIP == "":
+			externalIP = iface.IPAddress
+		}
+	}
+	return internalIP, externalIP
+}
+
+// utilityK8sNodesInfo gets computes of all nodes of the cluster
+func utilityK8sNodesInfo(ctx context.Context, m interface{}, k8s *K8SRecord) ([]k8sNodeInfo, error) {
+	masters, workers, err := utilityK8sNodesComputesGet(ctx, m, k8s)
+	if err != nil {
+		return nil, err
+	}
+	return k8sNodesInfo(k8s, masters, workers), nil
+}
+
+// utilityK8sLbGet gets the load balancer of the cluster
+func utilityK8sLbGet(ctx context.Context, m interface{}, lbId uint64) (*LbRecord, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("lbId", strconv.FormatUint(lbId, 10))
+
+	resp, err := c.DecortAPICall(ctx, "POST", LbGetAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	lb := &LbRecord{}
+	if err := json.Unmarshal([]byte(resp), lb); err != nil {
+		return nil, err
+	}
+	return lb, nil
+}
+
+var k8sInventoryGroupRe = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// k8sInventoryGroup returns the name of the inventory group of a worker group, Ansible group names
+// may contain letters, digits and underscores only
+func k8sInventoryGroup(wgName string) string {
+	return "wg_" + k8sInventoryGroupRe.ReplaceAllString(wgName, "_")
+}
+
+// k8sNodeAnsibleHost returns the address Ansible connects to, the external one if the node has it
+func k8sNodeAnsibleHost(node k8sNodeInfo) string {
+	if node.ExternalIP != "" {
+		return node.ExternalIP
+	}
+	return node.InternalIP
+}
+
+// k8sAnsibleInventory renders the Ansible inventory of the cluster in ini or yaml format. Masters are
+// in the "masters" group, workers are in a group per worker group, which are children of the "workers"
+// group, both are children of the "k8s_cluster" group which holds the load balancer address.
+func k8sAnsibleInventory(nodes []k8sNodeInfo, lbIP string, format string) (string, error) {
+	masters := make([]k8sNodeInfo, 0)
+	workerGroups := make(map[string][]k8sNodeInfo)
+	groupNames := make([]string, 0)
+	for _, node := range nodes {
+		if node.Role == k8sNodeRoleMaster {
+			masters = append(masters, node)
+			continue
+		}
+		group := k8sInventoryGroup(node.Group)
+		if _, ok := workerGroups[group]; !ok {
+			groupNames = append(groupNames, group)
+		}
+		workerGroups[group] = append(workerGroups[group], node)
+	}
+	sort.Strings(groupNames)
+
+	switch format {
+	case "ini":
+		var b strings.Builder
+		hosts := func(group string, nodes []k8sNodeInfo) {
+			fmt.Fprintf(&b, "[%s]\n", group)
+			for _, node := range nodes {
+				fmt.Fprintf(&b, "%s ansible_host=%s internal_ip=%s compute_id=%d\n", node.Name, k8sNodeAnsibleHost(node), node.InternalIP, node.ComputeID)
+			}
+			b.WriteString("\n")
+		}
+
+		hosts("masters", masters)
+		for _, group := range groupNames {
+			hosts(group, workerGroups[group])
+		}
+		b.WriteString("[workers:children]\n")
+		for _, group := range groupNames {
+			b.WriteString(group + "\n")
+		}
+		b.WriteString("\n[k8s_cluster:children]\nmasters\nworkers\n")
+		fmt.Fprintf(&b, "\n[k8s_cluster:vars]\nlb_ip=%s\n", lbIP)
+		return b.String(), nil
+
+	case "yaml":
+		hosts := func(nodes []k8sNodeInfo) map[string]interface{} {
+			res := make(map[string]interface{}, len(nodes))
+			for _, node := range nodes {
+				res[node.Name] = map[string]interface{}{
+					"ansible_host": k8sNodeAnsibleHost(node),
+					"internal_ip":  node.InternalIP,
+					"compute_id":   node.ComputeID,
+				}
+			}
+			return map[string]interface{}{"hosts": res}
+		}
+
+		workers := make(map[string]interface{}, len(groupNames))
+		for _, group := range groupNames {
+			workers[group] = hosts(workerGroups[group])
+		}
+		inventory := map[string]interface{}{
+			"all": map[string]interface{}{
+				"children": map[string]interface{}{
+					"k8s_cluster": map[string]interface{}{
+						"children": map[string]interface{}{
+							"masters": hosts(masters),
+							"workers": map[string]interface{}{"children": workers},
+						},
+						"vars": map[string]interface{}{"lb_ip": lbIP},
+					},
+				},
+			},
+		}
+
+		var b strings.Builder
+		encoder := yaml.NewEncoder(&b)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(inventory); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+
+	return "", fmt.Errorf("unknown inventory format %q", format)
+}
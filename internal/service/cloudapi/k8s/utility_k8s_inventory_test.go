@@ -0,0 +1,207 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/kvmvm"
+	"gopkg.in/yaml.v3"
+)
+
+var testK8sNodes = []k8sNodeInfo{
+	{Name: "master-1", Role: k8sNodeRoleMaster, ComputeID: 11, InternalIP: "10.0.0.11"},
+	{Name: "worker-a-1", Role: k8sNodeRoleWorker, Group: "gpu-a100", ComputeID: 21, InternalIP: "10.0.0.21", ExternalIP: "185.0.0.21"},
+	{Name: "worker-b-1", Role: k8sNodeRoleWorker, Group: "default", ComputeID: 31, InternalIP: "10.0.0.31"},
+	{Name: "worker-b-2", Role: k8sNodeRoleWorker, Group: "default", ComputeID: 32, InternalIP: "10.0.0.32"},
+}
+
+func TestK8sInventoryGroup(t *testing.T) {
+	tests := []struct {
+		wgName string
+		want   string
+	}{
+		{wgName: "default", want: "wg_default"},
+		{wgName: "gpu-a100", want: "wg_gpu_a100"},
+		{wgName: "wg.1 test", want: "wg_wg_1_test"},
+	}
+
+	for _, tc := range tests {
+		if got := k8sInventoryGroup(tc.wgName); got != tc.want {
+			t.Errorf("k8sInventoryGroup(%q) = %q, want %q", tc.wgName, got, tc.want)
+		}
+	}
+}
+
+func TestK8sNodeAddresses(t *testing.T) {
+	tests := []struct {
+		name         string
+		interfaces   []kvmvm.InterfaceRecord
+		wantInternal string
+		wantExternal string
+	}{
+		{name: "no interfaces"},
+		{
+			name:         "vins only",
+			interfaces:   []kvmvm.InterfaceRecord{{NetType: "VINS", IPAddress: "10.0.0.1"}},
+			wantInternal: "10.0.0.1",
+		},
+		{
+			name: "first of each type",
+			interfaces: []kvmvm.InterfaceRecord{
+				{NetType: "EXTNET", IPAddress: "185.0.0.1"},
+				{NetType: "VINS", IPAddress: "10.0.0.1"},
+				{NetType: "VINS", IPAddress: "10.0.1.1"},
+				{NetType: "EXTNET", IPAddress: "185.0.0.2"},
+			},
+			wantInternal: "10.0.0.1",
+			wantExternal: "185.0.0.1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			internalIP, externalIP := k8sNodeAddresses(kvmvm.ComputeGetResp{Interfaces: tc.interfaces})
+			if internalIP != tc.wantInternal || externalIP != tc.wantExternal {
+				t.Errorf("k8sNodeAddresses() = %q, %q, want %q, %q", internalIP, externalIP, tc.wantInternal, tc.wantExternal)
+			}
+		})
+	}
+}
+
+func TestK8sAnsibleInventoryIni(t *testing.T) {
+	want := `[masters]
+master-1 ansible_host=10.0.0.11 internal_ip=10.0.0.11 compute_id=11
+
+[wg_default]
+worker-b-1 ansible_host=10.0.0.31 internal_ip=10.0.0.31 compute_id=31
+worker-b-2 ansible_host=10.0.0.32 internal_ip=10.0.0.32 compute_id=32
+
+[wg_gpu_a100]
+worker-a-1 ansible_host=185.0.0.21 internal_ip=10.0.0.21 compute_id=21
+
+[workers:children]
+wg_default
+wg_gpu_a100
+
+[k8s_cluster:children]
+masters
+workers
+
+[k8s_cluster:vars]
+lb_ip=185.0.0.1
+`
+
+	got, err := k8sAnsibleInventory(testK8sNodes, "185.0.0.1", "ini")
+	if err != nil {
+		t.Fatalf("k8sAnsibleInventory() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("k8sAnsibleInventory() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestK8sAnsibleInventoryYaml(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []k8sNodeInfo
+		want  string
+	}{
+		{
+			name:  "cluster",
+			nodes: testK8sNodes,
+			want: `
+all:
+  children:
+    k8s_cluster:
+      vars:
+        lb_ip: 185.0.0.1
+      children:
+        masters:
+          hosts:
+            master-1: {ansible_host: 10.0.0.11, internal_ip: 10.0.0.11, compute_id: 11}
+        workers:
+          children:
+            wg_default:
+              hosts:
+                worker-b-1: {ansible_host: 10.0.0.31, internal_ip: 10.0.0.31, compute_id: 31}
+                worker-b-2: {ansible_host: 10.0.0.32, internal_ip: 10.0.0.32, compute_id: 32}
+            wg_gpu_a100:
+              hosts:
+                worker-a-1: {ansible_host: 185.0.0.21, internal_ip: 10.0.0.21, compute_id: 21}
+`,
+		},
+		{
+			name: "no workers",
+			want: `
+all:
+  children:
+    k8s_cluster:
+      vars:
+        lb_ip: 185.0.0.1
+      children:
+        masters:
+          hosts: {}
+        workers:
+          children: {}
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := k8sAnsibleInventory(tc.nodes, "185.0.0.1", "yaml")
+			if err != nil {
+				t.Fatalf("k8sAnsibleInventory() error = %v", err)
+			}
+
+			var gotInventory, wantInventory interface{}
+			if err := yaml.Unmarshal([]byte(got), &gotInventory); err != nil {
+				t.Fatalf("k8sAnsibleInventory() is not valid yaml: %v\n%s", err, got)
+			}
+			if err := yaml.Unmarshal([]byte(tc.want), &wantInventory); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotInventory, wantInventory) {
+				t.Errorf("k8sAnsibleInventory() =\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestK8sAnsibleInventoryFormat(t *testing.T) {
+	if _, err := k8sAnsibleInventory(testK8sNodes, "185.0.0.1", "json"); err == nil {
+		t.Error("k8sAnsibleInventory() of unknown format succeeded")
+	}
+}
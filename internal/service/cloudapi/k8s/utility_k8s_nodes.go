@@ -0,0 +1,369 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"gopkg.in/yaml.v3"
+)
+
+// k8sNode is a master or worker node found by its compute ID or name
+type k8sNode struct {
+	DetailedInfo
+	wgId uint64 // 0 for master nodes
+}
+
+// k8sKubeconfig is the part of the kubeconfig needed to access the API server of the cluster
+type k8sKubeconfig struct {
+	Clusters []struct {
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		User struct {
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKeyData         string `yaml:"client-key-data"`
+			Token                 string `yaml:"token"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// k8sNodeFind looks the node up by compute ID or name among the nodes
+func k8sNodeFind(nodes []k8sNode, idOrName string) (k8sNode, bool) {
+	for _, node := range nodes {
+		if strconv.FormatUint(node.ID, 10) == idOrName || node.Name == idOrName {
+			return node, true
+		}
+	}
+	return k8sNode{}, false
+}
+
+// k8sWorkerNodes returns worker nodes of all worker groups of the cluster
+func k8sWorkerNodes(k8s *K8SRecord) []k8sNode {
+	nodes := make([]k8sNode, 0)
+	for _, wg := range k8s.K8SGroups.Workers {
+		for _, info := range wg.DetailedInfo {
+			nodes = append(nodes, k8sNode{DetailedInfo: info, wgId: wg.ID})
+		}
+	}
+	return nodes
+}
+
+// k8sMasterNodes returns master nodes of the cluster
+func k8sMasterNodes(k8s *K8SRecord) []k8sNode {
+	nodes := make([]k8sNode, 0, len(k8s.K8SGroups.Masters.DetailedInfo))
+	for _, info := range k8s.K8SGroups.Masters.DetailedInfo {
+		nodes = append(nodes, k8sNode{DetailedInfo: info})
+	}
+	return nodes
+}
+
+// k8sNodesAdded returns IDs or names added to the set attribute, operations on nodes are triggered
+// only by new entries, the entries of already replaced nodes remain in the configuration
+func k8sNodesAdded(d *schema.ResourceData, key string) []string {
+	oldSet, newSet := d.GetChange(key)
+	added := newSet.(*schema.Set).Difference(oldSet.(*schema.Set)).List()
+
+	res := make([]string, 0, len(added))
+	for _, idOrName := range added {
+		res = append(res, idOrName.(string))
+	}
+	return res
+}
+
+// k8sNodesRollback restores the old value of the set attributes, other changes of the update
+// that have been applied stay in the state
+func k8sNodesRollback(d *schema.ResourceData, keys ...string) {
+	for _, key := range keys {
+		oldSet, _ := d.GetChange(key)
+		d.Set(key, oldSet)
+	}
+}
+
+// utilityK8sNodeCordon marks the node unschedulable through the API server of the cluster.
+// Failures are only logged: the node is deleted from the cluster anyway.
+func utilityK8sNodeCordon(ctx context.Context, m interface{}, k8sId string, nodeName string) {
+	kubeconfigRaw, err := utilityK8sKubeconfigGet(ctx, m, k8sId)
+	if err != nil {
+		logger.Warnf(ctx, "utilityK8sNodeCordon: cannot get kubeconfig to cordon node %s: %v", nodeName, err)
+		return
+	}
+
+	if err := k8sNodeCordon(ctx, kubeconfigRaw, nodeName); err != nil {
+		logger.Warnf(ctx, "utilityK8sNodeCordon: cannot cordon node %s: %v", nodeName, err)
+		return
+	}
+	logger.Debugf(ctx, "utilityK8sNodeCordon: node %s cordoned", nodeName)
+}
+
+// k8sAPIRequest sends the request to the API server of the cluster with the credentials of
+// the kubeconfig and returns the response body
+func k8sAPIRequest(ctx context.Context, kubeconfigRaw string, method string, path string, contentType string, body []byte) ([]byte, error) {
+	kubeconfig := k8sKubeconfig{}
+	if err := yaml.Unmarshal([]byte(kubeconfigRaw), &kubeconfig); err != nil {
+		return nil, err
+	}
+	if len(kubeconfig.Clusters) == 0 || len(kubeconfig.Users) == 0 {
+		return nil, fmt.Errorf("no cluster or user in kubeconfig")
+	}
+	cluster, user := kubeconfig.Clusters[0].Cluster, kubeconfig.Users[0].User
+
+	tlsConfig := &tls.Config{}
+	if cluster.CertificateAuthorityData != "" {
+		ca, err := base64.StdEncoding.DecodeString(cluster.CertificateAuthorityData)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		tlsConfig.RootCAs.AppendCertsFromPEM(ca)
+	}
+	if user.ClientCertificateData != "" {
+		cert, err := base64.StdEncoding.DecodeString(user.ClientCertificateData)
+		if err != nil {
+			return nil, err
+		}
+		key, err := base64.StdEncoding.DecodeString(user.ClientKeyData)
+		if err != nil {
+			return nil, err
+		}
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(cluster.Server, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if user.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+user.Token)
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}, Timeout: time.Second * 30}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API server responded with %s", resp.Status)
+	}
+	return respBody, nil
+}
+
+func k8sNodeCordon(ctx context.Context, kubeconfigRaw string, nodeName string) error {
+	body := []byte(`{"spec":{"unschedulable":true}}`)
+	_, err := k8sAPIRequest(ctx, kubeconfigRaw, "PATCH", "/api/v1/nodes/"+nodeName, "application/strategic-merge-patch+json", body)
+	return err
+}
+
+// k8sNodeStatus is the part of the node object of the API server a restart of the node is followed by
+type k8sNodeStatus struct {
+	Status struct {
+		NodeInfo struct {
+			BootID string `json:"bootID"`
+		} `json:"nodeInfo"`
+		Conditions []struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// ready reports whether the kubelet of the node reports it ready
+func (node *k8sNodeStatus) ready() bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == "Ready" {
+			return condition.Status == "True"
+		}
+	}
+	return false
+}
+
+func k8sNodeStatusGet(ctx context.Context, kubeconfigRaw string, nodeName string) (*k8sNodeStatus, error) {
+	body, err := k8sAPIRequest(ctx, kubeconfigRaw, "GET", "/api/v1/nodes/"+nodeName, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &k8sNodeStatus{}
+	if err := json.Unmarshal(body, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// utilityK8sNodesReplace cordons and deletes each worker node and adds a new node to its worker group,
+// so the number of workers is restored before the next node is replaced
+func utilityK8sNodesReplace(ctx context.Context, d *schema.ResourceData, m interface{}, idsOrNames []string) error {
+	c := m.(*controller.ControllerCfg)
+
+	k8s, err := utilityK8sCheckPresence(ctx, d, m)
+	if err != nil {
+		return err
+	}
+	workers := k8sWorkerNodes(k8s)
+
+	nodes := make([]k8sNode, 0, len(idsOrNames))
+	for _, idOrName := range idsOrNames {
+		node, ok := k8sNodeFind(workers, idOrName)
+		if !ok {
+			return fmt.Errorf("worker node %q to replace is not found in k8s %s", idOrName, d.Id())
+		}
+		nodes = append(nodes, node)
+	}
+
+	for _, node := range nodes {
+		logger.Debugf(ctx, "utilityK8sNodesReplace: replacing worker node %s ID %d of worker group %d", node.Name, node.ID, node.wgId)
+		utilityK8sNodeCordon(ctx, m, d.Id(), node.Name)
+
+		urlValues := &url.Values{}
+		urlValues.Add("k8sId", d.Id())
+		urlValues.Add("workersGroupId", strconv.FormatUint(node.wgId, 10))
+		urlValues.Add("workerId", strconv.FormatUint(node.ID, 10))
+		urlValues.Add("asyncMode", "true")
+		resp, err := c.DecortAPICall(ctx, "POST", K8sWorkerDeleteAPI, urlValues)
+		if err != nil {
+			return err
+		}
+		if _, err := utilityK8sWaitTask(ctx, m, resp, "delete worker from"); err != nil {
+			return err
+		}
+
+		urlValues = &url.Values{}
+		urlValues.Add("k8sId", d.Id())
+		urlValues.Add("workersGroupId", strconv.FormatUint(node.wgId, 10))
+		urlValues.Add("num", "1")
+		urlValues.Add("asyncMode", "true")
+		resp, err = c.DecortAPICall(ctx, "POST", K8sWorkerAddAPI, urlValues)
+		if err != nil {
+			return err
+		}
+		if _, err := utilityK8sWaitTask(ctx, m, resp, "add worker to"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// utilityK8sMastersRestart reboots master nodes one by one. The next node is rebooted only after
+// the API server reports the previous one booted again, with a new boot ID, and ready, so the
+// control plane keeps its quorum.
+func utilityK8sMastersRestart(ctx context.Context, d *schema.ResourceData, m interface{}, idsOrNames []string) error {
+	c := m.(*controller.ControllerCfg)
+
+	k8s, err := utilityK8sCheckPresence(ctx, d, m)
+	if err != nil {
+		return err
+	}
+	masters := k8sMasterNodes(k8s)
+
+	nodes := make([]k8sNode, 0, len(idsOrNames))
+	for _, idOrName := range idsOrNames {
+		node, ok := k8sNodeFind(masters, idOrName)
+		if !ok {
+			return fmt.Errorf("master node %q to restart is not found in k8s %s", idOrName, d.Id())
+		}
+		nodes = append(nodes, node)
+	}
+
+	kubeconfig, err := utilityK8sKubeconfigGet(ctx, m, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot get kubeconfig to follow restart of master nodes: %w", err)
+	}
+
+	for _, node := range nodes {
+		before, err := k8sNodeStatusGet(ctx, kubeconfig, node.Name)
+		if err != nil {
+			return fmt.Errorf("cannot get status of master node %s from the API server: %w", node.Name, err)
+		}
+		if !before.ready() {
+			return fmt.Errorf("master node %s is not ready, restarting it may break the quorum", node.Name)
+		}
+
+		logger.Debugf(ctx, "utilityK8sMastersRestart: restarting master node %s ID %d, boot ID %s", node.Name, node.ID, before.Status.NodeInfo.BootID)
+		urlValues := &url.Values{}
+		urlValues.Add("computeId", strconv.FormatUint(node.ID, 10))
+		if _, err := c.DecortAPICall(ctx, "POST", ComputeRebootAPI, urlValues); err != nil {
+			return err
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("master node %s is not ready after restart: %v", node.Name, ctx.Err())
+			case <-time.After(time.Second * 10):
+			}
+
+			// the API server may be unavailable while the node is down
+			after, err := k8sNodeStatusGet(ctx, kubeconfig, node.Name)
+			if err != nil {
+				logger.Debugf(ctx, "utilityK8sMastersRestart: cannot get status of master node %s: %v", node.Name, err)
+				continue
+			}
+			if after.Status.NodeInfo.BootID != before.Status.NodeInfo.BootID && after.ready() {
+				break
+			}
+		}
+	}
+
+	return nil
+}
@@ -38,6 +38,8 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
@@ -80,3 +82,103 @@ func utilityK8sWgCheckPresence(ctx context.Context, d *schema.ResourceData, m in
 
 	return nil, fmt.Errorf("Not found wg with id: %v in k8s cluster: %v", id, k8s.ID)
 }
+
+// k8sWgMetaKey returns the key of a label (key=value), annotation (key=value) or taint (key=value:effect)
+func k8sWgMetaKey(meta string) string {
+	return strings.SplitN(strings.SplitN(meta, "=", 2)[0], ":", 2)[0]
+}
+
+// k8sWgMetaFilter keeps the labels, annotations or taints of the group whose keys are managed by
+// the resource. The platform adds its own labels to worker groups, they are not shown to avoid
+// permanent diffs.
+func k8sWgMetaFilter(managed []interface{}, actual []string) []string {
+	keys := make(map[string]bool, len(managed))
+	for _, meta := range managed {
+		keys[k8sWgMetaKey(meta.(string))] = true
+	}
+
+	res := make([]string, 0, len(managed))
+	for _, meta := range actual {
+		if keys[k8sWgMetaKey(meta)] {
+			res = append(res, meta)
+		}
+	}
+	return res
+}
+
+func k8sWgMetaAdd(urlValues *url.Values, d *schema.ResourceData) {
+	for _, key := range []string{"labels", "taints", "annotations"} {
+		for _, meta := range d.Get(key).([]interface{}) {
+			urlValues.Add(key, meta.(string))
+		}
+	}
+}
+
+// utilityK8sWgWaitWorker waits for the worker node to be started
+func utilityK8sWgWaitWorker(ctx context.Context, d *schema.ResourceData, m interface{}, workerId uint64) error {
+	ctx = controller.WithoutCache(ctx)
+	for {
+		compute, err := utilityComputeCheckPresence(ctx, d, m, workerId)
+		if err != nil {
+			return err
+		}
+		if compute.TechStatus == "STARTED" {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("worker node %d is not started: %v", workerId, ctx.Err())
+		case <-time.After(time.Second * 10):
+		}
+	}
+}
+
+// utilityK8sWgReplaceWorkers replaces worker nodes of the group one by one with nodes created
+// from the current group configuration: a new node is added and started before an old one is deleted
+func utilityK8sWgReplaceWorkers(ctx context.Context, d *schema.ResourceData, m interface{}, wg *K8SGroup) error {
+	c := m.(*controller.ControllerCfg)
+
+	for _, oldWorker := range wg.DetailedInfo {
+		existing := make(map[uint64]bool)
+		current, err := utilityK8sWgCheckPresence(ctx, d, m)
+		if err != nil {
+			return err
+		}
+		for _, worker := range current.DetailedInfo {
+			existing[worker.ID] = true
+		}
+
+		logger.Debugf(ctx, "utilityK8sWgReplaceWorkers: replacing worker node %s ID %d", oldWorker.Name, oldWorker.ID)
+		urlValues := &url.Values{}
+		urlValues.Add("k8sId", strconv.Itoa(d.Get("k8s_id").(int)))
+		urlValues.Add("workersGroupId", strconv.FormatUint(wg.ID, 10))
+		urlValues.Add("num", "1")
+		if _, err := c.DecortAPICall(ctx, "POST", K8sWorkerAddAPI, urlValues); err != nil {
+			return err
+		}
+
+		current, err = utilityK8sWgCheckPresence(ctx, d, m)
+		if err != nil {
+			return err
+		}
+		for _, worker := range current.DetailedInfo {
+			if existing[worker.ID] {
+				continue
+			}
+			if err := utilityK8sWgWaitWorker(ctx, d, m, worker.ID); err != nil {
+				return err
+			}
+		}
+
+		urlValues = &url.Values{}
+		urlValues.Add("k8sId", strconv.Itoa(d.Get("k8s_id").(int)))
+		urlValues.Add("workersGroupId", strconv.FormatUint(wg.ID, 10))
+		urlValues.Add("workerId", strconv.FormatUint(oldWorker.ID, 10))
+		if _, err := c.DecortAPICall(ctx, "POST", K8sWorkerDeleteAPI, urlValues); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
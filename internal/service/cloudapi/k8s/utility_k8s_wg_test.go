@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package k8s
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestK8sWgMetaKey(t *testing.T) {
+	tests := []struct {
+		meta string
+		want string
+	}{
+		{meta: "role=worker", want: "role"},
+		{meta: "dedicated=gpu:NoSchedule", want: "dedicated"},
+		{meta: "node.kubernetes.io/unschedulable:NoSchedule", want: "node.kubernetes.io/unschedulable"},
+		{meta: "example.com/url=http://example.com", want: "example.com/url"},
+		{meta: "role", want: "role"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.meta, func(t *testing.T) {
+			if got := k8sWgMetaKey(tc.meta); got != tc.want {
+				t.Errorf("k8sWgMetaKey() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestK8sWgMetaFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		managed []interface{}
+		actual  []string
+		want    []string
+	}{
+		{
+			name:   "nothing managed",
+			actual: []string{"workersGroupName=wg1", "role=worker"},
+			want:   []string{},
+		},
+		{
+			name:    "platform labels are dropped",
+			managed: []interface{}{"role=worker"},
+			actual:  []string{"workersGroupName=wg1", "role=worker"},
+			want:    []string{"role=worker"},
+		},
+		{
+			name:    "changed values of managed keys are kept",
+			managed: []interface{}{"role=worker", "tier=backend"},
+			actual:  []string{"role=gpu", "workersGroupName=wg1", "tier=backend"},
+			want:    []string{"role=gpu", "tier=backend"},
+		},
+		{
+			name:    "removed managed keys",
+			managed: []interface{}{"role=worker"},
+			actual:  []string{"workersGroupName=wg1"},
+			want:    []string{},
+		},
+		{
+			name:    "taints",
+			managed: []interface{}{"dedicated=gpu:NoSchedule"},
+			actual:  []string{"dedicated=gpu:NoExecute", "node.kubernetes.io/unschedulable:NoSchedule"},
+			want:    []string{"dedicated=gpu:NoExecute"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := k8sWgMetaFilter(tc.managed, tc.actual); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("k8sWgMetaFilter() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -36,6 +36,7 @@ const (
 	KvmX86CreateAPI      = "/restmachine/cloudapi/kvmx86/create"
 	KvmPPCCreateAPI      = "/restmachine/cloudapi/kvmppc/create"
 	ComputeGetAPI        = "/restmachine/cloudapi/compute/get"
+	ComputeListAPI       = "/restmachine/cloudapi/compute/list"
 	RgListComputesAPI    = "/restmachine/cloudapi/rg/listComputes"
 	ComputeNetAttachAPI  = "/restmachine/cloudapi/compute/netAttach"
 	ComputeNetDetachAPI  = "/restmachine/cloudapi/compute/netDetach"
@@ -53,6 +54,16 @@ const (
 	ComputeEnableAPI     = "/restmachine/cloudapi/compute/enable"
 	ComputeDisableAPI    = "/restmachine/cloudapi/compute/disable"
 
+	//tags and custom fields
+	ComputeTagAddAPI          = "/restmachine/cloudapi/compute/tagAdd"
+	ComputeTagRemoveAPI       = "/restmachine/cloudapi/compute/tagRemove"
+	ComputeGetCustomFieldsAPI = "/restmachine/cloudapi/compute/getCustomFields"
+
+	//port forwarding
+	ComputePfwAddAPI  = "/restmachine/cloudapi/compute/pfwAdd"
+	ComputePfwDelAPI  = "/restmachine/cloudapi/compute/pfwDel"
+	ComputePfwListAPI = "/restmachine/cloudapi/compute/pfwList"
+
 	//affinity and anti-affinity
 	ComputeAffinityLabelSetAPI       = "/restmachine/cloudapi/compute/affinityLabelSet"
 	ComputeAffinityLabelRemoveAPI    = "/restmachine/cloudapi/compute/affinityLabelRemove"
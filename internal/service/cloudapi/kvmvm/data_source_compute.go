@@ -41,7 +41,6 @@ import (
 
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/status"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -50,11 +49,11 @@ import (
 
 // Parse list of all disks from API compute/get into a list of "extra disks" attached to this compute
 // Extra disks are all compute disks but a boot disk.
-func parseComputeDisksToExtraDisks(disks []DiskRecord) []interface{} {
+func parseComputeDisksToExtraDisks(ctx context.Context, disks []DiskRecord) []interface{} {
 	// this return value will be used to d.Set("extra_disks",) item of dataSourceCompute schema,
 	// which is a simple list of integer disk IDs excluding boot disk ID
 	length := len(disks)
-	log.Debugf("parseComputeDisksToExtraDisks: called for %d disks", length)
+	logger.Debugf(ctx, "parseComputeDisksToExtraDisks: called for %d disks", length)
 
 	if length == 0 || (length == 1 && disks[0].Type == "B") {
 		// the disk list is empty (which is kind of strange - diskless compute?), or
@@ -91,10 +90,10 @@ func findBootDisk(disks []DiskRecord) *DiskRecord {
 
 // Parse the list of interfaces from compute/get response into a list of networks
 // attached to this compute
-func parseComputeInterfacesToNetworks(ifaces []InterfaceRecord) []interface{} {
+func parseComputeInterfacesToNetworks(ctx context.Context, ifaces []InterfaceRecord) []interface{} {
 	// return value will be used to d.Set("network") item of dataSourceCompute schema
 	length := len(ifaces)
-	log.Debugf("parseComputeInterfacesToNetworks: called for %d ifaces", length)
+	logger.Debugf(ctx, "parseComputeInterfacesToNetworks: called for %d ifaces", length)
 
 	result := []interface{}{}
 
@@ -148,20 +147,20 @@ func flattenDataComputeDisksDemo(disksList []DiskRecord, extraDisks []interface{
 	return res
 }
 
-func flattenDataCompute(d *schema.ResourceData, compFacts string) error {
+func flattenDataCompute(ctx context.Context, d *schema.ResourceData, compFacts string) error {
 	// This function expects that compFacts string contains response from API compute/get,
 	// i.e. detailed information about compute instance.
 	//
 	// NOTE: this function modifies ResourceData argument - as such it should never be called
 	// from resourceComputeExists(...) method
 	model := ComputeGetResp{}
-	log.Debugf("flattenCompute: ready to unmarshal string %s", compFacts)
+	logger.Debugf(ctx, "flattenCompute: ready to unmarshal string %s", compFacts)
 	err := json.Unmarshal([]byte(compFacts), &model)
 	if err != nil {
 		return err
 	}
 
-	log.Debugf("flattenCompute: ID %d, RG ID %d", model.ID, model.RgID)
+	logger.Debugf(ctx, "flattenCompute: ID %d, RG ID %d", model.ID, model.RgID)
 
 	d.SetId(fmt.Sprintf("%d", model.ID))
 	// d.Set("compute_id", model.ID) - we should NOT set compute_id in the schema here: if it was set - it is already set, if it wasn't - we shouldn't
@@ -180,6 +179,7 @@ func flattenDataCompute(d *schema.ResourceData, compFacts string) error {
 		d.Set("image_id", model.ImageID)
 	}
 	d.Set("description", model.Desc)
+	d.Set("tags", map[string]string(model.Tags))
 	d.Set("enabled", false)
 	if model.Status == status.Enabled {
 		d.Set("enabled", true)
@@ -208,15 +208,15 @@ func flattenDataCompute(d *schema.ResourceData, compFacts string) error {
 	//}
 
 	if len(model.Interfaces) > 0 {
-		log.Debugf("flattenCompute: calling parseComputeInterfacesToNetworks for %d interfaces", len(model.Interfaces))
-		if err = d.Set("network", parseComputeInterfacesToNetworks(model.Interfaces)); err != nil {
+		logger.Debugf(ctx, "flattenCompute: calling parseComputeInterfacesToNetworks for %d interfaces", len(model.Interfaces))
+		if err = d.Set("network", parseComputeInterfacesToNetworks(ctx, model.Interfaces)); err != nil {
 			return err
 		}
 	}
 
 	if len(model.OsUsers) > 0 {
-		log.Debugf("flattenCompute: calling parseOsUsers for %d logins", len(model.OsUsers))
-		if err = d.Set("os_users", parseOsUsers(model.OsUsers)); err != nil {
+		logger.Debugf(ctx, "flattenCompute: calling parseOsUsers for %d logins", len(model.OsUsers))
+		if err = d.Set("os_users", parseOsUsers(ctx, model.OsUsers)); err != nil {
 			return err
 		}
 	}
@@ -238,10 +238,16 @@ func dataSourceComputeRead(ctx context.Context, d *schema.ResourceData, m interf
 		return diag.FromErr(err)
 	}
 
-	if err = flattenDataCompute(d, compFacts); err != nil {
+	if err = flattenDataCompute(ctx, d, compFacts); err != nil {
 		return diag.FromErr(err)
 	}
 
+	customFields, err := utilityComputeCustomFields(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("custom_fields", customFields)
+
 	return nil
 }
 
@@ -455,6 +461,21 @@ func DataSourceCompute() *schema.Resource {
 				Computed:    true,
 				Description: "Is compute started.",
 			},
+
+			"tags": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Key-value tags of this compute instance.",
+			},
+
+			"custom_fields": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Custom fields of this compute instance in JSON format.",
+			},
 		},
 	}
 }
@@ -1,12 +1,12 @@
 package kvmvm
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/status"
-	log "github.com/sirupsen/logrus"
 )
 
 func flattenComputeDisksDemo(disksList []DiskRecord, extraDisks []interface{}) []map[string]interface{} {
@@ -33,20 +33,20 @@ func flattenComputeDisksDemo(disksList []DiskRecord, extraDisks []interface{}) [
 	return res
 }
 
-func flattenCompute(d *schema.ResourceData, compFacts string) error {
+func flattenCompute(ctx context.Context, d *schema.ResourceData, compFacts string) error {
 	// This function expects that compFacts string contains response from API compute/get,
 	// i.e. detailed information about compute instance.
 	//
 	// NOTE: this function modifies ResourceData argument - as such it should never be called
 	// from resourceComputeExists(...) method
 	model := ComputeGetResp{}
-	log.Debugf("flattenCompute: ready to unmarshal string %s", compFacts)
+	logger.Debugf(ctx, "flattenCompute: ready to unmarshal string %s", compFacts)
 	err := json.Unmarshal([]byte(compFacts), &model)
 	if err != nil {
 		return err
 	}
 
-	log.Debugf("flattenCompute: ID %d, RG ID %d", model.ID, model.RgID)
+	logger.Debugf(ctx, "flattenCompute: ID %d, RG ID %d", model.ID, model.RgID)
 
 	d.SetId(fmt.Sprintf("%d", model.ID))
 	// d.Set("compute_id", model.ID) - we should NOT set compute_id in the schema here: if it was set - it is already set, if it wasn't - we shouldn't
@@ -65,6 +65,7 @@ func flattenCompute(d *schema.ResourceData, compFacts string) error {
 		d.Set("image_id", model.ImageID)
 	}
 	d.Set("description", model.Desc)
+	d.Set("tags_all", map[string]string(model.Tags))
 	d.Set("enabled", false)
 	if model.Status == status.Enabled {
 		d.Set("enabled", true)
@@ -93,15 +94,15 @@ func flattenCompute(d *schema.ResourceData, compFacts string) error {
 	//}
 
 	if len(model.Interfaces) > 0 {
-		log.Debugf("flattenCompute: calling parseComputeInterfacesToNetworks for %d interfaces", len(model.Interfaces))
-		if err = d.Set("network", parseComputeInterfacesToNetworks(model.Interfaces)); err != nil {
+		logger.Debugf(ctx, "flattenCompute: calling parseComputeInterfacesToNetworks for %d interfaces", len(model.Interfaces))
+		if err = d.Set("network", parseComputeInterfacesToNetworks(ctx, model.Interfaces)); err != nil {
 			return err
 		}
 	}
 
 	if len(model.OsUsers) > 0 {
-		log.Debugf("flattenCompute: calling parseOsUsers for %d logins", len(model.OsUsers))
-		if err = d.Set("os_users", parseOsUsers(model.OsUsers)); err != nil {
+		logger.Debugf(ctx, "flattenCompute: calling parseOsUsers for %d logins", len(model.OsUsers))
+		if err = d.Set("os_users", parseOsUsers(ctx, model.OsUsers)); err != nil {
 			return err
 		}
 	}
@@ -32,6 +32,8 @@ Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
 
 package kvmvm
 
+import "encoding/json"
+
 type DiskRecord struct {
 	Acl                 map[string]interface{} `json:"acl"`
 	AccountID           int                    `json:"accountId"`
@@ -151,15 +153,41 @@ type ComputeGetResp struct {
 	RgName             string            `json:"rgName"`
 	SnapSets           []SnapSetRecord   `json:"snapSets"`
 	Status             string            `json:"status"`
-	// Tags               []string          `json:"tags"` // Tags were reworked since DECORT 3.7.1
-	TechStatus     string `json:"techStatus"`
-	TotalDiskSize  int    `json:"totalDiskSize"`
-	UpdatedBy      string `json:"updatedBy"`
-	UpdateTime     uint64 `json:"updateTime"`
-	UserManaged    bool   `json:"userManaged"`
-	Vgpus          []int  `json:"vgpus"`
-	VinsConnected  int    `json:"vinsConnected"`
-	VirtualImageID int    `json:"virtualImageId"`
+	Tags               ComputeTags       `json:"tags"`
+	TechStatus         string            `json:"techStatus"`
+	TotalDiskSize      int               `json:"totalDiskSize"`
+	UpdatedBy          string            `json:"updatedBy"`
+	UpdateTime         uint64            `json:"updateTime"`
+	UserManaged        bool              `json:"userManaged"`
+	Vgpus              []int             `json:"vgpus"`
+	VinsConnected      int               `json:"vinsConnected"`
+	VirtualImageID     int               `json:"virtualImageId"`
+}
+
+type ComputeListResp []ComputeGetResp
+
+// ComputeTags are key-value pairs since DECORT 3.7.1. Platforms before 3.7.1 return
+// tags as a list, which is ignored.
+type ComputeTags map[string]string
+
+func (t *ComputeTags) UnmarshalJSON(data []byte) error {
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		*t = ComputeTags{}
+		return nil
+	}
+
+	res := make(ComputeTags, len(raw))
+	for key, value := range raw {
+		if str, ok := value.(string); ok {
+			res[key] = str
+			continue
+		}
+		encoded, _ := json.Marshal(value)
+		res[key] = string(encoded)
+	}
+	*t = res
+	return nil
 }
 
 type OsUserRecord struct {
@@ -190,3 +218,15 @@ type ComputeBriefRecord struct { // this is a brief compute specifiaction as ret
 }
 
 type RgListComputesResp []ComputeBriefRecord
+
+type PfwRecord struct {
+	ID              uint64 `json:"id"`
+	LocalIP         string `json:"localIp"`
+	LocalPort       uint64 `json:"localPort"`
+	Protocol        string `json:"protocol"`
+	PublicPortEnd   uint64 `json:"publicPortEnd"`
+	PublicPortStart uint64 `json:"publicPortStart"`
+	ComputeID       uint64 `json:"vmId"`
+}
+
+type ComputePfwListResp []PfwRecord
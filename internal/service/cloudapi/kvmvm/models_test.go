@@ -0,0 +1,76 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package kvmvm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestComputeTagsUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want ComputeTags
+	}{
+		{name: "key-value", data: `{"env": "prod", "team": "infra"}`, want: ComputeTags{"env": "prod", "team": "infra"}},
+		{name: "empty", data: `{}`, want: ComputeTags{}},
+		{name: "non-string values", data: `{"port": 8080, "enabled": true, "meta": {"a": 1}}`,
+			want: ComputeTags{"port": "8080", "enabled": "true", "meta": `{"a":1}`}},
+		{name: "list before 3.7.1", data: `["env", "prod"]`, want: ComputeTags{}},
+		{name: "null", data: `null`, want: ComputeTags{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got ComputeTags
+			if err := json.Unmarshal([]byte(tc.data), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Unmarshal() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestComputeGetRespTags(t *testing.T) {
+	var compute ComputeGetResp
+	if err := json.Unmarshal([]byte(`{"id": 1, "tags": ["old"], "name": "vm"}`), &compute); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if compute.Name != "vm" || len(compute.Tags) != 0 {
+		t.Errorf("Unmarshal() = name %q, tags %v", compute.Name, compute.Tags)
+	}
+}
@@ -37,7 +37,6 @@ import (
 	"hash/fnv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/statefuncs"
-	log "github.com/sirupsen/logrus"
 
 	"sort"
 
@@ -49,10 +48,8 @@ import (
 
 func networkSubresIPAddreDiffSupperss(key, oldVal, newVal string, d *schema.ResourceData) bool {
 	if newVal != "" && newVal != oldVal {
-		log.Debugf("networkSubresIPAddreDiffSupperss: key=%s, oldVal=%q, newVal=%q -> suppress=FALSE", key, oldVal, newVal)
 		return false
 	}
-	log.Debugf("networkSubresIPAddreDiffSupperss: key=%s, oldVal=%q, newVal=%q -> suppress=TRUE", key, oldVal, newVal)
 	return true // suppress difference
 }
 
@@ -33,12 +33,12 @@ Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
 package kvmvm
 
 import (
-	log "github.com/sirupsen/logrus"
+	"context"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-func parseOsUsers(logins []OsUserRecord) []interface{} {
+func parseOsUsers(ctx context.Context, logins []OsUserRecord) []interface{} {
 	var result = make([]interface{}, len(logins))
 
 	for index, value := range logins {
@@ -49,7 +49,7 @@ func parseOsUsers(logins []OsUserRecord) []interface{} {
 		elem["password"] = value.Password
 		elem["public_key"] = value.PubKey
 		result[index] = elem
-		log.Debugf("parseOsUsers: parsed element %d - login %q", index, value.Login)
+		logger.Debugf(ctx, "parseOsUsers: parsed element %d - login %q", index, value.Login)
 	}
 
 	return result
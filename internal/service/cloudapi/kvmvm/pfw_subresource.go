@@ -0,0 +1,304 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package kvmvm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/vins"
+)
+
+// This is subresource of compute resource used when creating/managing compute port forwarding rules
+
+// hashPfwSubresource identifies port_forwarding block by user-provided attributes only,
+// so that computed rule_id and local_ip do not affect set membership
+func hashPfwSubresource(v interface{}) int {
+	pfw := v.(map[string]interface{})
+	start := pfw["public_port_start"].(int)
+	return schema.HashString(fmt.Sprintf("%s-%d-%d-%d",
+		pfw["proto"].(string),
+		start,
+		vins.PortRangeEnd(start, pfw["public_port_end"].(int)),
+		pfw["local_port"].(int)))
+}
+
+func flattenPfw(pfw PfwRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"public_port_start": int(pfw.PublicPortStart),
+		"public_port_end":   int(pfw.PublicPortEnd),
+		"local_port":        int(pfw.LocalPort),
+		"proto":             pfw.Protocol,
+		"local_ip":          pfw.LocalIP,
+		"rule_id":           int(pfw.ID),
+	}
+}
+
+func utilityComputePfwList(ctx context.Context, m interface{}, computeId string) (ComputePfwListResp, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	pfws := ComputePfwListResp{}
+
+	urlValues.Add("computeId", computeId)
+	pfwsRaw, err := c.DecortAPICall(ctx, "POST", ComputePfwListAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+	if pfwsRaw == "" {
+		return pfws, nil
+	}
+
+	err = json.Unmarshal([]byte(pfwsRaw), &pfws)
+	if err != nil {
+		return nil, err
+	}
+	return pfws, nil
+}
+
+// utilityComputePfwRead converges port_forwarding against compute/pfwList. In exclusive
+// mode every rule of the compute is reported, so that undeclared rules are removed on
+// the next apply. Otherwise only rules already tracked in the state are kept.
+func utilityComputePfwRead(ctx context.Context, d *schema.ResourceData, m interface{}) error {
+	tracked := d.Get("port_forwarding").(*schema.Set)
+	exclusive := d.Get("port_forwarding_exclusive").(bool)
+	if tracked.Len() == 0 && !exclusive {
+		return nil
+	}
+
+	pfws, err := utilityComputePfwList(ctx, m, d.Id())
+	if err != nil {
+		return err
+	}
+
+	res := schema.NewSet(hashPfwSubresource, []interface{}{})
+	for _, pfw := range pfws {
+		rule := flattenPfw(pfw)
+		if exclusive || tracked.Contains(rule) {
+			res.Add(rule)
+		}
+	}
+
+	logger.Debugf(ctx, "utilityComputePfwRead: compute %s has %d port forwarding rules, %d tracked", d.Id(), len(pfws), res.Len())
+	return d.Set("port_forwarding", res)
+}
+
+// utilityComputePfwConfigure converges port forwarding rules of the compute to the declared
+// port_forwarding set. Rules are deleted first to free external ports for the new ones.
+// Declared rules which already exist on the compute are adopted instead of being added again.
+func utilityComputePfwConfigure(ctx context.Context, d *schema.ResourceData, m interface{}) error {
+	c := m.(*controller.ControllerCfg)
+	oldSet, newSet := d.GetChange("port_forwarding")
+	declared := newSet.(*schema.Set)
+	exclusive := d.Get("port_forwarding_exclusive").(bool)
+
+	pfws, err := utilityComputePfwList(ctx, m, d.Id())
+	if err != nil {
+		return err
+	}
+
+	existing := schema.NewSet(hashPfwSubresource, []interface{}{})
+	for _, pfw := range pfws {
+		rule := flattenPfw(pfw)
+		if declared.Contains(rule) {
+			existing.Add(rule)
+			continue
+		}
+		if !exclusive && !oldSet.(*schema.Set).Contains(rule) {
+			continue
+		}
+
+		logger.Debugf(ctx, "utilityComputePfwConfigure: deleting rule %d from compute %s", pfw.ID, d.Id())
+		urlValues := &url.Values{}
+		urlValues.Add("computeId", d.Id())
+		urlValues.Add("ruleId", strconv.FormatUint(pfw.ID, 10))
+		if _, err := c.DecortAPICall(ctx, "POST", ComputePfwDelAPI, urlValues); err != nil {
+			return fmt.Errorf("cannot delete port forwarding rule %d of compute %s: %w", pfw.ID, d.Id(), err)
+		}
+	}
+
+	for _, ruleRaw := range declared.Difference(existing).List() {
+		rule := ruleRaw.(map[string]interface{})
+
+		logger.Debugf(ctx, "utilityComputePfwConfigure: adding rule %v to compute %s", rule, d.Id())
+		urlValues := &url.Values{}
+		urlValues.Add("computeId", d.Id())
+		urlValues.Add("publicPortStart", strconv.Itoa(rule["public_port_start"].(int)))
+		if rule["public_port_end"].(int) != 0 {
+			urlValues.Add("publicPortEnd", strconv.Itoa(rule["public_port_end"].(int)))
+		}
+		urlValues.Add("localBasePort", strconv.Itoa(rule["local_port"].(int)))
+		urlValues.Add("proto", rule["proto"].(string))
+		if _, err := c.DecortAPICall(ctx, "POST", ComputePfwAddAPI, urlValues); err != nil {
+			return fmt.Errorf("cannot add port forwarding rule %d -> %d/%s to compute %s: %w",
+				rule["public_port_start"].(int), rule["local_port"].(int), rule["proto"].(string), d.Id(), err)
+		}
+	}
+
+	return nil
+}
+
+// utilityComputePfwCheckConflicts detects overlapping public port ranges at plan time:
+// between declared rules and between declared rules and NAT rules which already exist on
+// the ViNSes the compute is connected to, but are not managed by this resource.
+func utilityComputePfwCheckConflicts(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c := m.(*controller.ControllerCfg)
+	declared := d.Get("port_forwarding").(*schema.Set)
+	rules := declared.List()
+
+	for i, ruleRaw := range rules {
+		rule := ruleRaw.(map[string]interface{})
+		start, end := rule["public_port_start"].(int), rule["public_port_end"].(int)
+		if end != 0 && end < start {
+			return fmt.Errorf("port_forwarding with public_port_start %d has public_port_end %d less than start", start, end)
+		}
+
+		for _, otherRaw := range rules[i+1:] {
+			other := otherRaw.(map[string]interface{})
+			if vins.PortRangesOverlap(rule["proto"].(string), start, end,
+				other["proto"].(string), other["public_port_start"].(int), other["public_port_end"].(int)) {
+				return fmt.Errorf("port_forwarding public ports %d-%d/%s overlap with %d-%d/%s",
+					start, vins.PortRangeEnd(start, end), rule["proto"].(string),
+					other["public_port_start"].(int), vins.PortRangeEnd(other["public_port_start"].(int), other["public_port_end"].(int)), other["proto"].(string))
+			}
+		}
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	computeId, _ := strconv.ParseUint(d.Id(), 10, 64)
+	exclusive := d.Get("port_forwarding_exclusive").(bool)
+	oldSet, _ := d.GetChange("port_forwarding")
+
+	for _, netRaw := range d.Get("network").(*schema.Set).List() {
+		net := netRaw.(map[string]interface{})
+		// ViNS ID may be unknown at plan time if the ViNS is created in the same run
+		if strings.ToUpper(net["net_type"].(string)) != "VINS" || net["net_id"].(int) == 0 {
+			continue
+		}
+
+		urlValues := &url.Values{}
+		urlValues.Add("vinsId", strconv.Itoa(net["net_id"].(int)))
+		natRulesRaw, err := c.DecortAPICall(ctx, "POST", vins.VinsNatRuleListAPI, urlValues)
+		if err != nil {
+			return err
+		}
+
+		natRules := vins.NATRuleList{}
+		if err := json.Unmarshal([]byte(natRulesRaw), &natRules); err != nil {
+			return err
+		}
+
+		for _, natRule := range natRules {
+			if natRule.VMID == computeId && computeId != 0 {
+				own := flattenPfw(PfwRecord{
+					PublicPortStart: natRule.PublicPortStart,
+					PublicPortEnd:   natRule.PublicPortEnd,
+					LocalPort:       natRule.LocalPort,
+					Protocol:        natRule.Protocol,
+				})
+				// own rules are either managed here or removed in exclusive mode
+				if exclusive || oldSet.(*schema.Set).Contains(own) || declared.Contains(own) {
+					continue
+				}
+			}
+
+			for _, ruleRaw := range rules {
+				rule := ruleRaw.(map[string]interface{})
+				if vins.PortRangesOverlap(rule["proto"].(string), rule["public_port_start"].(int), rule["public_port_end"].(int),
+					natRule.Protocol, int(natRule.PublicPortStart), int(natRule.PublicPortEnd)) {
+					return fmt.Errorf("port_forwarding public ports %d-%d/%s overlap with rule %d (%s:%d, compute %d) on vins %d",
+						rule["public_port_start"].(int), vins.PortRangeEnd(rule["public_port_start"].(int), rule["public_port_end"].(int)), rule["proto"].(string),
+						natRule.ID, natRule.LocalIP, natRule.LocalPort, natRule.VMID, net["net_id"].(int))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func pfwSubresourceSchemaMake() map[string]*schema.Schema {
+	rets := map[string]*schema.Schema{
+		"public_port_start": {
+			Type:         schema.TypeInt,
+			Required:     true,
+			ValidateFunc: validation.IntBetween(1, 65535),
+			Description:  "External start port number for the rule.",
+		},
+
+		"public_port_end": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			ValidateFunc: validation.IntBetween(1, 65535),
+			Description:  "End port number (inclusive) for the ranged rule. Equals to public_port_start if not set.",
+		},
+
+		"local_port": {
+			Type:         schema.TypeInt,
+			Required:     true,
+			ValidateFunc: validation.IntBetween(1, 65535),
+			Description:  "Internal base port number.",
+		},
+
+		"proto": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "tcp",
+			ValidateFunc: validation.StringInSlice([]string{"tcp", "udp"}, false),
+			Description:  "Network protocol, either 'tcp' or 'udp'.",
+		},
+
+		"local_ip": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "IP address of compute instance.",
+		},
+
+		"rule_id": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "ID of the rule.",
+		},
+	}
+	return rets
+}
@@ -37,13 +37,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"reflect"
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/dc"
+	"github.com/rudecs/terraform-provider-decort/internal/quota"
 	"github.com/rudecs/terraform-provider-decort/internal/statefuncs"
 	"github.com/rudecs/terraform-provider-decort/internal/status"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -56,10 +58,8 @@ func cloudInitDiffSupperss(key, oldVal, newVal string, d *schema.ResourceData) b
 		// and there is a chance that the user will want custom cloud init parameters - so we check if
 		// cloud_init is explicitly set in TF file by making sure that its new value is different from "applied",
 		// which is a reserved key word.
-		log.Debugf("cloudInitDiffSupperss: key=%s, oldVal=%q, newVal=%q -> suppress=FALSE", key, oldVal, newVal)
 		return false // there is a difference between stored and new value
 	}
-	log.Debugf("cloudInitDiffSupperss: key=%s, oldVal=%q, newVal=%q -> suppress=TRUE", key, oldVal, newVal)
 	return true // suppress difference
 }
 
@@ -67,7 +67,7 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 	// we assume all mandatory parameters it takes to create a comptue instance are properly
 	// specified - we rely on schema "Required" attributes to let Terraform validate them for us
 
-	log.Debugf("resourceComputeCreate: called for Compute name %q, RG ID %d", d.Get("name").(string), d.Get("rg_id").(int))
+	logger.Debugf(ctx, "resourceComputeCreate: called for Compute name %q, RG ID %d", d.Get("name").(string), d.Get("rg_id").(int))
 
 	// create basic Compute (i.e. without extra disks and network connections - those will be attached
 	// by subsequent individual API calls).
@@ -132,9 +132,9 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 	driver := d.Get("driver").(string)
 	if driver == "KVM_PPC" {
 		computeCreateAPI = KvmPPCCreateAPI
-		log.Debugf("resourceComputeCreate: creating Compute of type KVM VM PowerPC")
+		logger.Debugf(ctx, "resourceComputeCreate: creating Compute of type KVM VM PowerPC")
 	} else { // note that we do not validate arch value for explicit "KVM_X86" here
-		log.Debugf("resourceComputeCreate: creating Compute of type KVM VM x86")
+		logger.Debugf(ctx, "resourceComputeCreate: creating Compute of type KVM VM x86")
 	}
 
 	argVal, argSet = d.GetOk("cloud_init")
@@ -165,23 +165,23 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 			urlValues.Add("detachDisks", "1")
 
 			if _, err := c.DecortAPICall(ctx, "POST", ComputeDeleteAPI, urlValues); err != nil {
-				log.Errorf("resourceComputeCreate: could not delete compute after failed creation: %v", err)
+				logger.Errorf(ctx, "resourceComputeCreate: could not delete compute after failed creation: %v", err)
 			}
 			d.SetId("")
 			urlValues = &url.Values{}
 		}
 	}()
 
-	log.Debugf("resourceComputeCreate: new simple Compute ID %d, name %s created", compId, d.Get("name").(string))
+	logger.Debugf(ctx, "resourceComputeCreate: new simple Compute ID %d, name %s created", compId, d.Get("name").(string))
 
 	// Configure data disks if any
 	argVal, argSet = d.GetOk("extra_disks")
 	if argSet && argVal.(*schema.Set).Len() > 0 {
 		// urlValues.Add("desc", argVal.(string))
-		log.Debugf("resourceComputeCreate: calling utilityComputeExtraDisksConfigure to attach %d extra disk(s)", argVal.(*schema.Set).Len())
+		logger.Debugf(ctx, "resourceComputeCreate: calling utilityComputeExtraDisksConfigure to attach %d extra disk(s)", argVal.(*schema.Set).Len())
 		err = utilityComputeExtraDisksConfigure(ctx, d, m, false) // do_delta=false, as we are working on a new compute
 		if err != nil {
-			log.Errorf("resourceComputeCreate: error when attaching extra disk(s) to a new Compute ID %d: %v", compId, err)
+			logger.Errorf(ctx, "resourceComputeCreate: error when attaching extra disk(s) to a new Compute ID %d: %v", compId, err)
 			cleanup = true
 			return diag.FromErr(err)
 		}
@@ -189,10 +189,22 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 	// Configure external networks if any
 	argVal, argSet = d.GetOk("network")
 	if argSet && argVal.(*schema.Set).Len() > 0 {
-		log.Debugf("resourceComputeCreate: calling utilityComputeNetworksConfigure to attach %d network(s)", argVal.(*schema.Set).Len())
+		logger.Debugf(ctx, "resourceComputeCreate: calling utilityComputeNetworksConfigure to attach %d network(s)", argVal.(*schema.Set).Len())
 		err = utilityComputeNetworksConfigure(ctx, d, m, false, true) // do_delta=false, as we are working on a new compute
 		if err != nil {
-			log.Errorf("resourceComputeCreate: error when attaching networks to a new Compute ID %d: %s", compId, err)
+			logger.Errorf(ctx, "resourceComputeCreate: error when attaching networks to a new Compute ID %d: %s", compId, err)
+			cleanup = true
+			return diag.FromErr(err)
+		}
+	}
+
+	// Configure port forwarding rules if any
+	argVal, argSet = d.GetOk("port_forwarding")
+	if argSet && argVal.(*schema.Set).Len() > 0 {
+		logger.Debugf(ctx, "resourceComputeCreate: calling utilityComputePfwConfigure to add %d port forwarding rule(s)", argVal.(*schema.Set).Len())
+		err = utilityComputePfwConfigure(ctx, d, m)
+		if err != nil {
+			logger.Errorf(ctx, "resourceComputeCreate: error when adding port forwarding rules to a new Compute ID %d: %s", compId, err)
 			cleanup = true
 			return diag.FromErr(err)
 		}
@@ -203,7 +215,7 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 	if d.Get("started").(bool) {
 		reqValues := &url.Values{}
 		reqValues.Add("computeId", fmt.Sprintf("%d", compId))
-		log.Debugf("resourceComputeCreate: starting Compute ID %d after completing its resource configuration", compId)
+		logger.Debugf(ctx, "resourceComputeCreate: starting Compute ID %d after completing its resource configuration", compId)
 		if _, err := c.DecortAPICall(ctx, "POST", ComputeStartAPI, reqValues); err != nil {
 			cleanup = true
 			return diag.FromErr(err)
@@ -217,7 +229,7 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 		}
 		urlValues := &url.Values{}
 		urlValues.Add("computeId", fmt.Sprintf("%d", compId))
-		log.Debugf("resourceComputeCreate: enable=%t Compute ID %d after completing its resource configuration", compId, enabled)
+		logger.Debugf(ctx, "resourceComputeCreate: enable=%t Compute ID %d after completing its resource configuration", enabled, compId)
 		if _, err := c.DecortAPICall(ctx, "POST", api, urlValues); err != nil {
 			return diag.FromErr(err)
 		}
@@ -238,7 +250,7 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 		}
 
 		if disks, ok := d.GetOk("disks"); ok {
-			log.Debugf("resourceComputeCreate: Create disks on ComputeID: %d", compId)
+			logger.Debugf(ctx, "resourceComputeCreate: Create disks on ComputeID: %d", compId)
 			addedDisks := disks.([]interface{})
 			if len(addedDisks) > 0 {
 				for _, disk := range addedDisks {
@@ -273,7 +285,7 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 		}
 
 		if ars, ok := d.GetOk("affinity_rules"); ok {
-			log.Debugf("resourceComputeCreate: Create affinity rules on ComputeID: %d", compId)
+			logger.Debugf(ctx, "resourceComputeCreate: Create affinity rules on ComputeID: %d", compId)
 			addedAR := ars.([]interface{})
 			if len(addedAR) > 0 {
 				for _, ar := range addedAR {
@@ -296,7 +308,7 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 		}
 
 		if ars, ok := d.GetOk("anti_affinity_rules"); ok {
-			log.Debugf("resourceComputeCreate: Create anti affinity rules on ComputeID: %d", compId)
+			logger.Debugf(ctx, "resourceComputeCreate: Create anti affinity rules on ComputeID: %d", compId)
 			addedAR := ars.([]interface{})
 			if len(addedAR) > 0 {
 				for _, ar := range addedAR {
@@ -317,9 +329,17 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 				}
 			}
 		}
+
+		if tags, ok := d.GetOk("tags_all"); ok && len(tags.(map[string]interface{})) > 0 {
+			logger.Debugf(ctx, "resourceComputeCreate: Add %d tags on ComputeID: %d", len(tags.(map[string]interface{})), compId)
+			if err := utilityComputeTagsConfigure(ctx, d, m); err != nil {
+				cleanup = true
+				return diag.FromErr(err)
+			}
+		}
 	}
 
-	log.Debugf("resourceComputeCreate: new Compute ID %d, name %s creation sequence complete", compId, d.Get("name").(string))
+	logger.Debugf(ctx, "resourceComputeCreate: new Compute ID %d, name %s creation sequence complete", compId, d.Get("name").(string))
 
 	// We may reuse dataSourceComputeRead here as we maintain similarity
 	// between Compute resource and Compute data source schemas
@@ -329,7 +349,7 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 }
 
 func resourceComputeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceComputeRead: called for Compute name %s, RG ID %d",
+	logger.Debugf(ctx, "resourceComputeRead: called for Compute name %s, RG ID %d",
 		d.Get("name").(string), d.Get("rg_id").(int))
 
 	c := m.(*controller.ControllerCfg)
@@ -346,7 +366,7 @@ func resourceComputeRead(ctx context.Context, d *schema.ResourceData, m interfac
 	compute := &ComputeGetResp{}
 	err = json.Unmarshal([]byte(compFacts), compute)
 
-	log.Debugf("resourceComputeRead: compute is: %+v", compute)
+	logger.Debugf(ctx, "resourceComputeRead: compute is: %+v", compute)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -367,7 +387,7 @@ func resourceComputeRead(ctx context.Context, d *schema.ResourceData, m interfac
 		d.SetId("")
 		return resourceComputeCreate(ctx, d, m)
 	case status.Disabled:
-		log.Debugf("The compute is in status: %s, may troubles can be occured with update. Please, enable compute first.", compute.Status)
+		logger.Debugf(ctx, "The compute is in status: %s, may troubles can be occured with update. Please, enable compute first.", compute.Status)
 	case status.Redeploying:
 	case status.Deleting:
 	case status.Destroying:
@@ -377,7 +397,7 @@ func resourceComputeRead(ctx context.Context, d *schema.ResourceData, m interfac
 	}
 
 	compFacts, err = utilityComputeCheckPresence(ctx, d, m)
-	log.Debugf("resourceComputeRead: after changes compute is: %s", compFacts)
+	logger.Debugf(ctx, "resourceComputeRead: after changes compute is: %s", compFacts)
 	if compFacts == "" {
 		if err != nil {
 			return diag.FromErr(err)
@@ -386,18 +406,32 @@ func resourceComputeRead(ctx context.Context, d *schema.ResourceData, m interfac
 		return nil
 	}
 
-	if err = flattenCompute(d, compFacts); err != nil {
+	tags := d.Get("tags").(map[string]interface{})
+	if err = flattenCompute(ctx, d, compFacts); err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("tags", c.ResourceTags(d.Get("tags_all").(map[string]interface{}), tags))
+
+	if err = utilityComputePfwRead(ctx, d, m); err != nil {
 		return diag.FromErr(err)
 	}
 
-	log.Debugf("resourceComputeRead: after flattenCompute: Compute ID %s, name %q, RG ID %d",
+	warnings := dc.Warnings{}
+	customFields, err := utilityComputeCustomFields(ctx, d, m)
+	if err != nil {
+		warnings.Add(fmt.Errorf("cannot get custom fields of compute %s: %w", d.Id(), err))
+	} else {
+		d.Set("custom_fields", customFields)
+	}
+
+	logger.Debugf(ctx, "resourceComputeRead: after flattenCompute: Compute ID %s, name %q, RG ID %d",
 		d.Id(), d.Get("name").(string), d.Get("rg_id").(int))
 
-	return nil
+	return warnings.Get()
 }
 
 func resourceComputeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceComputeUpdate: called for Compute ID %s / name %s, RGID %d",
+	logger.Debugf(ctx, "resourceComputeUpdate: called for Compute ID %s / name %s, RGID %d",
 		d.Id(), d.Get("name").(string), d.Get("rg_id").(int))
 
 	c := m.(*controller.ControllerCfg)
@@ -420,7 +454,7 @@ func resourceComputeUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		}
 		urlValues := &url.Values{}
 		urlValues.Add("computeId", d.Id())
-		log.Debugf("resourceComputeUpdate: enable=%t Compute ID %s after completing its resource configuration", d.Id(), enabled)
+		logger.Debugf(ctx, "resourceComputeUpdate: enable=%t Compute ID %s after completing its resource configuration", enabled, d.Id())
 		if _, err := c.DecortAPICall(ctx, "POST", api, urlValues); err != nil {
 			return diag.FromErr(err)
 		}
@@ -443,7 +477,7 @@ func resourceComputeUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		d.SetId("")
 		return resourceComputeCreate(ctx, d, m)
 	case status.Disabled:
-		log.Debugf("The compute is in status: %s, may troubles can be occured with update. Please, enable compute first.", compute.Status)
+		logger.Debugf(ctx, "The compute is in status: %s, may troubles can be occured with update. Please, enable compute first.", compute.Status)
 	case status.Redeploying:
 	case status.Deleting:
 	case status.Destroying:
@@ -482,7 +516,7 @@ func resourceComputeUpdate(ctx context.Context, d *schema.ResourceData, m interf
 	}
 
 	if doUpdate {
-		log.Debugf("resourceComputeUpdate: changing CPU %d -> %d and/or RAM %d -> %d",
+		logger.Debugf(ctx, "resourceComputeUpdate: changing CPU %d -> %d and/or RAM %d -> %d",
 			oldCpu.(int), newCpu.(int),
 			oldRam.(int), newRam.(int))
 		urlValues.Add("force", "true")
@@ -498,14 +532,14 @@ func resourceComputeUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		bdsParams := &url.Values{}
 		bdsParams.Add("diskId", fmt.Sprintf("%d", d.Get("boot_disk_id").(int)))
 		bdsParams.Add("size", fmt.Sprintf("%d", newSize.(int)))
-		log.Debugf("resourceComputeUpdate: compute ID %s, boot disk ID %d resize %d -> %d",
+		logger.Debugf(ctx, "resourceComputeUpdate: compute ID %s, boot disk ID %d resize %d -> %d",
 			d.Id(), d.Get("boot_disk_id").(int), oldSize.(int), newSize.(int))
 		_, err := c.DecortAPICall(ctx, "POST", DisksResizeAPI, bdsParams)
 		if err != nil {
 			return diag.FromErr(err)
 		}
 	} else if oldSize.(int) > newSize.(int) {
-		log.Warnf("resourceComputeUpdate: compute ID %s - shrinking boot disk is not allowed", d.Id())
+		logger.Warnf(ctx, "resourceComputeUpdate: compute ID %s - shrinking boot disk is not allowed", d.Id())
 	}
 
 	// 3. Calculate and apply changes to data disks
@@ -522,6 +556,14 @@ func resourceComputeUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		return diag.FromErr(err)
 	}
 
+	// 5. Calculate and apply changes to port forwarding rules
+	if d.HasChange("port_forwarding") || d.HasChange("port_forwarding_exclusive") {
+		err = utilityComputePfwConfigure(ctx, d, m)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	if d.HasChange("description") || d.HasChange("name") {
 		updateParams := &url.Values{}
 		updateParams.Add("computeId", d.Id())
@@ -532,6 +574,12 @@ func resourceComputeUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		}
 	}
 
+	if d.HasChange("tags_all") {
+		if err := utilityComputeTagsConfigure(ctx, d, m); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	if d.HasChange("started") {
 		params := &url.Values{}
 		params.Add("computeId", d.Id())
@@ -814,12 +862,47 @@ func isContainsAR(els []interface{}, el interface{}) bool {
 	return false
 }
 
+func resourceComputeCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c := m.(*controller.ControllerCfg)
+
+	// naming policy applies to new and renamed objects only
+	if (d.Id() == "" || d.HasChange("name")) && d.NewValueKnown("name") {
+		if err := c.CheckName(d.Get("name").(string)); err != nil {
+			return err
+		}
+	}
+
+	// provider default tags are merged here, so that their changes show as in-place
+	// updates of every compute
+	if d.NewValueKnown("tags") {
+		tagsAll := c.MergeTags(d.Get("tags").(map[string]interface{}))
+		if d.Id() == "" || !reflect.DeepEqual(tagsAll, d.Get("tags_all").(map[string]interface{})) {
+			if err := d.SetNew("tags_all", tagsAll); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := d.SetNewComputed("tags_all"); err != nil {
+			return err
+		}
+	}
+
+	if err := quota.Check(ctx, m, computeQuotaKey(d), 0, d.Get("rg_id").(int), utilityComputeQuotaUsage(d)); err != nil {
+		return err
+	}
+
+	if d.HasChange("port_forwarding") || d.HasChange("port_forwarding_exclusive") || d.HasChange("network") {
+		return utilityComputePfwCheckConflicts(ctx, d, m)
+	}
+	return nil
+}
+
 func resourceComputeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	// NOTE: this function destroys target Compute instance "permanently", so
 	// there is no way to restore it.
 	// If compute being destroyed has some extra disks attached, they are
 	// detached from the compute
-	log.Debugf("resourceComputeDelete: called for Compute name %s, RG ID %d",
+	logger.Debugf(ctx, "resourceComputeDelete: called for Compute name %s, RG ID %d",
 		d.Get("name").(string), d.Get("rg_id").(int))
 
 	c := m.(*controller.ControllerCfg)
@@ -1077,6 +1160,47 @@ func ResourceComputeSchemaMake() map[string]*schema.Schema {
 			Description: "Optional network connection(s) for this compute. You may specify several network blocks, one for each connection.",
 		},
 
+		"port_forwarding": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Set:      hashPfwSubresource,
+			Elem: &schema.Resource{
+				Schema: pfwSubresourceSchemaMake(),
+			},
+			Description: "Optional port forwarding rule(s) for this compute. Compute must be connected to a ViNS.",
+		},
+
+		"tags": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+			Description: "Key-value tags of this compute instance, e.g. owner, cost centre or environment.",
+		},
+
+		"tags_all": {
+			Type:     schema.TypeMap,
+			Computed: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+			Description: "All tags of this compute instance, including provider default tags.",
+		},
+
+		"custom_fields": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Custom fields of this compute instance in JSON format.",
+		},
+
+		"port_forwarding_exclusive": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "If true, port forwarding rules of this compute not declared in port_forwarding are removed.",
+		},
+
 		/*
 			"ssh_keys": {
 				Type:     schema.TypeList,
@@ -1178,11 +1302,13 @@ func ResourceCompute() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
 
-		CreateContext: resourceComputeCreate,
+		CreateContext: quota.WithWarnings(resourceComputeCreate, computeQuotaKey),
 		ReadContext:   resourceComputeRead,
-		UpdateContext: resourceComputeUpdate,
+		UpdateContext: quota.WithWarnings(resourceComputeUpdate, computeQuotaKey),
 		DeleteContext: resourceComputeDelete,
 
+		CustomizeDiff: resourceComputeCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
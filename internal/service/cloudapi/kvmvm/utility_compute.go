@@ -38,9 +38,10 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
+	"github.com/rudecs/terraform-provider-decort/internal/quota"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -56,7 +57,7 @@ func utilityComputeExtraDisksConfigure(ctx context.Context, d *schema.ResourceDa
 	// disks via atomic API calls. However, it will not retry failed manipulation on the same disk.
 	c := m.(*controller.ControllerCfg)
 
-	log.Debugf("utilityComputeExtraDisksConfigure: called for Compute ID %s with do_delta = %t", d.Id(), do_delta)
+	logger.Debugf(ctx, "utilityComputeExtraDisksConfigure: called for Compute ID %s with do_delta = %t", d.Id(), do_delta)
 
 	// NB: as of rc-1.25 "extra_disks" are TypeSet with the elem of TypeInt
 	old_set, new_set := d.GetChange("extra_disks")
@@ -82,7 +83,7 @@ func utilityComputeExtraDisksConfigure(ctx context.Context, d *schema.ResourceDa
 		}
 
 		if apiErrCount > 0 {
-			log.Errorf("utilityComputeExtraDisksConfigure: there were %d error(s) when attaching disks to Compute ID %s. Last error was: %s",
+			logger.Errorf(ctx, "utilityComputeExtraDisksConfigure: there were %d error(s) when attaching disks to Compute ID %s. Last error was: %s",
 				apiErrCount, d.Id(), lastSavedError)
 			return lastSavedError
 		}
@@ -91,7 +92,7 @@ func utilityComputeExtraDisksConfigure(ctx context.Context, d *schema.ResourceDa
 	}
 
 	detach_set := old_set.(*schema.Set).Difference(new_set.(*schema.Set))
-	log.Debugf("utilityComputeExtraDisksConfigure: detach set has %d items for Compute ID %s", detach_set.Len(), d.Id())
+	logger.Debugf(ctx, "utilityComputeExtraDisksConfigure: detach set has %d items for Compute ID %s", detach_set.Len(), d.Id())
 
 	if detach_set.Len() > 0 {
 		urlValues := &url.Values{}
@@ -108,7 +109,7 @@ func utilityComputeExtraDisksConfigure(ctx context.Context, d *schema.ResourceDa
 			_, err := c.DecortAPICall(ctx, "POST", ComputeDiskDetachAPI, urlValues)
 			if err != nil {
 				// failed to detach disk - there will be partial resource update
-				log.Errorf("utilityComputeExtraDisksConfigure: failed to detach disk ID %d from Compute ID %s: %s", diskId.(int), d.Id(), err)
+				logger.Errorf(ctx, "utilityComputeExtraDisksConfigure: failed to detach disk ID %d from Compute ID %s: %s", diskId.(int), d.Id(), err)
 				apiErrCount++
 				lastSavedError = err
 			}
@@ -123,7 +124,7 @@ func utilityComputeExtraDisksConfigure(ctx context.Context, d *schema.ResourceDa
 	}
 
 	attach_set := new_set.(*schema.Set).Difference(old_set.(*schema.Set))
-	log.Debugf("utilityComputeExtraDisksConfigure: attach set has %d items for Compute ID %s", attach_set.Len(), d.Id())
+	logger.Debugf(ctx, "utilityComputeExtraDisksConfigure: attach set has %d items for Compute ID %s", attach_set.Len(), d.Id())
 	for _, diskId := range attach_set.List() {
 		urlValues := &url.Values{}
 		urlValues.Add("computeId", d.Id())
@@ -131,14 +132,14 @@ func utilityComputeExtraDisksConfigure(ctx context.Context, d *schema.ResourceDa
 		_, err := c.DecortAPICall(ctx, "POST", ComputeDiskAttachAPI, urlValues)
 		if err != nil {
 			// failed to attach disk - there will be partial resource update
-			log.Errorf("utilityComputeExtraDisksConfigure: failed to attach disk ID %d to Compute ID %s: %s", diskId.(int), d.Id(), err)
+			logger.Errorf(ctx, "utilityComputeExtraDisksConfigure: failed to attach disk ID %d to Compute ID %s: %s", diskId.(int), d.Id(), err)
 			apiErrCount++
 			lastSavedError = err
 		}
 	}
 
 	if apiErrCount > 0 {
-		log.Errorf("utilityComputeExtraDisksConfigure: there were %d error(s) when managing disks of Compute ID %s. Last error was: %s",
+		logger.Errorf(ctx, "utilityComputeExtraDisksConfigure: there were %d error(s) when managing disks of Compute ID %s. Last error was: %s",
 			apiErrCount, d.Id(), lastSavedError)
 		return lastSavedError
 	}
@@ -187,7 +188,7 @@ func utilityComputeNetworksConfigure(ctx context.Context, d *schema.ResourceData
 		}
 
 		if apiErrCount > 0 {
-			log.Errorf("utilityComputeNetworksConfigure: there were %d error(s) when managing networks of Compute ID %s. Last error was: %s",
+			logger.Errorf(ctx, "utilityComputeNetworksConfigure: there were %d error(s) when managing networks of Compute ID %s. Last error was: %s",
 				apiErrCount, d.Id(), lastSavedError)
 			return lastSavedError
 		}
@@ -195,7 +196,7 @@ func utilityComputeNetworksConfigure(ctx context.Context, d *schema.ResourceData
 	}
 
 	detach_set := old_set.(*schema.Set).Difference(new_set.(*schema.Set))
-	log.Debugf("utilityComputeNetworksConfigure: detach set has %d items for Compute ID %s", detach_set.Len(), d.Id())
+	logger.Debugf(ctx, "utilityComputeNetworksConfigure: detach set has %d items for Compute ID %s", detach_set.Len(), d.Id())
 	for _, runner := range detach_set.List() {
 		urlValues := &url.Values{}
 		net_data := runner.(map[string]interface{})
@@ -205,7 +206,7 @@ func utilityComputeNetworksConfigure(ctx context.Context, d *schema.ResourceData
 		_, err := c.DecortAPICall(ctx, "POST", ComputeNetDetachAPI, urlValues)
 		if err != nil {
 			// failed to detach this network - there will be partial resource update
-			log.Errorf("utilityComputeNetworksConfigure: failed to detach net ID %d of type %s from Compute ID %s: %s",
+			logger.Errorf(ctx, "utilityComputeNetworksConfigure: failed to detach net ID %d of type %s from Compute ID %s: %s",
 				net_data["net_id"].(int), net_data["net_type"].(string), d.Id(), err)
 			apiErrCount++
 			lastSavedError = err
@@ -213,7 +214,7 @@ func utilityComputeNetworksConfigure(ctx context.Context, d *schema.ResourceData
 	}
 
 	attach_set := new_set.(*schema.Set).Difference(old_set.(*schema.Set))
-	log.Debugf("utilityComputeNetworksConfigure: attach set has %d items for Compute ID %s", attach_set.Len(), d.Id())
+	logger.Debugf(ctx, "utilityComputeNetworksConfigure: attach set has %d items for Compute ID %s", attach_set.Len(), d.Id())
 	for _, runner := range attach_set.List() {
 		urlValues := &url.Values{}
 		net_data := runner.(map[string]interface{})
@@ -226,7 +227,7 @@ func utilityComputeNetworksConfigure(ctx context.Context, d *schema.ResourceData
 		_, err := c.DecortAPICall(ctx, "POST", ComputeNetAttachAPI, urlValues)
 		if err != nil {
 			// failed to attach this network - there will be partial resource update
-			log.Errorf("utilityComputeNetworksConfigure: failed to attach net ID %d of type %s to Compute ID %s: %s",
+			logger.Errorf(ctx, "utilityComputeNetworksConfigure: failed to attach net ID %d of type %s to Compute ID %s: %s",
 				net_data["net_id"].(int), net_data["net_type"].(string), d.Id(), err)
 			apiErrCount++
 			lastSavedError = err
@@ -234,7 +235,7 @@ func utilityComputeNetworksConfigure(ctx context.Context, d *schema.ResourceData
 	}
 
 	if apiErrCount > 0 {
-		log.Errorf("utilityComputeNetworksConfigure: there were %d error(s) when managing networks of Compute ID %s. Last error was: %s",
+		logger.Errorf(ctx, "utilityComputeNetworksConfigure: there were %d error(s) when managing networks of Compute ID %s. Last error was: %s",
 			apiErrCount, d.Id(), lastSavedError)
 		return lastSavedError
 	}
@@ -275,7 +276,7 @@ func utilityComputeCheckPresence(ctx context.Context, d *schema.ResourceData, m
 
 	if idSet {
 		// compute ID is specified, try to get compute instance straight by this ID
-		log.Debugf("utilityComputeCheckPresence: locating compute by its ID %d", theId)
+		logger.Debugf(ctx, "utilityComputeCheckPresence: locating compute by its ID %d", theId)
 		urlValues.Add("computeId", fmt.Sprintf("%d", theId))
 		computeFacts, err := c.DecortAPICall(ctx, "POST", ComputeGetAPI, urlValues)
 		if err != nil {
@@ -302,7 +303,7 @@ func utilityComputeCheckPresence(ctx context.Context, d *schema.ResourceData, m
 		return "", err
 	}
 
-	log.Debugf("utilityComputeCheckPresence: ready to unmarshal string %s", apiResp)
+	logger.Debugf(ctx, "utilityComputeCheckPresence: ready to unmarshal string %s", apiResp)
 
 	computeList := RgListComputesResp{}
 	err = json.Unmarshal([]byte(apiResp), &computeList)
@@ -311,11 +312,11 @@ func utilityComputeCheckPresence(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	// log.Printf("%#v", computeList)
-	log.Debugf("utilityComputeCheckPresence: traversing decoded JSON of length %d", len(computeList))
+	logger.Debugf(ctx, "utilityComputeCheckPresence: traversing decoded JSON of length %d", len(computeList))
 	for index, item := range computeList {
 		// need to match Compute by name, skip Computes with the same name in DESTROYED satus
 		if item.Name == computeName.(string) && item.Status != "DESTROYED" {
-			log.Debugf("utilityComputeCheckPresence: index %d, matched name %s", index, item.Name)
+			logger.Debugf(ctx, "utilityComputeCheckPresence: index %d, matched name %s", index, item.Name)
 			// we found the Compute we need - now get detailed information via compute/get API
 			cgetValues := &url.Values{}
 			cgetValues.Add("computeId", fmt.Sprintf("%d", item.ID))
@@ -329,3 +330,89 @@ func utilityComputeCheckPresence(ctx context.Context, d *schema.ResourceData, m
 
 	return "", nil // there should be no error if Compute does not exist
 }
+
+func utilityComputeTagsConfigure(ctx context.Context, d *schema.ResourceData, m interface{}) error {
+	// Tags are key-value pairs since DECORT 3.7.1. Tags removed from the configuration (or with
+	// changed values) are removed first, then new and changed tags are added.
+	// "tags_all" holds resource tags merged with provider default tags, see resourceComputeCustomizeDiff
+	c := m.(*controller.ControllerCfg)
+
+	oldTags, newTags := d.GetChange("tags_all")
+	oldMap := oldTags.(map[string]interface{})
+	newMap := newTags.(map[string]interface{})
+
+	logger.Debugf(ctx, "utilityComputeTagsConfigure: called for Compute ID %s, %d old tag(s), %d new tag(s)", d.Id(), len(oldMap), len(newMap))
+
+	for key, oldValue := range oldMap {
+		if newValue, ok := newMap[key]; ok && newValue == oldValue {
+			continue
+		}
+		urlValues := &url.Values{}
+		urlValues.Add("computeId", d.Id())
+		urlValues.Add("key", key)
+		if _, err := c.DecortAPICall(ctx, "POST", ComputeTagRemoveAPI, urlValues); err != nil {
+			return fmt.Errorf("cannot remove tag %q from compute %s: %w", key, d.Id(), err)
+		}
+	}
+
+	for key, newValue := range newMap {
+		if oldValue, ok := oldMap[key]; ok && oldValue == newValue {
+			continue
+		}
+		urlValues := &url.Values{}
+		urlValues.Add("computeId", d.Id())
+		urlValues.Add("key", key)
+		urlValues.Add("value", newValue.(string))
+		if _, err := c.DecortAPICall(ctx, "POST", ComputeTagAddAPI, urlValues); err != nil {
+			return fmt.Errorf("cannot add tag %q to compute %s: %w", key, d.Id(), err)
+		}
+	}
+
+	return nil
+}
+
+func utilityComputeCustomFields(ctx context.Context, d *schema.ResourceData, m interface{}) (string, error) {
+	// custom fields are arbitrary JSON, so they are returned to the caller as is
+	c := m.(*controller.ControllerCfg)
+
+	urlValues := &url.Values{}
+	urlValues.Add("computeId", d.Id())
+	customFields, err := c.DecortAPICall(ctx, "POST", ComputeGetCustomFieldsAPI, urlValues)
+	if err != nil {
+		return "", err
+	}
+
+	if customFields == "" || customFields == "null" {
+		return "{}", nil
+	}
+	return customFields, nil
+}
+
+func computeQuotaKey(d quota.Resource) string {
+	return quota.Key("kvmvm", d, "rg_id", "name")
+}
+
+// utilityComputeQuotaUsage returns resources the planned change of the compute adds
+func utilityComputeQuotaUsage(d *schema.ResourceDiff) quota.Usage {
+	extIPs := func(networks interface{}) int {
+		count := 0
+		for _, netRaw := range networks.(*schema.Set).List() {
+			if strings.ToUpper(netRaw.(map[string]interface{})["net_type"].(string)) == "EXTNET" {
+				count++
+			}
+		}
+		return count
+	}
+
+	oldCpu, newCpu := d.GetChange("cpu")
+	oldRam, newRam := d.GetChange("ram")
+	oldDisk, newDisk := d.GetChange("boot_disk_size")
+	oldNets, newNets := d.GetChange("network")
+
+	return quota.Usage{
+		CPU:    newCpu.(int) - oldCpu.(int),
+		RAM:    newRam.(int) - oldRam.(int),
+		Disk:   newDisk.(int) - oldDisk.(int),
+		ExtIPs: extIPs(newNets) - extIPs(oldNets),
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package kvmvm
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func utilityComputeListCheckPresence(ctx context.Context, d *schema.ResourceData, m interface{}) (ComputeListResp, error) {
+	computeList := ComputeListResp{}
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+
+	if includeDeleted, ok := d.GetOk("includedeleted"); ok {
+		urlValues.Add("includedeleted", strconv.FormatBool(includeDeleted.(bool)))
+	}
+	if page, ok := d.GetOk("page"); ok {
+		urlValues.Add("page", strconv.Itoa(page.(int)))
+	}
+	if size, ok := d.GetOk("size"); ok {
+		urlValues.Add("size", strconv.Itoa(size.(int)))
+	}
+
+	logger.Debugf(ctx, "utilityComputeListCheckPresence: load compute list")
+	computeListRaw, err := c.DecortAPICall(ctx, "POST", ComputeListAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(computeListRaw), &computeList)
+	if err != nil {
+		return nil, err
+	}
+
+	// the platform does not filter by tags, so computes are filtered here:
+	// a compute matches if it has all of the requested tags with the same values
+	tags := d.Get("tags").(map[string]interface{})
+	if len(tags) == 0 {
+		return computeList, nil
+	}
+
+	res := make(ComputeListResp, 0, len(computeList))
+	for _, compute := range computeList {
+		matched := true
+		for key, value := range tags {
+			if tagValue, ok := compute.Tags[key]; !ok || tagValue != value.(string) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			res = append(res, compute)
+		}
+	}
+
+	return res, nil
+}
@@ -44,6 +44,7 @@ const lbStopAPI = "/restmachine/cloudapi/lb/stop"
 const lbRestartAPI = "/restmachine/cloudapi/lb/restart"
 const lbRestoreAPI = "/restmachine/cloudapi/lb/restore"
 const lbConfigResetAPI = "/restmachine/cloudapi/lb/configReset"
+const lbHighlyAvailableAPI = "/restmachine/cloudapi/lb/highlyAvailable"
 const lbBackendCreateAPI = "/restmachine/cloudapi/lb/backendCreate"
 const lbBackendDeleteAPI = "/restmachine/cloudapi/lb/backendDelete"
 const lbBackendUpdateAPI = "/restmachine/cloudapi/lb/backendUpdate"
@@ -75,6 +75,13 @@ func dataSourceLBRead(ctx context.Context, d *schema.ResourceData, m interface{}
 
 	d.SetId(strconv.FormatUint(lb.ID, 10))
 
+	// only the nodes of an lb in HA mode are switched over, so only their status matters
+	if lb.HAMode {
+		if err := utilityLBNodesTechStatus(ctx, m, lb); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	flattenLB(d, lb)
 
 	return nil
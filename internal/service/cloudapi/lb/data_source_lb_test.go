@@ -0,0 +1,150 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package lb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/kvmvm"
+)
+
+// stubController serves lb/get of an lb with nodes on computes 11 and 12 and compute/get
+// of the nodes
+type stubController struct {
+	sync.Mutex
+	haMode   bool
+	computes map[string]int // compute/get calls by compute ID
+}
+
+func (s *stubController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var resp interface{}
+	switch r.URL.Path {
+	case "/restmachine/cloudapi/accounts/list":
+		resp = []interface{}{}
+	case lbGetAPI:
+		resp = map[string]interface{}{
+			"id":            5,
+			"HAmode":        s.haMode,
+			"primaryNode":   map[string]interface{}{"computeId": 11},
+			"secondaryNode": map[string]interface{}{"computeId": 12},
+		}
+	case kvmvm.ComputeGetAPI:
+		s.computes[r.Form.Get("computeId")]++
+		resp = map[string]interface{}{"id": 11, "techStatus": "STARTED"}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+// testControllerConfigure configures the controller the way the provider does, against the stub
+func testControllerConfigure(t *testing.T, handler http.Handler) *controller.ControllerCfg {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	providerSchema := map[string]*schema.Schema{
+		"default_tags": {Type: schema.TypeMap, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+	}
+	for _, key := range []string{"authenticator", "controller_url", "jwt", "oauth2_url", "user", "password",
+		"app_id", "app_secret", "trace_file", "name_prefix", "name_pattern", "quota_check"} {
+		providerSchema[key] = &schema.Schema{Type: schema.TypeString, Optional: true}
+	}
+	providerSchema["cache_ttl"] = &schema.Schema{Type: schema.TypeInt, Optional: true}
+	providerSchema["allow_unverified_ssl"] = &schema.Schema{Type: schema.TypeBool, Optional: true}
+
+	d := schema.TestResourceDataRaw(t, providerSchema, map[string]interface{}{
+		"authenticator":  "jwt",
+		"controller_url": srv.URL,
+		"jwt":            "jwt",
+		"oauth2_url":     srv.URL,
+	})
+	c, err := controller.ControllerConfigure(context.Background(), d)
+	if err != nil {
+		t.Fatalf("ControllerConfigure() error = %v", err)
+	}
+	return c
+}
+
+func TestDataSourceLBReadNodesTechStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		haMode       bool
+		wantComputes map[string]int
+		wantStatus   string
+	}{
+		{name: "ha_mode disabled", haMode: false, wantComputes: map[string]int{}},
+		{name: "ha_mode enabled", haMode: true, wantComputes: map[string]int{"11": 1, "12": 1}, wantStatus: "STARTED"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &stubController{haMode: tc.haMode, computes: map[string]int{}}
+			c := testControllerConfigure(t, stub)
+			d := schema.TestResourceDataRaw(t, dsLBSchemaMake(), map[string]interface{}{"lb_id": 5})
+
+			if diags := dataSourceLBRead(context.Background(), d, c); diags.HasError() {
+				t.Fatalf("dataSourceLBRead() error = %v", diags)
+			}
+			if len(stub.computes) != len(tc.wantComputes) {
+				t.Errorf("compute/get calls = %v, want %v", stub.computes, tc.wantComputes)
+			}
+			for id, calls := range tc.wantComputes {
+				if stub.computes[id] != calls {
+					t.Errorf("compute/get calls = %v, want %v", stub.computes, tc.wantComputes)
+				}
+			}
+			if status := d.Get("secondary_node.0.tech_status").(string); status != tc.wantStatus {
+				t.Errorf("secondary_node tech_status = %q, want %q", status, tc.wantStatus)
+			}
+		})
+	}
+}
@@ -40,6 +40,7 @@ func flattenNode(node Node) []map[string]interface{} {
 		"guid":        node.GUID,
 		"mgmt_ip":     node.MGMTIp,
 		"network_id":  node.NetworkId,
+		"tech_status": node.TechStatus,
 	}
 
 	temp = append(temp, n)
@@ -57,6 +57,12 @@ func lbResourceSchemaMake() map[string]*schema.Schema {
 		Type:     schema.TypeBool,
 		Required: true,
 	}
+	sch["ha_mode"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Computed:    true,
+		Description: "Enable high availability mode. Once enabled, it cannot be turned off",
+	}
 	sch["desc"] = &schema.Schema{
 		Type:     schema.TypeString,
 		Optional: true,
@@ -300,6 +300,10 @@ func createLBSchema() map[string]*schema.Schema {
 						Type:     schema.TypeInt,
 						Computed: true,
 					},
+					"tech_status": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
 				},
 			},
 		},
@@ -340,6 +344,10 @@ func createLBSchema() map[string]*schema.Schema {
 						Type:     schema.TypeInt,
 						Computed: true,
 					},
+					"tech_status": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
 				},
 			},
 		},
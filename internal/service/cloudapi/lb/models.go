@@ -103,6 +103,7 @@ type Node struct {
 	GUID       string `json:"guid"`
 	MGMTIp     string `json:"mgmtIp"`
 	NetworkId  uint64 `json:"networkId"`
+	TechStatus string `json:"techStatus"`
 }
 
 type Frontend struct {
@@ -33,6 +33,7 @@ package lb
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
 
@@ -40,11 +41,12 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
+	"github.com/rudecs/terraform-provider-decort/internal/status"
+	"github.com/rudecs/terraform-provider-decort/internal/techstatus"
 )
 
 func resourceLBCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBCreate")
+	logger.Debugf(ctx, "resourceLBCreate")
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -66,6 +68,9 @@ func resourceLBCreate(ctx context.Context, d *schema.ResourceData, m interface{}
 	d.SetId(lbId)
 	d.Set("lb_id", lbId)
 
+	// Read overwrites ha_mode with the value of the new lb, so take it from the config first
+	haMode := d.Get("ha_mode").(bool)
+
 	_, err = utilityLBCheckPresence(ctx, d, m)
 	if err != nil {
 		return diag.FromErr(err)
@@ -93,11 +98,19 @@ func resourceLBCreate(ctx context.Context, d *schema.ResourceData, m interface{}
 		urlValues = &url.Values{}
 	}
 
+	if haMode {
+		if err := utilityLBEnableHA(ctx, d, m); err != nil {
+			return diag.FromErr(err)
+		}
+
+		return resourceLBRead(ctx, d, m)
+	}
+
 	return nil
 }
 
 func resourceLBRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBRead")
+	logger.Debugf(ctx, "resourceLBRead")
 
 	lb, err := utilityLBCheckPresence(ctx, d, m)
 	if lb == nil {
@@ -105,6 +118,13 @@ func resourceLBRead(ctx context.Context, d *schema.ResourceData, m interface{})
 		return diag.FromErr(err)
 	}
 
+	// only the nodes of an lb in HA mode are switched over, so only their status matters
+	if lb.HAMode {
+		if err := utilityLBNodesTechStatus(ctx, m, lb); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	d.Set("ha_mode", lb.HAMode)
 	d.Set("backends", flattenLBBackends(lb.Backends))
 	d.Set("created_by", lb.CreatedBy)
@@ -135,7 +155,7 @@ func resourceLBRead(ctx context.Context, d *schema.ResourceData, m interface{})
 }
 
 func resourceLBDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBDelete")
+	logger.Debugf(ctx, "resourceLBDelete")
 
 	lb, err := utilityLBCheckPresence(ctx, d, m)
 	if lb == nil {
@@ -163,7 +183,7 @@ func resourceLBDelete(ctx context.Context, d *schema.ResourceData, m interface{}
 }
 
 func resourceLBEdit(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBEdit")
+	logger.Debugf(ctx, "resourceLBEdit")
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 
@@ -183,6 +203,12 @@ func resourceLBEdit(ctx context.Context, d *schema.ResourceData, m interface{})
 		urlValues = &url.Values{}
 	}
 
+	if d.HasChange("ha_mode") && d.Get("ha_mode").(bool) {
+		if err := utilityLBEnableHA(ctx, d, m); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	if d.HasChange("start") {
 		api := lbStopAPI
 		start := d.Get("start").(bool)
@@ -255,6 +281,43 @@ func resourceLBEdit(ctx context.Context, d *schema.ResourceData, m interface{})
 	return resourceLBRead(ctx, d, m)
 }
 
+func resourceLBImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourceLBImport: called with id %s", d.Id())
+
+	lbId, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return nil, fmt.Errorf("invalid import id %q: lb id must be a number", d.Id())
+	}
+	d.Set("lb_id", lbId)
+
+	lb, err := utilityLBCheckPresence(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+	if lb == nil {
+		return nil, fmt.Errorf("lb with id %d not found", lbId)
+	}
+
+	d.Set("start", lb.TechStatus == techstatus.Started)
+	d.Set("enable", lb.Status == status.Enabled)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceLBCustomizeDiff rejects turning ha_mode off, as the platform cannot disable it
+func resourceLBCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || !d.HasChange("ha_mode") {
+		return nil
+	}
+
+	oldHAMode, newHAMode := d.GetChange("ha_mode")
+	if oldHAMode.(bool) && !newHAMode.(bool) {
+		return fmt.Errorf("ha_mode cannot be disabled for lb %s", d.Id())
+	}
+
+	return nil
+}
+
 func ResourceLB() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
@@ -265,9 +328,11 @@ func ResourceLB() *schema.Resource {
 		DeleteContext: resourceLBDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceLBImport,
 		},
 
+		CustomizeDiff: resourceLBCustomizeDiff,
+
 		Timeouts: &schema.ResourceTimeout{
 			Create:  &constants.Timeout600s,
 			Read:    &constants.Timeout300s,
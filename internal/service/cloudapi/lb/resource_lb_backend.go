@@ -33,6 +33,7 @@ package lb
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -42,11 +43,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceLBBackendCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBBackendCreate")
+	logger.Debugf(ctx, "resourceLBBackendCreate")
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -102,7 +102,7 @@ func resourceLBBackendCreate(ctx context.Context, d *schema.ResourceData, m inte
 }
 
 func resourceLBBackendRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBBackendRead")
+	logger.Debugf(ctx, "resourceLBBackendRead")
 
 	b, err := utilityLBBackendCheckPresence(ctx, d, m)
 	if b == nil {
@@ -130,7 +130,7 @@ func resourceLBBackendRead(ctx context.Context, d *schema.ResourceData, m interf
 }
 
 func resourceLBBackendDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBBackendDelete")
+	logger.Debugf(ctx, "resourceLBBackendDelete")
 
 	lb, err := utilityLBBackendCheckPresence(ctx, d, m)
 	if lb == nil {
@@ -155,7 +155,7 @@ func resourceLBBackendDelete(ctx context.Context, d *schema.ResourceData, m inte
 }
 
 func resourceLBBackendEdit(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBBackendEdit")
+	logger.Debugf(ctx, "resourceLBBackendEdit")
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 
@@ -200,6 +200,28 @@ func resourceLBBackendEdit(ctx context.Context, d *schema.ResourceData, m interf
 	return resourceLBBackendRead(ctx, d, m)
 }
 
+func resourceLBBackendImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourceLBBackendImport: called with id %s", d.Id())
+
+	lbId, names, err := utilityLBParseImportId(d.Id(), 2)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("lb_id", lbId)
+	d.Set("name", names[0])
+
+	b, err := utilityLBBackendCheckPresence(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, fmt.Errorf("backend %s not found", d.Id())
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func ResourceLBBackend() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
@@ -210,7 +232,7 @@ func ResourceLBBackend() *schema.Resource {
 		DeleteContext: resourceLBBackendDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceLBBackendImport,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
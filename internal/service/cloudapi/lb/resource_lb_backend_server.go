@@ -33,6 +33,7 @@ package lb
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -42,11 +43,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceLBBackendServerCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBBackendServerCreate")
+	logger.Debugf(ctx, "resourceLBBackendServerCreate")
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -106,7 +106,7 @@ func resourceLBBackendServerCreate(ctx context.Context, d *schema.ResourceData,
 }
 
 func resourceLBBackendServerRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBBackendServerRead")
+	logger.Debugf(ctx, "resourceLBBackendServerRead")
 
 	s, err := utilityLBBackendServerCheckPresence(ctx, d, m)
 	if s == nil {
@@ -137,7 +137,7 @@ func resourceLBBackendServerRead(ctx context.Context, d *schema.ResourceData, m
 }
 
 func resourceLBBackendServerDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBBackendServerDelete")
+	logger.Debugf(ctx, "resourceLBBackendServerDelete")
 
 	lb, err := utilityLBBackendServerCheckPresence(ctx, d, m)
 	if lb == nil {
@@ -163,7 +163,7 @@ func resourceLBBackendServerDelete(ctx context.Context, d *schema.ResourceData,
 }
 
 func resourceLBBackendServerEdit(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBBackendServerEdit")
+	logger.Debugf(ctx, "resourceLBBackendServerEdit")
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 
@@ -211,6 +211,29 @@ func resourceLBBackendServerEdit(ctx context.Context, d *schema.ResourceData, m
 	return resourceLBBackendServerRead(ctx, d, m)
 }
 
+func resourceLBBackendServerImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourceLBBackendServerImport: called with id %s", d.Id())
+
+	lbId, names, err := utilityLBParseImportId(d.Id(), 3)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("lb_id", lbId)
+	d.Set("backend_name", names[0])
+	d.Set("name", names[1])
+
+	s, err := utilityLBBackendServerCheckPresence(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("server %s not found", d.Id())
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func ResourceLBBackendServer() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
@@ -221,7 +244,7 @@ func ResourceLBBackendServer() *schema.Resource {
 		DeleteContext: resourceLBBackendServerDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceLBBackendServerImport,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
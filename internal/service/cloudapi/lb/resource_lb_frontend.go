@@ -33,6 +33,7 @@ package lb
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -41,11 +42,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceLBFrontendCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBFrontendCreate")
+	logger.Debugf(ctx, "resourceLBFrontendCreate")
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -74,7 +74,7 @@ func resourceLBFrontendCreate(ctx context.Context, d *schema.ResourceData, m int
 }
 
 func resourceLBFrontendRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBFrontendRead")
+	logger.Debugf(ctx, "resourceLBFrontendRead")
 
 	f, err := utilityLBFrontendCheckPresence(ctx, d, m)
 	if f == nil {
@@ -93,7 +93,7 @@ func resourceLBFrontendRead(ctx context.Context, d *schema.ResourceData, m inter
 }
 
 func resourceLBFrontendDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBFrontendDelete")
+	logger.Debugf(ctx, "resourceLBFrontendDelete")
 
 	lb, err := utilityLBFrontendCheckPresence(ctx, d, m)
 	if lb == nil {
@@ -124,6 +124,28 @@ func resourceLBFrontendEdit(ctx context.Context, d *schema.ResourceData, m inter
 	return nil
 }
 
+func resourceLBFrontendImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourceLBFrontendImport: called with id %s", d.Id())
+
+	lbId, names, err := utilityLBParseImportId(d.Id(), 2)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("lb_id", lbId)
+	d.Set("name", names[0])
+
+	f, err := utilityLBFrontendCheckPresence(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, fmt.Errorf("frontend %s not found", d.Id())
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func ResourceLBFrontend() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
@@ -134,7 +156,7 @@ func ResourceLBFrontend() *schema.Resource {
 		DeleteContext: resourceLBFrontendDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceLBFrontendImport,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -33,6 +33,7 @@ package lb
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -41,11 +42,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceLBFrontendBindCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBFrontendBindCreate")
+	logger.Debugf(ctx, "resourceLBFrontendBindCreate")
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -76,7 +76,7 @@ func resourceLBFrontendBindCreate(ctx context.Context, d *schema.ResourceData, m
 }
 
 func resourceLBFrontendBindRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBFrontendBindRead")
+	logger.Debugf(ctx, "resourceLBFrontendBindRead")
 
 	b, err := utilityLBFrontendBindCheckPresence(ctx, d, m)
 	if b == nil {
@@ -98,7 +98,7 @@ func resourceLBFrontendBindRead(ctx context.Context, d *schema.ResourceData, m i
 }
 
 func resourceLBFrontendBindDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBFrontendBindDelete")
+	logger.Debugf(ctx, "resourceLBFrontendBindDelete")
 
 	b, err := utilityLBFrontendBindCheckPresence(ctx, d, m)
 	if b == nil {
@@ -124,7 +124,7 @@ func resourceLBFrontendBindDelete(ctx context.Context, d *schema.ResourceData, m
 }
 
 func resourceLBFrontendBindEdit(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceLBFrontendBindEdit")
+	logger.Debugf(ctx, "resourceLBFrontendBindEdit")
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 
@@ -148,6 +148,29 @@ func resourceLBFrontendBindEdit(ctx context.Context, d *schema.ResourceData, m i
 	return resourceLBFrontendBindRead(ctx, d, m)
 }
 
+func resourceLBFrontendBindImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourceLBFrontendBindImport: called with id %s", d.Id())
+
+	lbId, names, err := utilityLBParseImportId(d.Id(), 3)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("lb_id", lbId)
+	d.Set("frontend_name", names[0])
+	d.Set("name", names[1])
+
+	b, err := utilityLBFrontendBindCheckPresence(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, fmt.Errorf("binding %s not found", d.Id())
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func ResourceLBFrontendBind() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
@@ -158,7 +181,7 @@ func ResourceLBFrontendBind() *schema.Resource {
 		DeleteContext: resourceLBFrontendBindDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceLBFrontendBindImport,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package lb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceLBCustomizeDiffHAMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		state   string
+		config  bool
+		wantErr bool
+	}{
+		{name: "create with ha_mode", config: true},
+		{name: "create without ha_mode", config: false},
+		{name: "enable", id: "123", state: "false", config: true},
+		{name: "keep enabled", id: "123", state: "true", config: true},
+		{name: "keep disabled", id: "123", state: "false", config: false},
+		{name: "disable", id: "123", state: "true", config: false, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var state *terraform.InstanceState
+			if tc.id != "" {
+				state = &terraform.InstanceState{
+					ID: tc.id,
+					Attributes: map[string]string{
+						"id":        tc.id,
+						"name":      "lb",
+						"rg_id":     "1",
+						"extnet_id": "2",
+						"vins_id":   "3",
+						"start":     "true",
+						"ha_mode":   tc.state,
+					},
+				}
+			}
+			config := terraform.NewResourceConfigRaw(map[string]interface{}{
+				"name":      "lb",
+				"rg_id":     1,
+				"extnet_id": 2,
+				"vins_id":   3,
+				"start":     true,
+				"ha_mode":   tc.config,
+			})
+
+			_, err := ResourceLB().Diff(context.Background(), state, config, nil)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Diff() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -37,9 +37,14 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
+	"github.com/rudecs/terraform-provider-decort/internal/parallel"
+	"github.com/rudecs/terraform-provider-decort/internal/service/cloudapi/kvmvm"
+	"github.com/rudecs/terraform-provider-decort/internal/techstatus"
 )
 
 func utilityLBCheckPresence(ctx context.Context, d *schema.ResourceData, m interface{}) (*LoadBalancer, error) {
@@ -68,3 +73,105 @@ func utilityLBCheckPresence(ctx context.Context, d *schema.ResourceData, m inter
 
 	return lb, nil
 }
+
+// utilityLBParseImportId splits the composite ID of an lb subresource,
+// e.g. "<lb_id>#<backend_name>#<server_name>", into the lb ID and the names
+func utilityLBParseImportId(id string, parts int) (int, []string, error) {
+	parameters := strings.Split(id, "#")
+	if len(parameters) != parts {
+		return 0, nil, fmt.Errorf("invalid import id %q: expected %d parts separated by '#'", id, parts)
+	}
+
+	lbId, err := strconv.Atoi(parameters[0])
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid import id %q: lb id must be a number", id)
+	}
+
+	for _, p := range parameters[1:] {
+		if p == "" {
+			return 0, nil, fmt.Errorf("invalid import id %q: names must not be empty", id)
+		}
+	}
+
+	return lbId, parameters[1:], nil
+}
+
+func utilityLBNodeTechStatus(ctx context.Context, m interface{}, computeId uint64) (string, error) {
+	if computeId == 0 {
+		return "", nil
+	}
+
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("computeId", strconv.FormatUint(computeId, 10))
+
+	computeRaw, err := c.DecortAPICall(ctx, "POST", kvmvm.ComputeGetAPI, urlValues)
+	if err != nil {
+		return "", err
+	}
+
+	compute := &kvmvm.ComputeGetResp{}
+	if err := json.Unmarshal([]byte(computeRaw), compute); err != nil {
+		return "", err
+	}
+
+	return compute.TechStatus, nil
+}
+
+// utilityLBNodesTechStatus fills in the tech status of the primary and secondary
+// nodes from the underlying computes when the platform does not report it in lb/get.
+// The computes are read concurrently.
+func utilityLBNodesTechStatus(ctx context.Context, m interface{}, lb *LoadBalancer) error {
+	nodes := []*Node{&lb.PrimaryNode, &lb.SecondaryNode}
+	return parallel.Run(ctx, len(nodes), parallel.Limit, func(ctx context.Context, i int) error {
+		if nodes[i].TechStatus != "" {
+			return nil
+		}
+
+		techStatus, err := utilityLBNodeTechStatus(ctx, m, nodes[i].ComputeId)
+		if err != nil {
+			return err
+		}
+		nodes[i].TechStatus = techStatus
+		return nil
+	})
+}
+
+func utilityLBEnableHA(ctx context.Context, d *schema.ResourceData, m interface{}) error {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("lbId", strconv.Itoa(d.Get("lb_id").(int)))
+
+	_, err := c.DecortAPICall(ctx, "POST", lbHighlyAvailableAPI, urlValues)
+	if err != nil {
+		return err
+	}
+
+	ctx = controller.WithoutCache(ctx)
+	for {
+		lb, err := utilityLBCheckPresence(ctx, d, m)
+		if err != nil {
+			return err
+		}
+		if lb == nil {
+			return fmt.Errorf("lb with id %d not found", d.Get("lb_id").(int))
+		}
+
+		techStatus, err := utilityLBNodeTechStatus(ctx, m, lb.SecondaryNode.ComputeId)
+		if err != nil {
+			return err
+		}
+		logger.Debugf(ctx, "utilityLBEnableHA: lb %d HA mode %t, secondary node %d is %s",
+			lb.ID, lb.HAMode, lb.SecondaryNode.ComputeId, techStatus)
+
+		if lb.HAMode && techStatus == techstatus.Started {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("secondary node of lb %d did not become healthy: %w", lb.ID, ctx.Err())
+		case <-time.After(time.Second * 10):
+		}
+	}
+}
@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -59,7 +58,7 @@ func utilityLBListCheckPresence(ctx context.Context, d *schema.ResourceData, m i
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityLBListCheckPresence: load lb list")
+	logger.Debugf(ctx, "utilityLBListCheckPresence: load lb list")
 	lbListRaw, err := c.DecortAPICall(ctx, "POST", lbListAPI, urlValues)
 	if err != nil {
 		return nil, err
@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -55,7 +54,7 @@ func utilityLBListDeletedCheckPresence(ctx context.Context, d *schema.ResourceDa
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityLBListDeletedCheckPresence: load lb list")
+	logger.Debugf(ctx, "utilityLBListDeletedCheckPresence: load lb list")
 	lbListRaw, err := c.DecortAPICall(ctx, "POST", lbListDeletedAPI, urlValues)
 	if err != nil {
 		return nil, err
@@ -37,13 +37,12 @@ import (
 	"net/url"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func utilityLocationUrlCheckPresence(ctx context.Context, m interface{}) (string, error) {
 	c := m.(*controller.ControllerCfg)
 
-	log.Debugf("utilityLocationUrlCheckPresence: load locations list")
+	logger.Debugf(ctx, "utilityLocationUrlCheckPresence: load locations list")
 	locationUrl, err := c.DecortAPICall(ctx, "POST", locationURLAPI, &url.Values{})
 	if err != nil {
 		return "", err
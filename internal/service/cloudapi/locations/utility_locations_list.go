@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -55,7 +54,7 @@ func utilityLocationsListCheckPresence(ctx context.Context, d *schema.ResourceDa
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityLocationsListCheckPresence: load locations list")
+	logger.Debugf(ctx, "utilityLocationsListCheckPresence: load locations list")
 	locationsListRaw, err := c.DecortAPICall(ctx, "POST", locationsListAPI, urlValues)
 	if err != nil {
 		return nil, err
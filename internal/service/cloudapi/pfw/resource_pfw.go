@@ -36,17 +36,17 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourcePfwCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourcePfwCreate: called for compute %d", d.Get("compute_id").(int))
+	logger.Debugf(ctx, "resourcePfwCreate: called for compute %d", d.Get("compute_id").(int))
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -80,7 +80,7 @@ func resourcePfwCreate(ctx context.Context, d *schema.ResourceData, m interface{
 }
 
 func resourcePfwRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourcePfwRead: called for compute %d, rule %s", d.Get("compute_id").(int), d.Id())
+	logger.Debugf(ctx, "resourcePfwRead: called for compute %d, rule %s", d.Get("compute_id").(int), d.Id())
 
 	pfw, err := utilityPfwCheckPresence(ctx, d, m)
 	if pfw == nil {
@@ -99,7 +99,7 @@ func resourcePfwRead(ctx context.Context, d *schema.ResourceData, m interface{})
 }
 
 func resourcePfwDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourcePfwDelete: called for compute %d, rule %s", d.Get("compute_id").(int), d.Id())
+	logger.Debugf(ctx, "resourcePfwDelete: called for compute %d, rule %s", d.Get("compute_id").(int), d.Id())
 
 	pfw, err := utilityPfwCheckPresence(ctx, d, m)
 	if pfw == nil {
@@ -122,6 +122,35 @@ func resourcePfwDelete(ctx context.Context, d *schema.ResourceData, m interface{
 	return nil
 }
 
+func resourcePfwImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourcePfwImport: called with id %s", d.Id())
+
+	parameters := strings.Split(d.Id(), "-")
+	if len(parameters) != 2 {
+		return nil, fmt.Errorf("invalid import id %q: expected <compute_id>-<rule_id>", d.Id())
+	}
+
+	computeId, err := strconv.Atoi(parameters[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid import id %q: compute id must be a number", d.Id())
+	}
+	if _, err := strconv.Atoi(parameters[1]); err != nil {
+		return nil, fmt.Errorf("invalid import id %q: rule id must be a number", d.Id())
+	}
+
+	d.Set("compute_id", computeId)
+
+	pfw, err := utilityPfwCheckPresence(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+	if pfw == nil {
+		return nil, fmt.Errorf("port forwarding rule %s not found", d.Id())
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourcePfwSchemaMake() map[string]*schema.Schema {
 	return map[string]*schema.Schema{
 		"compute_id": {
@@ -181,7 +210,7 @@ func ResourcePfw() *schema.Resource {
 		DeleteContext: resourcePfwDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourcePfwImport,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
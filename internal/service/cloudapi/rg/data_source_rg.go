@@ -71,7 +71,7 @@ func dataSourceResgroupRead(ctx context.Context, d *schema.ResourceData, m inter
 		d.SetId("") // ensure ID is empty in this case
 		return diag.FromErr(err)
 	}
-	return diag.FromErr(flattenDataResgroup(d, *rg))
+	return diag.FromErr(flattenDataResgroup(ctx, d, *rg))
 }
 
 func DataSourceResgroup() *schema.Resource {
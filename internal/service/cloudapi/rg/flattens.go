@@ -33,10 +33,10 @@ Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
 package rg
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	log "github.com/sirupsen/logrus"
 )
 
 func flattenAccountSeps(seps map[string]map[string]ResourceSep) []map[string]interface{} {
@@ -80,12 +80,12 @@ func flattenRgResources(r Resources) []map[string]interface{} {
 	return res
 }
 
-func flattenDataResgroup(d *schema.ResourceData, details ResgroupGetResp) error {
+func flattenDataResgroup(ctx context.Context, d *schema.ResourceData, details ResgroupGetResp) error {
 	// NOTE: this function modifies ResourceData argument - as such it should never be called
 	// from resourceRsgroupExists(...) method
 	// log.Debugf("%s", rg_facts)
 
-	log.Debugf("flattenResgroup: decoded RG name %q / ID %d, account ID %d",
+	logger.Debugf(ctx, "flattenResgroup: decoded RG name %q / ID %d, account ID %d",
 		details.Name, details.ID, details.AccountID)
 
 	d.SetId(fmt.Sprintf("%d", details.ID))
@@ -101,7 +101,7 @@ func flattenDataResgroup(d *schema.ResourceData, details ResgroupGetResp) error
 	d.Set("resources", flattenRgResources(details.Resources))
 	d.Set("vins", details.Vins)
 	d.Set("vms", details.Computes)
-	log.Debugf("flattenResgroup: calling flattenQuota()")
+	logger.Debugf(ctx, "flattenResgroup: calling flattenQuota()")
 	if err := d.Set("quota", parseQuota(details.Quota)); err != nil {
 		return err
 	}
@@ -109,7 +109,7 @@ func flattenDataResgroup(d *schema.ResourceData, details ResgroupGetResp) error
 	return nil
 }
 
-func flattenResgroup(d *schema.ResourceData, details ResgroupGetResp) error {
+func flattenResgroup(ctx context.Context, d *schema.ResourceData, details ResgroupGetResp) error {
 	// NOTE: this function modifies ResourceData argument - as such it should never be called
 	// from resourceRsgroupExists(...) method
 	// log.Debugf("%s", rg_facts)
@@ -120,7 +120,7 @@ func flattenResgroup(d *schema.ResourceData, details ResgroupGetResp) error {
 	//return err
 	//}
 
-	log.Debugf("flattenResgroup: decoded RG name %q / ID %d, account ID %d",
+	logger.Debugf(ctx, "flattenResgroup: decoded RG name %q / ID %d, account ID %d",
 		details.Name, details.ID, details.AccountID)
 
 	d.SetId(fmt.Sprintf("%d", details.ID))
@@ -136,7 +136,7 @@ func flattenResgroup(d *schema.ResourceData, details ResgroupGetResp) error {
 	d.Set("resources", flattenRgResources(details.Resources))
 	d.Set("vins", details.Vins)
 	d.Set("vms", details.Computes)
-	log.Debugf("flattenResgroup: calling flattenQuota()")
+	logger.Debugf(ctx, "flattenResgroup: calling flattenQuota()")
 	if err := d.Set("quota", parseQuota(details.Quota)); err != nil {
 		return err
 	}
@@ -41,7 +41,7 @@ import (
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
 	"github.com/rudecs/terraform-provider-decort/internal/location"
-	log "github.com/sirupsen/logrus"
+	"github.com/rudecs/terraform-provider-decort/internal/quota"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -59,19 +59,14 @@ func resourceResgroupCreate(ctx context.Context, d *schema.ResourceData, m inter
 		return diag.FromErr(fmt.Errorf("Cannot create new RG: missing name."))
 	}
 
-	/* Current version of provider works with default grid id (same is true for disk resources)
-	grid_id, arg_set := d.GetOk("grid_id")
-	if !arg_set {
-		return fmt.Errorf("Cannot create new RG %q in account ID %d: missing Grid ID.",
-			rg_name.(string), validated_account_id)
-	}
-	if grid_id.(int) < 1 {
-		grid_id = DefaultGridID
+	grid_id, err := location.UtilityLocationResourceGridID(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
 	}
-	*/
+	d.Set("gid", grid_id)
 
 	// all required parameters are set in the schema - we can continue with RG creation
-	log.Debugf("resourceResgroupCreate: called for RG name %s, account ID %d",
+	logger.Debugf(ctx, "resourceResgroupCreate: called for RG name %s, account ID %d",
 		rg_name.(string), d.Get("account_id").(int))
 
 	// quota settings are optional
@@ -79,20 +74,20 @@ func resourceResgroupCreate(ctx context.Context, d *schema.ResourceData, m inter
 	var quota_record QuotaRecord
 	arg_value, arg_set := d.GetOk("quota")
 	if arg_set {
-		log.Debugf("resourceResgroupCreate: setting Quota on RG requested")
+		logger.Debugf(ctx, "resourceResgroupCreate: setting Quota on RG requested")
 		quota_record = makeQuotaRecord(arg_value.([]interface{}))
 		set_quota = true
 	}
 
 	c := m.(*controller.ControllerCfg)
-	log.Debugf("resourceResgroupCreate: called by user %q for RG name %s, account ID %d",
+	logger.Debugf(ctx, "resourceResgroupCreate: called by user %q for RG name %s, account ID %d",
 		c.GetDecortUsername(),
 		rg_name.(string), d.Get("account_id").(int))
 
 	url_values := &url.Values{}
 	url_values.Add("accountId", fmt.Sprintf("%d", d.Get("account_id").(int)))
 	url_values.Add("name", rg_name.(string))
-	url_values.Add("gid", fmt.Sprintf("%d", location.DefaultGridID)) // use default Grid ID, similar to disk resource mgmt convention
+	url_values.Add("gid", fmt.Sprintf("%d", grid_id))
 	url_values.Add("owner", c.GetDecortUsername())
 
 	// pass quota values as set
@@ -147,7 +142,7 @@ func resourceResgroupCreate(ctx context.Context, d *schema.ResourceData, m inter
 }
 
 func resourceResgroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceResgroupRead: called for RG name %s, account ID %d",
+	logger.Debugf(ctx, "resourceResgroupRead: called for RG name %s, account ID %d",
 		d.Get("name").(string), d.Get("account_id").(int))
 
 	rg_facts, err := utilityResgroupCheckPresence(ctx, d, m)
@@ -158,11 +153,11 @@ func resourceResgroupRead(ctx context.Context, d *schema.ResourceData, m interfa
 		return diag.FromErr(err)
 	}
 
-	return diag.FromErr(flattenResgroup(d, *rg_facts))
+	return diag.FromErr(flattenResgroup(ctx, d, *rg_facts))
 }
 
 func resourceResgroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceResgroupUpdate: called for RG name %s, account ID %d",
+	logger.Debugf(ctx, "resourceResgroupUpdate: called for RG name %s, account ID %d",
 		d.Get("name").(string), d.Get("account_id").(int))
 
 	/* NOTE: we do not allow changing the following attributes of an existing RG via terraform:
@@ -193,7 +188,7 @@ func resourceResgroupUpdate(ctx context.Context, d *schema.ResourceData, m inter
 
 	name_new, name_set := d.GetOk("name")
 	if name_set {
-		log.Debugf("resourceResgroupUpdate: name specified - looking for deltas from the old settings.")
+		logger.Debugf(ctx, "resourceResgroupUpdate: name specified - looking for deltas from the old settings.")
 		name_old, _ := d.GetChange("name")
 		if name_old.(string) != name_new.(string) {
 			do_general_update = true
@@ -203,45 +198,45 @@ func resourceResgroupUpdate(ctx context.Context, d *schema.ResourceData, m inter
 
 	quota_value, quota_set := d.GetOk("quota")
 	if quota_set {
-		log.Debugf("resourceResgroupUpdate: quota specified - looking for deltas from the old quota.")
+		logger.Debugf(ctx, "resourceResgroupUpdate: quota specified - looking for deltas from the old quota.")
 		quotarecord_new := makeQuotaRecord(quota_value.([]interface{}))
 		quota_value_old, _ := d.GetChange("quota") // returns old as 1st, new as 2nd return value
 		quotarecord_old := makeQuotaRecord(quota_value_old.([]interface{}))
 
 		if quotarecord_new.Cpu != quotarecord_old.Cpu {
 			do_general_update = true
-			log.Debugf("resourceResgroupUpdate: Cpu diff %d <- %d", quotarecord_new.Cpu, quotarecord_old.Cpu)
+			logger.Debugf(ctx, "resourceResgroupUpdate: Cpu diff %d <- %d", quotarecord_new.Cpu, quotarecord_old.Cpu)
 			url_values.Add("maxCPUCapacity", fmt.Sprintf("%d", quotarecord_new.Cpu))
 		}
 
 		if quotarecord_new.Disk != quotarecord_old.Disk {
 			do_general_update = true
-			log.Debugf("resourceResgroupUpdate: Disk diff %d <- %d", quotarecord_new.Disk, quotarecord_old.Disk)
+			logger.Debugf(ctx, "resourceResgroupUpdate: Disk diff %d <- %d", quotarecord_new.Disk, quotarecord_old.Disk)
 			url_values.Add("maxVDiskCapacity", fmt.Sprintf("%d", quotarecord_new.Disk))
 		}
 
 		if quotarecord_new.Ram != quotarecord_old.Ram { // NB: quota on RAM is stored as float32, in units of MB
 			do_general_update = true
-			log.Debugf("resourceResgroupUpdate: Ram diff %f <- %f", quotarecord_new.Ram, quotarecord_old.Ram)
+			logger.Debugf(ctx, "resourceResgroupUpdate: Ram diff %f <- %f", quotarecord_new.Ram, quotarecord_old.Ram)
 			url_values.Add("maxMemoryCapacity", fmt.Sprintf("%f", quotarecord_new.Ram))
 		}
 
 		if quotarecord_new.ExtTraffic != quotarecord_old.ExtTraffic {
 			do_general_update = true
-			log.Debugf("resourceResgroupUpdate: ExtTraffic diff %d <- %d", quotarecord_new.ExtTraffic, quotarecord_old.ExtTraffic)
+			logger.Debugf(ctx, "resourceResgroupUpdate: ExtTraffic diff %d <- %d", quotarecord_new.ExtTraffic, quotarecord_old.ExtTraffic)
 			url_values.Add("maxNetworkPeerTransfer", fmt.Sprintf("%d", quotarecord_new.ExtTraffic))
 		}
 
 		if quotarecord_new.ExtIPs != quotarecord_old.ExtIPs {
 			do_general_update = true
-			log.Debugf("resourceResgroupUpdate: ExtIPs diff %d <- %d", quotarecord_new.ExtIPs, quotarecord_old.ExtIPs)
+			logger.Debugf(ctx, "resourceResgroupUpdate: ExtIPs diff %d <- %d", quotarecord_new.ExtIPs, quotarecord_old.ExtIPs)
 			url_values.Add("maxNumPublicIP", fmt.Sprintf("%d", quotarecord_new.ExtIPs))
 		}
 	}
 
 	desc_new, desc_set := d.GetOk("description")
 	if desc_set {
-		log.Debugf("resourceResgroupUpdate: description specified - looking for deltas from the old settings.")
+		logger.Debugf(ctx, "resourceResgroupUpdate: description specified - looking for deltas from the old settings.")
 		desc_old, _ := d.GetChange("description")
 		if desc_old.(string) != desc_new.(string) {
 			do_general_update = true
@@ -250,13 +245,13 @@ func resourceResgroupUpdate(ctx context.Context, d *schema.ResourceData, m inter
 	}
 
 	if do_general_update {
-		log.Debugf("resourceResgroupUpdate: detected delta between new and old RG specs - updating the RG")
+		logger.Debugf(ctx, "resourceResgroupUpdate: detected delta between new and old RG specs - updating the RG")
 		_, err := c.DecortAPICall(ctx, "POST", ResgroupUpdateAPI, url_values)
 		if err != nil {
 			return diag.FromErr(err)
 		}
 	} else {
-		log.Debugf("resourceResgroupUpdate: no difference between old and new state - no update on the RG will be done")
+		logger.Debugf(ctx, "resourceResgroupUpdate: no difference between old and new state - no update on the RG will be done")
 	}
 
 	return resourceResgroupRead(ctx, d, m)
@@ -265,7 +260,7 @@ func resourceResgroupUpdate(ctx context.Context, d *schema.ResourceData, m inter
 func resourceResgroupDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	// NOTE: this method forcibly destroys target resource group with flag "permanently", so there is no way to
 	// restore the destroyed resource group as well all Computes & VINSes that existed in it
-	log.Debugf("resourceResgroupDelete: called for RG name %s, account ID %d",
+	logger.Debugf(ctx, "resourceResgroupDelete: called for RG name %s, account ID %d",
 		d.Get("name").(string), d.Get("account_id").(int))
 
 	c := m.(*controller.ControllerCfg)
@@ -300,12 +295,16 @@ func ResourceRgSchemaMake() map[string]*schema.Schema {
 		},
 
 		"gid": {
-			Type:        schema.TypeInt,
-			Required:    true,
-			ForceNew:    true, // change of Grid ID will require new RG
-			Description: "Unique ID of the grid, where this resource group is deployed.",
+			Type:          schema.TypeInt,
+			Optional:      true,
+			Computed:      true,
+			ForceNew:      true, // change of Grid ID will require new RG
+			ConflictsWith: []string{"location"},
+			Description:   "Unique ID of the grid, where this resource group is deployed. If neither gid nor location is set, the default grid of the provider is used.",
 		},
 
+		"location": location.ResourceLocationSchemaMake(),
+
 		"name": {
 			Type:        schema.TypeString,
 			Required:    true,
@@ -541,15 +540,31 @@ func ResourceRgSchemaMake() map[string]*schema.Schema {
 	}
 }
 
+// resourceResgroupCustomizeDiff counts the external IP address taken by the ViNS of a new
+// resource group with PRIVATE default network against the account limits
+func resourceResgroupCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	usage := quota.Usage{}
+	if d.Id() == "" && d.Get("def_net_type").(string) == "PRIVATE" {
+		usage.ExtIPs = 1
+	}
+	return quota.Check(ctx, m, resgroupQuotaKey(d), d.Get("account_id").(int), 0, usage)
+}
+
+func resgroupQuotaKey(d quota.Resource) string {
+	return quota.Key("rg", d, "account_id", "name")
+}
+
 func ResourceResgroup() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
 
-		CreateContext: resourceResgroupCreate,
+		CreateContext: quota.WithWarnings(resourceResgroupCreate, resgroupQuotaKey),
 		ReadContext:   resourceResgroupRead,
-		UpdateContext: resourceResgroupUpdate,
+		UpdateContext: quota.WithWarnings(resourceResgroupUpdate, resgroupQuotaKey),
 		DeleteContext: resourceResgroupDelete,
 
+		CustomizeDiff: resourceResgroupCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
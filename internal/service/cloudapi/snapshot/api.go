@@ -35,3 +35,6 @@ const snapshotCreateAPI = "/restmachine/cloudapi/compute/snapshotCreate"
 const snapshotDeleteAPI = "/restmachine/cloudapi/compute/snapshotDelete"
 const snapshotRollbackAPI = "/restmachine/cloudapi/compute/snapshotRollback"
 const snapshotListAPI = "/restmachine/cloudapi/compute/snapshotList"
+
+const disksGetAPI = "/restmachine/cloudapi/disks/get"
+const disksSnapshotRollbackAPI = "/restmachine/cloudapi/disks/snapshotRollback"
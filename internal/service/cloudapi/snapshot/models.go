@@ -39,3 +39,20 @@ type Snapshot struct {
 }
 
 type SnapshotList []Snapshot
+
+// DiskSnapshot is a snapshot record as reported by disks/get
+type DiskSnapshot struct {
+	Guid        string `json:"guid"`
+	Label       string `json:"label"`
+	SnapSetGuid string `json:"snapSetGuid"`
+	SnapSetTime uint64 `json:"snapSetTime"`
+	TimeStamp   uint64 `json:"timestamp"`
+}
+
+// DiskSnapshots is the subset of disks/get response needed to resolve
+// disk snapshots and the compute the disk is attached to
+type DiskSnapshots struct {
+	ID        int            `json:"id"`
+	VMID      int            `json:"vmid"`
+	Snapshots []DiskSnapshot `json:"snapshots"`
+}
@@ -0,0 +1,236 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package snapshot
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/rudecs/terraform-provider-decort/internal/constants"
+)
+
+func resourceSnapshotPolicyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceSnapshotPolicyCreate: called with label template %s", d.Get("label_template").(string))
+
+	if err := utilitySnapshotPolicyApply(ctx, d, m); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(uuid.New().String())
+
+	return resourceSnapshotPolicyRead(ctx, d, m)
+}
+
+func resourceSnapshotPolicyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceSnapshotPolicyRead: called for policy %s", d.Id())
+
+	if err := utilitySnapshotPolicyRead(ctx, d, m); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceSnapshotPolicyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceSnapshotPolicyUpdate: called for policy %s", d.Id())
+
+	if err := utilitySnapshotPolicyApply(ctx, d, m); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceSnapshotPolicyRead(ctx, d, m)
+}
+
+func resourceSnapshotPolicyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceSnapshotPolicyDelete: called for policy %s", d.Id())
+
+	if !d.Get("delete_snapshots").(bool) {
+		d.SetId("")
+		return nil
+	}
+
+	computes, err := utilitySnapshotPolicyComputes(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	labelRe := policyLabelRegexp(d.Get("label_template").(string))
+	for _, computeId := range computes {
+		list, err := utilitySnapshotPolicyList(ctx, m, computeId, labelRe)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for _, s := range list {
+			if err := utilitySnapshotPolicyDelete(ctx, m, computeId, s.Label); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceSnapshotPolicyCustomizeDiff schedules a snapshot when the interval has passed
+// since the latest one, so that every terraform apply run (e.g. from cron) takes
+// snapshots when they are due and prunes the expired ones
+func resourceSnapshotPolicyCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	interval, err := time.ParseDuration(d.Get("interval").(string))
+	if err != nil {
+		return err
+	}
+
+	lastSnapshotTime := int64(d.Get("last_snapshot_time").(int))
+	if lastSnapshotTime != 0 && time.Since(time.Unix(lastSnapshotTime, 0)) < interval {
+		return nil
+	}
+
+	logger.Debugf(ctx, "resourceSnapshotPolicyCustomizeDiff: snapshot of policy %s is due", d.Id())
+	if err := d.SetNewComputed("last_snapshot_time"); err != nil {
+		return err
+	}
+	return d.SetNewComputed("snapshots")
+}
+
+func resourceSnapshotPolicySchemaMake() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"compute_ids": {
+			Type:         schema.TypeSet,
+			Optional:     true,
+			Elem:         &schema.Schema{Type: schema.TypeInt},
+			AtLeastOneOf: []string{"compute_ids", "disk_ids"},
+			Description:  "IDs of the computes to take snapshots of.",
+		},
+		"disk_ids": {
+			Type:         schema.TypeSet,
+			Optional:     true,
+			Elem:         &schema.Schema{Type: schema.TypeInt},
+			AtLeastOneOf: []string{"compute_ids", "disk_ids"},
+			Description:  "IDs of the disks to take snapshots of. The platform takes disk snapshots together with all disks of the compute the disk is attached to.",
+		},
+		"label_template": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "tf-{date}-{time}",
+			ValidateFunc: validatePolicyLabelTemplate,
+			Description:  "Template of snapshot labels. Supports {date} (YYYYMMDD), {time} (HHMMSS) and {unix} placeholders, UTC. Snapshots with labels matching the template are managed by the policy.",
+		},
+		"interval": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "24h",
+			ValidateFunc: validatePolicyInterval,
+			Description:  "Minimal interval between snapshots, e.g. 12h. A snapshot is taken on the apply run after the interval has passed.",
+		},
+		"retention_count": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      7,
+			ValidateFunc: validation.IntAtLeast(0),
+			Description:  "Number of the latest snapshots to keep for every compute. 0 means unlimited.",
+		},
+		"retention_days": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			ValidateFunc: validation.IntAtLeast(0),
+			Description:  "Snapshots older than this number of days are deleted. 0 means unlimited.",
+		},
+		"delete_snapshots": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Delete snapshots created by the policy when the policy is destroyed.",
+		},
+		"last_snapshot_time": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "Time of the latest snapshot. If computes have different snapshots, the oldest of them; 0 if any compute has no snapshot yet.",
+		},
+		"snapshots": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "Snapshots created by the policy.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"compute_id": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"label": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"guid": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"timestamp": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func ResourceSnapshotPolicy() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+
+		CreateContext: resourceSnapshotPolicyCreate,
+		ReadContext:   resourceSnapshotPolicyRead,
+		UpdateContext: resourceSnapshotPolicyUpdate,
+		DeleteContext: resourceSnapshotPolicyDelete,
+
+		CustomizeDiff: resourceSnapshotPolicyCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create:  &constants.Timeout600s,
+			Read:    &constants.Timeout300s,
+			Update:  &constants.Timeout600s,
+			Delete:  &constants.Timeout300s,
+			Default: &constants.Timeout300s,
+		},
+
+		Schema: resourceSnapshotPolicySchemaMake(),
+	}
+}
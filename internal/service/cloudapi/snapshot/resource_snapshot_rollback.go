@@ -0,0 +1,189 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/constants"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+// decort_snapshot_rollback is an action resource: creating it rolls the compute or the disk
+// back to the snapshot and records which snapshot was restored and when. To roll back again
+// the resource has to be replaced, e.g. by changing triggers.
+
+func resourceSnapshotRollbackCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	label := d.Get("label").(string)
+	logger.Debugf(ctx, "resourceSnapshotRollbackCreate: called for snapshot %s", label)
+
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("label", label)
+
+	var guid string
+	var timestamp uint64
+	var rollbackAPI string
+
+	if computeId, ok := d.GetOk("compute_id"); ok {
+		list, err := utilitySnapshotListCheckPresence(ctx, d, m)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for _, s := range list {
+			if s.Label == label {
+				guid, timestamp = s.Guid, s.Timestamp
+				break
+			}
+		}
+		if guid == "" {
+			return diag.Errorf("snapshot %s of compute %d not found", label, computeId.(int))
+		}
+
+		urlValues.Add("computeId", strconv.Itoa(computeId.(int)))
+		rollbackAPI = snapshotRollbackAPI
+	} else {
+		diskId := d.Get("disk_id").(int)
+		disk, err := utilitySnapshotDiskGet(ctx, m, diskId)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for _, s := range disk.Snapshots {
+			if s.Label == label {
+				guid, timestamp = s.Guid, s.TimeStamp
+				break
+			}
+		}
+		if guid == "" {
+			return diag.Errorf("snapshot %s of disk %d not found", label, diskId)
+		}
+
+		urlValues.Add("diskId", strconv.Itoa(diskId))
+		urlValues.Add("timestamp", strconv.FormatUint(timestamp, 10))
+		rollbackAPI = disksSnapshotRollbackAPI
+	}
+
+	if _, err := c.DecortAPICall(ctx, "POST", rollbackAPI, urlValues); err != nil {
+		return diag.FromErr(fmt.Errorf("cannot roll back to snapshot %s: %w", label, err))
+	}
+
+	d.SetId(uuid.New().String())
+	d.Set("snapshot_guid", guid)
+	d.Set("snapshot_timestamp", timestamp)
+	d.Set("rolled_back_at", time.Now().UTC().Format(time.RFC3339))
+
+	return nil
+}
+
+func resourceSnapshotRollbackRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// the rollback is a completed action, there is nothing to refresh
+	return nil
+}
+
+func resourceSnapshotRollbackDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceSnapshotRollbackDelete: removing rollback record %s from state", d.Id())
+	d.SetId("")
+	return nil
+}
+
+func resourceSnapshotRollbackSchemaMake() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"compute_id": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ForceNew:     true,
+			ExactlyOneOf: []string{"compute_id", "disk_id"},
+			Description:  "ID of the compute to roll back.",
+		},
+		"disk_id": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ForceNew:     true,
+			ExactlyOneOf: []string{"compute_id", "disk_id"},
+			Description:  "ID of the disk to roll back.",
+		},
+		"label": {
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+			Description: "Label of the snapshot to roll back to.",
+		},
+		"triggers": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			ForceNew:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Arbitrary values, changing any of them performs the rollback again.",
+		},
+		"snapshot_guid": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "GUID of the restored snapshot.",
+		},
+		"snapshot_timestamp": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "Timestamp of the restored snapshot.",
+		},
+		"rolled_back_at": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Time of the rollback, RFC3339.",
+		},
+	}
+}
+
+func ResourceSnapshotRollback() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+
+		CreateContext: resourceSnapshotRollbackCreate,
+		ReadContext:   resourceSnapshotRollbackRead,
+		DeleteContext: resourceSnapshotRollbackDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create:  &constants.Timeout600s,
+			Read:    &constants.Timeout30s,
+			Delete:  &constants.Timeout30s,
+			Default: &constants.Timeout60s,
+		},
+
+		Schema: resourceSnapshotRollbackSchemaMake(),
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+// placeholders supported in label_template of decort_snapshot_policy
+var policyLabelPlaceholders = map[string]string{
+	"{date}": `\d{8}`,
+	"{time}": `\d{6}`,
+	"{unix}": `\d+`,
+}
+
+// policyLabel renders label template for the snapshot taken at the given time
+func policyLabel(template string, now time.Time) string {
+	now = now.UTC()
+	return strings.NewReplacer(
+		"{date}", now.Format("20060102"),
+		"{time}", now.Format("150405"),
+		"{unix}", strconv.FormatInt(now.Unix(), 10),
+	).Replace(template)
+}
+
+// policyLabelRegexp matches labels rendered from the template, which is how snapshots
+// created by the policy are told apart from the other snapshots of the compute
+func policyLabelRegexp(template string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(template)
+	for placeholder, re := range policyLabelPlaceholders {
+		expr = strings.ReplaceAll(expr, regexp.QuoteMeta(placeholder), re)
+	}
+	return regexp.MustCompile("^" + expr + "$")
+}
+
+func validatePolicyLabelTemplate(v interface{}, k string) ([]string, []error) {
+	template := v.(string)
+	for placeholder := range policyLabelPlaceholders {
+		if strings.Contains(template, placeholder) {
+			return nil, nil
+		}
+	}
+	return nil, []error{fmt.Errorf("%s must contain at least one of {date}, {time} or {unix} placeholders to keep labels unique", k)}
+}
+
+func validatePolicyInterval(v interface{}, k string) ([]string, []error) {
+	interval, err := time.ParseDuration(v.(string))
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s must be a duration like \"24h\": %w", k, err)}
+	}
+	if interval <= 0 {
+		return nil, []error{fmt.Errorf("%s must be positive", k)}
+	}
+	return nil, nil
+}
+
+func utilitySnapshotDiskGet(ctx context.Context, m interface{}, diskId int) (*DiskSnapshots, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("diskId", strconv.Itoa(diskId))
+
+	diskRaw, err := c.DecortAPICall(ctx, "POST", disksGetAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	disk := &DiskSnapshots{}
+	if err := json.Unmarshal([]byte(diskRaw), disk); err != nil {
+		return nil, err
+	}
+	return disk, nil
+}
+
+// utilitySnapshotPolicyComputes resolves the computes the policy takes snapshots of.
+// Disk snapshots are always taken as a part of the snapshot of the compute the disk
+// is attached to, so disk_ids are resolved to their computes.
+func utilitySnapshotPolicyComputes(ctx context.Context, d *schema.ResourceData, m interface{}) ([]int, error) {
+	computes := map[int]bool{}
+	for _, id := range d.Get("compute_ids").(*schema.Set).List() {
+		computes[id.(int)] = true
+	}
+
+	for _, id := range d.Get("disk_ids").(*schema.Set).List() {
+		disk, err := utilitySnapshotDiskGet(ctx, m, id.(int))
+		if err != nil {
+			return nil, err
+		}
+		if disk.VMID == 0 {
+			return nil, fmt.Errorf("disk %d is not attached to a compute, snapshots cannot be taken", id.(int))
+		}
+		computes[disk.VMID] = true
+	}
+
+	res := make([]int, 0, len(computes))
+	for id := range computes {
+		res = append(res, id)
+	}
+	sort.Ints(res)
+	return res, nil
+}
+
+// utilitySnapshotPolicyList returns snapshots of the compute created by the policy, newest first
+func utilitySnapshotPolicyList(ctx context.Context, m interface{}, computeId int, labelRe *regexp.Regexp) (SnapshotList, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("computeId", strconv.Itoa(computeId))
+
+	resp, err := c.DecortAPICall(ctx, "POST", snapshotListAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotList := SnapshotList{}
+	if resp != "" {
+		if err := json.Unmarshal([]byte(resp), &snapshotList); err != nil {
+			return nil, err
+		}
+	}
+
+	res := SnapshotList{}
+	for _, s := range snapshotList {
+		if labelRe.MatchString(s.Label) {
+			res = append(res, s)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Timestamp > res[j].Timestamp })
+	return res, nil
+}
+
+func utilitySnapshotPolicyDelete(ctx context.Context, m interface{}, computeId int, label string) error {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("computeId", strconv.Itoa(computeId))
+	urlValues.Add("label", label)
+
+	if _, err := c.DecortAPICall(ctx, "POST", snapshotDeleteAPI, urlValues); err != nil {
+		return fmt.Errorf("cannot delete snapshot %s of compute %d: %w", label, computeId, err)
+	}
+	return nil
+}
+
+// utilitySnapshotPolicyRead reports snapshots created by the policy and the time of the
+// latest snapshot. If any of the computes has no snapshot yet, last_snapshot_time is
+// reported as 0, so that the next plan schedules a snapshot.
+func utilitySnapshotPolicyRead(ctx context.Context, d *schema.ResourceData, m interface{}) error {
+	computes, err := utilitySnapshotPolicyComputes(ctx, d, m)
+	if err != nil {
+		return err
+	}
+	labelRe := policyLabelRegexp(d.Get("label_template").(string))
+
+	snapshots := make([]map[string]interface{}, 0)
+	var lastSnapshotTime uint64
+	missing := false
+	for _, computeId := range computes {
+		list, err := utilitySnapshotPolicyList(ctx, m, computeId, labelRe)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range list {
+			snapshots = append(snapshots, map[string]interface{}{
+				"compute_id": computeId,
+				"label":      s.Label,
+				"guid":       s.Guid,
+				"timestamp":  s.Timestamp,
+			})
+		}
+
+		// the oldest of the latest snapshots of every compute
+		if len(list) == 0 {
+			missing = true
+		} else if lastSnapshotTime == 0 || list[0].Timestamp < lastSnapshotTime {
+			lastSnapshotTime = list[0].Timestamp
+		}
+	}
+	if missing {
+		lastSnapshotTime = 0
+	}
+
+	d.Set("snapshots", snapshots)
+	d.Set("last_snapshot_time", lastSnapshotTime)
+	return nil
+}
+
+// utilitySnapshotPolicyApply takes snapshots of the computes which are due according to
+// the interval and prunes snapshots beyond retention_count and retention_days
+func utilitySnapshotPolicyApply(ctx context.Context, d *schema.ResourceData, m interface{}) error {
+	c := m.(*controller.ControllerCfg)
+
+	computes, err := utilitySnapshotPolicyComputes(ctx, d, m)
+	if err != nil {
+		return err
+	}
+
+	template := d.Get("label_template").(string)
+	labelRe := policyLabelRegexp(template)
+	interval, _ := time.ParseDuration(d.Get("interval").(string))
+	retentionCount := d.Get("retention_count").(int)
+	retentionAge := time.Duration(d.Get("retention_days").(int)) * 24 * time.Hour
+	now := time.Now()
+
+	for _, computeId := range computes {
+		list, err := utilitySnapshotPolicyList(ctx, m, computeId, labelRe)
+		if err != nil {
+			return err
+		}
+
+		if len(list) == 0 || now.Sub(time.Unix(int64(list[0].Timestamp), 0)) >= interval {
+			label := policyLabel(template, now)
+			logger.Debugf(ctx, "utilitySnapshotPolicyApply: creating snapshot %s of compute %d", label, computeId)
+
+			urlValues := &url.Values{}
+			urlValues.Add("computeId", strconv.Itoa(computeId))
+			urlValues.Add("label", label)
+			if _, err := c.DecortAPICall(ctx, "POST", snapshotCreateAPI, urlValues); err != nil {
+				return fmt.Errorf("cannot create snapshot %s of compute %d: %w", label, computeId, err)
+			}
+
+			list, err = utilitySnapshotPolicyList(ctx, m, computeId, labelRe)
+			if err != nil {
+				return err
+			}
+		}
+
+		for i, s := range list {
+			expired := retentionAge > 0 && now.Sub(time.Unix(int64(s.Timestamp), 0)) > retentionAge
+			if (retentionCount > 0 && i >= retentionCount) || expired {
+				logger.Debugf(ctx, "utilitySnapshotPolicyApply: pruning snapshot %s of compute %d", s.Label, computeId)
+				if err := utilitySnapshotPolicyDelete(ctx, m, computeId, s.Label); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
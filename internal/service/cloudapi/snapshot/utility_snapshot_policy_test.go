@@ -0,0 +1,127 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package snapshot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyLabel(t *testing.T) {
+	now := time.Date(2023, 3, 14, 15, 9, 26, 0, time.FixedZone("MSK", 3*60*60))
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{template: "auto-{date}-{time}", want: "auto-20230314-120926"},
+		{template: "auto-{unix}", want: "auto-1678795766"},
+		{template: "daily.{date}", want: "daily.20230314"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.template, func(t *testing.T) {
+			if got := policyLabel(tc.template, now); got != tc.want {
+				t.Errorf("policyLabel() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPolicyLabelRegexp(t *testing.T) {
+	tests := []struct {
+		template string
+		label    string
+		want     bool
+	}{
+		{template: "auto-{date}-{time}", label: "auto-20230314-120926", want: true},
+		{template: "auto-{date}-{time}", label: "auto-20230314", want: false},
+		{template: "auto-{date}-{time}", label: "manual-20230314-120926", want: false},
+		{template: "auto-{date}-{time}", label: "auto-20230314-120926-copy", want: false},
+		{template: "auto-{unix}", label: "auto-1678795766", want: true},
+		{template: "auto-{unix}", label: "auto-", want: false},
+		// regexp metacharacters of the template are matched literally
+		{template: "daily.{date}", label: "daily.20230314", want: true},
+		{template: "daily.{date}", label: "dailyX20230314", want: false},
+		{template: "(a+){date}", label: "(a+)20230314", want: true},
+		{template: "(a+){date}", label: "aa20230314", want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.template+" "+tc.label, func(t *testing.T) {
+			if got := policyLabelRegexp(tc.template).MatchString(tc.label); got != tc.want {
+				t.Errorf("policyLabelRegexp(%q).MatchString(%q) = %v, want %v", tc.template, tc.label, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidatePolicyLabelTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		wantErr  bool
+	}{
+		{template: "auto-{date}"},
+		{template: "auto-{time}"},
+		{template: "auto-{unix}"},
+		{template: "auto", wantErr: true},
+		{template: "auto-{day}", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		_, errs := validatePolicyLabelTemplate(tc.template, "label_template")
+		if (len(errs) != 0) != tc.wantErr {
+			t.Errorf("validatePolicyLabelTemplate(%q) = %v, wantErr %v", tc.template, errs, tc.wantErr)
+		}
+	}
+}
+
+func TestValidatePolicyInterval(t *testing.T) {
+	tests := []struct {
+		interval string
+		wantErr  bool
+	}{
+		{interval: "24h"},
+		{interval: "90m"},
+		{interval: "0s", wantErr: true},
+		{interval: "-1h", wantErr: true},
+		{interval: "1d", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		_, errs := validatePolicyInterval(tc.interval, "interval")
+		if (len(errs) != 0) != tc.wantErr {
+			t.Errorf("validatePolicyInterval(%q) = %v, wantErr %v", tc.interval, errs, tc.wantErr)
+		}
+	}
+}
@@ -340,22 +340,6 @@ func flattenVNFS(vnfs VNFS) []map[string]interface{} {
 	return res
 }
 
-func flattenRuleBlock(rules ListNATRules) []map[string]interface{} {
-	res := make([]map[string]interface{}, 0)
-	for _, rule := range rules {
-		tmp := map[string]interface{}{
-			"int_ip":         rule.LocalIP,
-			"int_port":       rule.LocalPort,
-			"ext_port_start": rule.PublicPortStart,
-			"ext_port_end":   rule.PublicPortEnd,
-			"proto":          rule.Protocol,
-			"rule_id":        rule.ID,
-		}
-		res = append(res, tmp)
-	}
-	return res
-}
-
 func flattenVins(d *schema.ResourceData, vins VINSDetailed) {
 	d.Set("vins_id", vins.ID)
 	d.Set("vnf_dev", flattenVNFDev(vins.VNFDev))
@@ -384,7 +368,6 @@ func flattenVins(d *schema.ResourceData, vins VINSDetailed) {
 	d.Set("user_managed", vins.UserManaged)
 	d.Set("vnfs", flattenVNFS(vins.VNFS))
 	d.Set("vxlan_id", vins.VXLanID)
-	d.Set("nat_rule", flattenRuleBlock(vins.VNFS.NAT.Config.Rules))
 }
 
 func flattenVinsData(d *schema.ResourceData, vins VINSDetailed) {
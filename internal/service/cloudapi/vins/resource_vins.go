@@ -42,7 +42,6 @@ import (
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
 	"github.com/rudecs/terraform-provider-decort/internal/dc"
 	"github.com/rudecs/terraform-provider-decort/internal/status"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -95,6 +94,8 @@ func resourceVinsCreate(ctx context.Context, d *schema.ResourceData, m interface
 		urlValues.Add("accountId", strconv.Itoa(accountId.(int)))
 		if gid, ok := d.GetOk("gid"); ok {
 			urlValues.Add("gid", strconv.Itoa(gid.(int)))
+		} else {
+			urlValues.Add("gid", strconv.Itoa(c.GetDefaultGridID()))
 		}
 		if ipcidr, ok := d.GetOk("ipcidr"); ok {
 			urlValues.Add("ipcidr", ipcidr.(string))
@@ -135,26 +136,9 @@ func resourceVinsCreate(ctx context.Context, d *schema.ResourceData, m interface
 		}
 	}
 
-	urlValues = &url.Values{}
-	if natRule, ok := d.GetOk("nat_rule"); ok {
-		addedNatRules := natRule.([]interface{})
-		if len(addedNatRules) > 0 {
-			for _, natRuleInterface := range addedNatRules {
-				urlValues = &url.Values{}
-				natRule := natRuleInterface.(map[string]interface{})
-
-				urlValues.Add("vinsId", d.Id())
-				urlValues.Add("intIp", natRule["int_ip"].(string))
-				urlValues.Add("intPort", strconv.Itoa(natRule["int_port"].(int)))
-				urlValues.Add("extPortStart", strconv.Itoa(natRule["ext_port_start"].(int)))
-				urlValues.Add("extPortEnd", strconv.Itoa(natRule["ext_port_end"].(int)))
-				urlValues.Add("proto", natRule["proto"].(string))
-
-				_, err := c.DecortAPICall(ctx, "POST", VinsNatRuleAddAPI, urlValues)
-				if err != nil {
-					warnings.Add(err)
-				}
-			}
+	if natRules, ok := d.GetOk("nat_rule"); ok && natRules.(*schema.Set).Len() > 0 || d.Get("nat_rule_exclusive").(bool) {
+		if err := utilityVinsNatRulesConfigure(ctx, d, m); err != nil {
+			return append(warnings.Get(), diag.FromErr(err)...)
 		}
 	}
 
@@ -216,6 +200,9 @@ func resourceVinsRead(ctx context.Context, d *schema.ResourceData, m interface{}
 	}
 
 	flattenVins(d, *vins)
+	if err := utilityVinsNatRulesRead(ctx, d, m); err != nil {
+		return append(warnings.Get(), diag.FromErr(err)...)
+	}
 	return warnings.Get()
 }
 
@@ -230,19 +217,6 @@ func isContainsIp(els []interface{}, el interface{}) bool {
 	return false
 }
 
-func isContinsNatRule(els []interface{}, el interface{}) bool {
-	for _, elOld := range els {
-		elOldConv := elOld.(map[string]interface{})
-		elConv := el.(map[string]interface{})
-		if elOldConv["int_ip"].(string) == elConv["int_ip"].(string) &&
-			elOldConv["int_port"].(int) == elConv["int_port"].(int) &&
-			elOldConv["ext_port_start"].(int) == elConv["ext_port_start"].(int) {
-			return true
-		}
-	}
-	return false
-}
-
 func resourceVinsUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -266,7 +240,7 @@ func resourceVinsUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	//extnet v1
 	oldExtNetId, newExtNedId := d.GetChange("ext_net_id")
 	if oldExtNetId.(int) != newExtNedId.(int) {
-		log.Debugf("resourceVinsUpdate: changing ViNS ID %s - ext_net_id %d -> %d", d.Id(), oldExtNetId.(int), newExtNedId.(int))
+		logger.Debugf(ctx, "resourceVinsUpdate: changing ViNS ID %s - ext_net_id %d -> %d", d.Id(), oldExtNetId.(int), newExtNedId.(int))
 
 		extnetParams := &url.Values{}
 		extnetParams.Add("vinsId", d.Id())
@@ -357,63 +331,9 @@ func resourceVinsUpdate(ctx context.Context, d *schema.ResourceData, m interface
 		}
 	}
 
-	if d.HasChange("nat_rule") {
-		deletedNatRules := make([]interface{}, 0)
-		addedNatRules := make([]interface{}, 0)
-
-		oldNatRulesInterface, newNatRulesInterface := d.GetChange("nat_rule")
-		oldNatRulesSlice := oldNatRulesInterface.([]interface{})
-		newNatRulesSlice := newNatRulesInterface.([]interface{})
-
-		for _, el := range oldNatRulesSlice {
-			if !isContinsNatRule(newNatRulesSlice, el) {
-				deletedNatRules = append(deletedNatRules, el)
-			}
-		}
-
-		for _, el := range newNatRulesSlice {
-			if !isContinsNatRule(oldNatRulesSlice, el) {
-				addedNatRules = append(addedNatRules, el)
-			}
-		}
-
-		if len(deletedNatRules) > 0 {
-			for _, natRuleInterface := range deletedNatRules {
-				urlValues = &url.Values{}
-				natRule := natRuleInterface.(map[string]interface{})
-
-				urlValues.Add("vinsId", d.Id())
-				urlValues.Add("ruleId", strconv.Itoa(natRule["rule_id"].(int)))
-				log.Debug("NAT_RULE_DEL_WITH: ", urlValues.Encode())
-				_, err := c.DecortAPICall(ctx, "POST", VinsNatRuleDelAPI, urlValues)
-				if err != nil {
-					warnings.Add(err)
-				}
-			}
-		}
-
-		if len(addedNatRules) > 0 {
-			for _, natRuleInterface := range addedNatRules {
-				urlValues = &url.Values{}
-				natRule := natRuleInterface.(map[string]interface{})
-
-				urlValues.Add("vinsId", d.Id())
-				urlValues.Add("intIp", natRule["int_ip"].(string))
-				urlValues.Add("intPort", strconv.Itoa(natRule["int_port"].(int)))
-				urlValues.Add("extPortStart", strconv.Itoa(natRule["ext_port_start"].(int)))
-				if natRule["ext_port_end"].(int) != 0 {
-					urlValues.Add("extPortEnd", strconv.Itoa(natRule["ext_port_end"].(int)))
-				}
-				if natRule["proto"].(string) != "" {
-					urlValues.Add("proto", natRule["proto"].(string))
-				}
-
-				log.Debug("NAT_RULE_ADD_WITH: ", urlValues.Encode())
-				_, err := c.DecortAPICall(ctx, "POST", VinsNatRuleAddAPI, urlValues)
-				if err != nil {
-					warnings.Add(err)
-				}
-			}
+	if d.HasChange("nat_rule") || d.HasChange("nat_rule_exclusive") {
+		if err := utilityVinsNatRulesConfigure(ctx, d, m); err != nil {
+			return append(warnings.Get(), diag.FromErr(err)...)
 		}
 	}
 
@@ -439,6 +359,22 @@ func resourceVinsUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	return warnings.Get()
 }
 
+func resourceVinsCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c := m.(*controller.ControllerCfg)
+
+	// naming policy applies to new and renamed objects only
+	if (d.Id() == "" || d.HasChange("name")) && d.NewValueKnown("name") {
+		if err := c.CheckName(d.Get("name").(string)); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("nat_rule") || d.HasChange("nat_rule_exclusive") {
+		return utilityVinsNatRulesCheckConflicts(ctx, d, m)
+	}
+	return nil
+}
+
 func resourceVinsDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	urlValues := &url.Values{}
 	c := m.(*controller.ControllerCfg)
@@ -493,30 +429,35 @@ func ipSchemaMake() map[string]*schema.Schema {
 func natRuleSchemaMake() map[string]*schema.Schema {
 	return map[string]*schema.Schema{
 		"int_ip": {
-			Type:     schema.TypeString,
-			Optional: true,
-			Computed: true,
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Internal IP address to forward traffic to.",
 		},
 		"int_port": {
-			Type:     schema.TypeInt,
-			Optional: true,
-			Computed: true,
+			Type:         schema.TypeInt,
+			Required:     true,
+			ValidateFunc: validation.IntBetween(1, 65535),
+			Description:  "Internal base port number.",
 		},
 		"ext_port_start": {
-			Type:     schema.TypeInt,
-			Optional: true,
-			Computed: true,
+			Type:         schema.TypeInt,
+			Required:     true,
+			ValidateFunc: validation.IntBetween(1, 65535),
+			Description:  "External start port number for the rule.",
 		},
 		"ext_port_end": {
-			Type:     schema.TypeInt,
-			Optional: true,
-			Computed: true,
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			ValidateFunc: validation.IntBetween(1, 65535),
+			Description:  "External end port number (inclusive) for the ranged rule. Equals to ext_port_start if not set.",
 		},
 		"proto": {
 			Type:         schema.TypeString,
 			Optional:     true,
+			Default:      "tcp",
 			ValidateFunc: validation.StringInSlice([]string{"tcp", "udp"}, false),
-			Computed:     true,
+			Description:  "Network protocol, either 'tcp' or 'udp'.",
 		},
 		"rule_id": {
 			Type:     schema.TypeInt,
@@ -593,13 +534,22 @@ func resourceVinsSchemaMake() map[string]*schema.Schema {
 		Elem: &schema.Resource{
 			Schema: ipSchemaMake(),
 		},
+		Deprecated: "Use decort_vins_ip_reservation resource instead",
 	}
 	rets["nat_rule"] = &schema.Schema{
-		Type:     schema.TypeList,
+		Type:     schema.TypeSet,
 		Optional: true,
+		Set:      hashNatRule,
 		Elem: &schema.Resource{
 			Schema: natRuleSchemaMake(),
 		},
+		Description: "NAT rules of the ViNS. Rules are compared by internal address, ports and protocol.",
+	}
+	rets["nat_rule_exclusive"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "If true, NAT rules of the ViNS not declared in nat_rule (including port forwardings of computes) are removed.",
 	}
 	rets["desc"] = &schema.Schema{
 		Type:        schema.TypeString,
@@ -641,6 +591,8 @@ func ResourceVins() *schema.Resource {
 		UpdateContext: resourceVinsUpdate,
 		DeleteContext: resourceVinsDelete,
 
+		CustomizeDiff: resourceVinsCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
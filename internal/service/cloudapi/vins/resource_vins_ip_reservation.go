@@ -0,0 +1,271 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package vins
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/rudecs/terraform-provider-decort/internal/constants"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+func resourceVinsIpReservationCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceVinsIpReservationCreate: called for vins %d", d.Get("vins_id").(int))
+
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("vinsId", strconv.Itoa(d.Get("vins_id").(int)))
+	urlValues.Add("type", d.Get("type").(string))
+	if ipAddr, ok := d.GetOk("ip_addr"); ok {
+		urlValues.Add("ipAddr", ipAddr.(string))
+	}
+	if macAddr, ok := d.GetOk("mac_addr"); ok {
+		urlValues.Add("mac", macAddr.(string))
+	}
+	if computeId, ok := d.GetOk("compute_id"); ok {
+		urlValues.Add("computeId", strconv.Itoa(computeId.(int)))
+	}
+
+	res, err := c.DecortAPICall(ctx, "POST", VinsIpReserveAPI, urlValues)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// the platform returns reserved address, which is the only way
+	// to find out the address if it was not specified
+	ipAddr := d.Get("ip_addr").(string)
+	if ipAddr == "" {
+		ipAddr = strings.Trim(res, "\"")
+	}
+
+	d.SetId(fmt.Sprintf("%d#%s", d.Get("vins_id").(int), ipAddr))
+	d.Set("ip_addr", ipAddr)
+
+	return resourceVinsIpReservationRead(ctx, d, m)
+}
+
+func resourceVinsIpReservationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceVinsIpReservationRead: called for reservation %s", d.Id())
+
+	ip, err := utilityVinsIpReservationCheckPresence(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if ip == nil {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("type", ip.Type)
+	d.Set("ip_addr", ip.IP)
+	d.Set("mac_addr", ip.MAC)
+	d.Set("compute_id", ip.VMID)
+	d.Set("client_type", ip.ClientType)
+	d.Set("domainname", ip.DomainName)
+	d.Set("hostname", ip.HostName)
+
+	return nil
+}
+
+func resourceVinsIpReservationDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	logger.Debugf(ctx, "resourceVinsIpReservationDelete: called for reservation %s", d.Id())
+
+	ip, err := utilityVinsIpReservationCheckPresence(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if ip == nil {
+		return nil
+	}
+
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	urlValues.Add("vinsId", strconv.Itoa(d.Get("vins_id").(int)))
+	urlValues.Add("ipAddr", ip.IP)
+	if ip.MAC != "" {
+		urlValues.Add("mac", ip.MAC)
+	}
+
+	_, err = c.DecortAPICall(ctx, "POST", VinsIpReleaseAPI, urlValues)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceVinsIpReservationImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourceVinsIpReservationImport: called with id %s", d.Id())
+
+	parameters := strings.Split(d.Id(), "#")
+	if len(parameters) != 2 || parameters[1] == "" {
+		return nil, fmt.Errorf("invalid import id %q: expected <vins_id>#<ip_addr>", d.Id())
+	}
+
+	vinsId, err := strconv.Atoi(parameters[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid import id %q: vins id must be a number", d.Id())
+	}
+
+	d.Set("vins_id", vinsId)
+	d.Set("ip_addr", parameters[1])
+
+	ip, err := utilityVinsIpReservationCheckPresence(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+	if ip == nil {
+		return nil, fmt.Errorf("ip reservation %s not found", d.Id())
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVinsIpReservationCustomizeDiff detects at plan time that the requested address or
+// MAC is already reserved in the ViNS, i.e. it is listed by decort_vins_ip_list
+func resourceVinsIpReservationCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" {
+		return nil
+	}
+
+	vinsId := d.Get("vins_id").(int)
+	ipAddr := d.Get("ip_addr").(string)
+	macAddr := d.Get("mac_addr").(string)
+	// values may be unknown at plan time if they come from other resources
+	if vinsId == 0 || (ipAddr == "" && macAddr == "") {
+		return nil
+	}
+
+	ips, err := utilityVinsIpReservationList(ctx, m, vinsId)
+	if err != nil {
+		return err
+	}
+
+	for _, ip := range ips {
+		if ipAddr != "" && ip.IP == ipAddr {
+			return fmt.Errorf("ip %s is already reserved in vins %d (type %s, mac %s, compute %d); import it with id %d#%s",
+				ip.IP, vinsId, ip.Type, ip.MAC, ip.VMID, vinsId, ip.IP)
+		}
+		if macAddr != "" && strings.EqualFold(ip.MAC, macAddr) {
+			return fmt.Errorf("mac %s is already bound to ip %s in vins %d", macAddr, ip.IP, vinsId)
+		}
+	}
+
+	return nil
+}
+
+func resourceVinsIpReservationSchemaMake() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"vins_id": {
+			Type:        schema.TypeInt,
+			Required:    true,
+			ForceNew:    true,
+			Description: "ID of the ViNS to reserve IP address in.",
+		},
+		"type": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.StringInSlice([]string{"DHCP", "VIP", "EXCLUDE"}, false),
+			Description:  "Type of the reservation: DHCP, VIP or EXCLUDE.",
+		},
+		"ip_addr": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.IsIPv4Address,
+			Description:  "IP address to reserve. Allocated by the platform if not set.",
+		},
+		"mac_addr": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+			ForceNew: true,
+			// the platform may report the address in a different case
+			DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool { return strings.EqualFold(old, new) },
+			Description:      "MAC address to bind the reserved IP address to (DHCP reservations).",
+		},
+		"compute_id": {
+			Type:        schema.TypeInt,
+			Optional:    true,
+			Computed:    true,
+			ForceNew:    true,
+			Description: "ID of the compute to bind the reserved IP address to.",
+		},
+		"client_type": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"domainname": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"hostname": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+}
+
+func ResourceVinsIpReservation() *schema.Resource {
+	return &schema.Resource{
+		SchemaVersion: 1,
+
+		CreateContext: resourceVinsIpReservationCreate,
+		ReadContext:   resourceVinsIpReservationRead,
+		DeleteContext: resourceVinsIpReservationDelete,
+
+		CustomizeDiff: resourceVinsIpReservationCustomizeDiff,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVinsIpReservationImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create:  &constants.Timeout60s,
+			Read:    &constants.Timeout30s,
+			Delete:  &constants.Timeout60s,
+			Default: &constants.Timeout60s,
+		},
+
+		Schema: resourceVinsIpReservationSchemaMake(),
+	}
+}
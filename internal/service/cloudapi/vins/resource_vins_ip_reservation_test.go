@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package vins
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestVinsIpReservationMacAddrDiff(t *testing.T) {
+	// a replacement is checked against the reservations of the ViNS, only the schema is tested here
+	res := ResourceVinsIpReservation()
+	res.CustomizeDiff = nil
+
+	tests := []struct {
+		name        string
+		configMac   string
+		wantReplace bool
+	}{
+		{name: "same mac", configMac: "52:54:00:ab:cd:ef"},
+		{name: "mac in upper case", configMac: "52:54:00:AB:CD:EF"},
+		{name: "other mac", configMac: "52:54:00:ab:cd:00", wantReplace: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			state := &terraform.InstanceState{
+				ID: "10#192.168.0.5",
+				Attributes: map[string]string{
+					"id":       "10#192.168.0.5",
+					"vins_id":  "10",
+					"type":     "DHCP",
+					"ip_addr":  "192.168.0.5",
+					"mac_addr": "52:54:00:ab:cd:ef",
+				},
+			}
+			config := terraform.NewResourceConfigRaw(map[string]interface{}{
+				"vins_id":  10,
+				"type":     "DHCP",
+				"ip_addr":  "192.168.0.5",
+				"mac_addr": tc.configMac,
+			})
+
+			diff, err := res.Diff(context.Background(), state, config, nil)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if got := diff != nil && diff.RequiresNew(); got != tc.wantReplace {
+				t.Errorf("Diff() requires new = %v, want %v: %+v", got, tc.wantReplace, diff)
+			}
+		})
+	}
+}
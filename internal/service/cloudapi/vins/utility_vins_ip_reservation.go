@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package vins
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+func utilityVinsIpReservationList(ctx context.Context, m interface{}, vinsId int) (IPList, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	ips := IPList{}
+
+	urlValues.Add("vinsId", strconv.Itoa(vinsId))
+	ipsRaw, err := c.DecortAPICall(ctx, "POST", VinsIpListAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(ipsRaw), &ips)
+	if err != nil {
+		return nil, err
+	}
+	return ips, nil
+}
+
+func utilityVinsIpReservationCheckPresence(ctx context.Context, d *schema.ResourceData, m interface{}) (*IP, error) {
+	ips, err := utilityVinsIpReservationList(ctx, m, d.Get("vins_id").(int))
+	if err != nil {
+		return nil, err
+	}
+
+	ipAddr := d.Get("ip_addr").(string)
+	for _, ip := range ips {
+		if ip.IP == ipAddr {
+			return &ip, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -39,7 +39,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -59,7 +58,7 @@ func utilityVinsListCheckPresence(ctx context.Context, d *schema.ResourceData, m
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityVinsListCheckPresence")
+	logger.Debugf(ctx, "utilityVinsListCheckPresence")
 	vinsListRaw, err := c.DecortAPICall(ctx, "POST", VinsListAPI, urlValues)
 	if err != nil {
 		return nil, err
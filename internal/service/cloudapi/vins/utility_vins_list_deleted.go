@@ -40,7 +40,6 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func utilityVinsListDeletedCheckPresence(ctx context.Context, d *schema.ResourceData, m interface{}) (VINSList, error) {
@@ -55,7 +54,7 @@ func utilityVinsListDeletedCheckPresence(ctx context.Context, d *schema.Resource
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityVinsListDeletedCheckPresence")
+	logger.Debugf(ctx, "utilityVinsListDeletedCheckPresence")
 	vinsListRaw, err := c.DecortAPICall(ctx, "POST", VinsListDeletedAPI, urlValues)
 	if err != nil {
 		return nil, err
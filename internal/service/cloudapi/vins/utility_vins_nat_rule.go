@@ -0,0 +1,259 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package vins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rudecs/terraform-provider-decort/internal/controller"
+)
+
+// PortRangeEnd returns the last port of the range. The platform treats
+// zero end port as a single port rule.
+func PortRangeEnd(start, end int) int {
+	if end == 0 {
+		return start
+	}
+	return end
+}
+
+// PortRangesOverlap reports whether two inclusive port ranges of the same
+// protocol intersect.
+func PortRangesOverlap(proto1 string, start1, end1 int, proto2 string, start2, end2 int) bool {
+	if proto1 != proto2 {
+		return false
+	}
+	return start1 <= PortRangeEnd(start2, end2) && start2 <= PortRangeEnd(start1, end1)
+}
+
+// hashNatRule identifies nat_rule block by user-provided attributes only,
+// so that computed rule_id does not affect set membership
+func hashNatRule(v interface{}) int {
+	rule := v.(map[string]interface{})
+	start := rule["ext_port_start"].(int)
+	return schema.HashString(fmt.Sprintf("%s-%d-%s-%d-%d",
+		rule["int_ip"].(string),
+		rule["int_port"].(int),
+		rule["proto"].(string),
+		start,
+		PortRangeEnd(start, rule["ext_port_end"].(int))))
+}
+
+func flattenNatRule(rule NATRule) map[string]interface{} {
+	return map[string]interface{}{
+		"int_ip":         rule.LocalIP,
+		"int_port":       int(rule.LocalPort),
+		"ext_port_start": int(rule.PublicPortStart),
+		"ext_port_end":   int(rule.PublicPortEnd),
+		"proto":          rule.Protocol,
+		"rule_id":        int(rule.ID),
+	}
+}
+
+func utilityVinsNatRulesGet(ctx context.Context, m interface{}, vinsId string) (NATRuleList, error) {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+	natRuleList := NATRuleList{}
+
+	urlValues.Add("vinsId", vinsId)
+	natRulesRaw, err := c.DecortAPICall(ctx, "POST", VinsNatRuleListAPI, urlValues)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(natRulesRaw), &natRuleList)
+	if err != nil {
+		return nil, err
+	}
+	return natRuleList, nil
+}
+
+func utilityVinsNatRuleAdd(ctx context.Context, m interface{}, vinsId string, natRule map[string]interface{}) error {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+
+	urlValues.Add("vinsId", vinsId)
+	urlValues.Add("intIp", natRule["int_ip"].(string))
+	urlValues.Add("intPort", strconv.Itoa(natRule["int_port"].(int)))
+	urlValues.Add("extPortStart", strconv.Itoa(natRule["ext_port_start"].(int)))
+	if natRule["ext_port_end"].(int) != 0 {
+		urlValues.Add("extPortEnd", strconv.Itoa(natRule["ext_port_end"].(int)))
+	}
+	if natRule["proto"].(string) != "" {
+		urlValues.Add("proto", natRule["proto"].(string))
+	}
+
+	_, err := c.DecortAPICall(ctx, "POST", VinsNatRuleAddAPI, urlValues)
+	if err != nil {
+		return fmt.Errorf("cannot add nat rule %s:%d -> %d on vins %s: %w",
+			natRule["int_ip"].(string), natRule["int_port"].(int), natRule["ext_port_start"].(int), vinsId, err)
+	}
+	return nil
+}
+
+func utilityVinsNatRuleDel(ctx context.Context, m interface{}, vinsId string, ruleId int) error {
+	c := m.(*controller.ControllerCfg)
+	urlValues := &url.Values{}
+
+	urlValues.Add("vinsId", vinsId)
+	urlValues.Add("ruleId", strconv.Itoa(ruleId))
+
+	_, err := c.DecortAPICall(ctx, "POST", VinsNatRuleDelAPI, urlValues)
+	if err != nil {
+		return fmt.Errorf("cannot delete nat rule %d on vins %s: %w", ruleId, vinsId, err)
+	}
+	return nil
+}
+
+// utilityVinsNatRulesRead converges nat_rule against vins/natRuleList. In exclusive
+// mode every rule of the ViNS is reported, so that undeclared rules are removed on
+// the next apply. Otherwise only rules already tracked in the state are kept.
+func utilityVinsNatRulesRead(ctx context.Context, d *schema.ResourceData, m interface{}) error {
+	natRules, err := utilityVinsNatRulesGet(ctx, m, d.Id())
+	if err != nil {
+		return err
+	}
+
+	exclusive := d.Get("nat_rule_exclusive").(bool)
+	tracked := d.Get("nat_rule").(*schema.Set)
+
+	res := schema.NewSet(hashNatRule, []interface{}{})
+	for _, natRule := range natRules {
+		rule := flattenNatRule(natRule)
+		if exclusive || tracked.Contains(rule) {
+			res.Add(rule)
+		}
+	}
+
+	logger.Debugf(ctx, "utilityVinsNatRulesRead: vins %s has %d nat rules, %d tracked", d.Id(), len(natRules), res.Len())
+	return d.Set("nat_rule", res)
+}
+
+// utilityVinsNatRulesConfigure converges NAT rules of the ViNS to the declared nat_rule set.
+// Rules are deleted first to free external ports for the new ones. Declared rules which
+// already exist on the ViNS are adopted instead of being added again.
+func utilityVinsNatRulesConfigure(ctx context.Context, d *schema.ResourceData, m interface{}) error {
+	oldSet, newSet := d.GetChange("nat_rule")
+	declared := newSet.(*schema.Set)
+
+	natRules, err := utilityVinsNatRulesGet(ctx, m, d.Id())
+	if err != nil {
+		return err
+	}
+
+	exclusive := d.Get("nat_rule_exclusive").(bool)
+	existing := schema.NewSet(hashNatRule, []interface{}{})
+	for _, natRule := range natRules {
+		rule := flattenNatRule(natRule)
+		if declared.Contains(rule) {
+			existing.Add(rule)
+			continue
+		}
+		if !exclusive && !oldSet.(*schema.Set).Contains(rule) {
+			continue
+		}
+		if err := utilityVinsNatRuleDel(ctx, m, d.Id(), int(natRule.ID)); err != nil {
+			return err
+		}
+	}
+
+	for _, natRule := range declared.Difference(existing).List() {
+		if err := utilityVinsNatRuleAdd(ctx, m, d.Id(), natRule.(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// utilityVinsNatRulesCheckConflicts detects overlapping external port ranges at plan time:
+// between declared rules and between declared rules and rules that exist on the ViNS but
+// are not managed by this resource (e.g. port forwardings of computes).
+func utilityVinsNatRulesCheckConflicts(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	declared := d.Get("nat_rule").(*schema.Set)
+	rules := declared.List()
+
+	for i, ruleRaw := range rules {
+		rule := ruleRaw.(map[string]interface{})
+		start, end := rule["ext_port_start"].(int), rule["ext_port_end"].(int)
+		if end != 0 && end < start {
+			return fmt.Errorf("nat_rule with ext_port_start %d has ext_port_end %d less than start", start, end)
+		}
+
+		for _, otherRaw := range rules[i+1:] {
+			other := otherRaw.(map[string]interface{})
+			if PortRangesOverlap(rule["proto"].(string), start, end,
+				other["proto"].(string), other["ext_port_start"].(int), other["ext_port_end"].(int)) {
+				return fmt.Errorf("nat_rule external ports %d-%d/%s overlap with %d-%d/%s",
+					start, PortRangeEnd(start, end), rule["proto"].(string),
+					other["ext_port_start"].(int), PortRangeEnd(other["ext_port_start"].(int), other["ext_port_end"].(int)), other["proto"].(string))
+			}
+		}
+	}
+
+	// rules of a new ViNS cannot collide with anything but each other, and in exclusive
+	// mode all undeclared rules are deleted before the declared ones are added
+	if d.Id() == "" || d.Get("nat_rule_exclusive").(bool) {
+		return nil
+	}
+
+	natRules, err := utilityVinsNatRulesGet(ctx, m, d.Id())
+	if err != nil {
+		return err
+	}
+
+	oldSet, _ := d.GetChange("nat_rule")
+	for _, natRule := range natRules {
+		existing := flattenNatRule(natRule)
+		if oldSet.(*schema.Set).Contains(existing) || declared.Contains(existing) {
+			continue
+		}
+
+		for _, ruleRaw := range rules {
+			rule := ruleRaw.(map[string]interface{})
+			if PortRangesOverlap(rule["proto"].(string), rule["ext_port_start"].(int), rule["ext_port_end"].(int),
+				natRule.Protocol, int(natRule.PublicPortStart), int(natRule.PublicPortEnd)) {
+				return fmt.Errorf("nat_rule external ports %d-%d/%s overlap with rule %d (%s:%d, compute %d) not managed by this resource; set nat_rule_exclusive to remove it",
+					rule["ext_port_start"].(int), PortRangeEnd(rule["ext_port_start"].(int), rule["ext_port_end"].(int)), rule["proto"].(string),
+					natRule.ID, natRule.LocalIP, natRule.LocalPort, natRule.VMID)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright (c) 2019-2022 Digital Energy Cloud Solutions LLC. All Rights Reserved.
+Authors:
+Petr Krutov, <petr.krutov@digitalenergy.online>
+Stanislav Solovev, <spsolovev@digitalenergy.online>
+Kasim Baybikov, <kmbaybikov@basistech.ru>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Terraform DECORT provider - manage resources provided by DECORT (Digital Energy Cloud
+Orchestration Technology) with Terraform by Hashicorp.
+
+Source code: https://github.com/rudecs/terraform-provider-decort
+
+Please see README.md to learn where to place source code so that it
+builds seamlessly.
+
+Documentation: https://github.com/rudecs/terraform-provider-decort/wiki
+*/
+
+package vins
+
+import "testing"
+
+func TestPortRangesOverlap(t *testing.T) {
+	tests := []struct {
+		name         string
+		proto1       string
+		start1, end1 int
+		proto2       string
+		start2, end2 int
+		want         bool
+	}{
+		{name: "same single port", proto1: "tcp", start1: 80, proto2: "tcp", start2: 80, want: true},
+		{name: "different single ports", proto1: "tcp", start1: 80, proto2: "tcp", start2: 81},
+		{name: "different protocols", proto1: "tcp", start1: 80, proto2: "udp", start2: 80},
+		{name: "port inside range", proto1: "tcp", start1: 8000, end1: 8100, proto2: "tcp", start2: 8080, want: true},
+		{name: "range around port", proto1: "tcp", start1: 8080, proto2: "tcp", start2: 8000, end2: 8100, want: true},
+		{name: "adjacent ranges", proto1: "udp", start1: 1000, end1: 1999, proto2: "udp", start2: 2000, end2: 2999},
+		{name: "shared boundary", proto1: "udp", start1: 1000, end1: 2000, proto2: "udp", start2: 2000, end2: 2999, want: true},
+		{name: "nested ranges", proto1: "tcp", start1: 1000, end1: 5000, proto2: "tcp", start2: 2000, end2: 3000, want: true},
+		{name: "end equal to start", proto1: "tcp", start1: 80, end1: 80, proto2: "tcp", start2: 80, want: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := PortRangesOverlap(tc.proto1, tc.start1, tc.end1, tc.proto2, tc.start2, tc.end2); got != tc.want {
+				t.Errorf("PortRangesOverlap() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPortRangeEnd(t *testing.T) {
+	tests := []struct {
+		start, end int
+		want       int
+	}{
+		{start: 80, end: 0, want: 80},
+		{start: 80, end: 80, want: 80},
+		{start: 8000, end: 8100, want: 8100},
+	}
+
+	for _, tc := range tests {
+		if got := PortRangeEnd(tc.start, tc.end); got != tc.want {
+			t.Errorf("PortRangeEnd(%d, %d) = %d, want %d", tc.start, tc.end, got, tc.want)
+		}
+	}
+}
@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -55,7 +54,7 @@ func utilityAccountDeletedListCheckPresence(ctx context.Context, d *schema.Resou
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityAccountDeletedListCheckPresence: load")
+	logger.Debugf(ctx, "utilityAccountDeletedListCheckPresence: load")
 	accountDeletedListRaw, err := c.DecortAPICall(ctx, "POST", accountListDeletedAPI, urlValues)
 	if err != nil {
 		return nil, err
@@ -41,7 +41,6 @@ import (
 
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -186,7 +185,7 @@ func resourceDiskUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	if d.HasChange("size_max") {
 		oldSize, newSize := d.GetChange("size_max")
 		if oldSize.(int) < newSize.(int) {
-			log.Debugf("resourceDiskUpdate: resizing disk ID %s - %d GB -> %d GB",
+			logger.Debugf(ctx, "resourceDiskUpdate: resizing disk ID %s - %d GB -> %d GB",
 				d.Id(), oldSize.(int), newSize.(int))
 			urlValues.Add("diskId", d.Id())
 			urlValues.Add("size", fmt.Sprintf("%d", newSize.(int)))
@@ -39,7 +39,6 @@ import (
 	"strings"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -62,7 +61,7 @@ func utilityDiskListCheckPresence(ctx context.Context, d *schema.ResourceData, m
 		urlValues.Add("accountId", strconv.Itoa(accountId.(int)))
 	}
 
-	log.Debugf("utilityDiskListCheckPresence: load disk list")
+	logger.Debugf(ctx, "utilityDiskListCheckPresence: load disk list")
 	diskListRaw, err := c.DecortAPICall(ctx, "POST", disksListAPI, urlValues)
 	if err != nil {
 		return nil, err
@@ -39,7 +39,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -55,7 +54,7 @@ func utilityGridCheckPresence(ctx context.Context, d *schema.ResourceData, m int
 		return nil, errors.New("grid_id is required")
 	}
 
-	log.Debugf("utilityGridCheckPresence: load grid")
+	logger.Debugf(ctx, "utilityGridCheckPresence: load grid")
 	gridRaw, err := c.DecortAPICall(ctx, "POST", GridGetAPI, urlValues)
 	if err != nil {
 		return nil, err
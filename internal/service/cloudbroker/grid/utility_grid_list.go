@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -55,7 +54,7 @@ func utilityGridListCheckPresence(ctx context.Context, d *schema.ResourceData, m
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityGridListCheckPresence: load grid list")
+	logger.Debugf(ctx, "utilityGridListCheckPresence: load grid list")
 	gridListRaw, err := c.DecortAPICall(ctx, "POST", GridListGetAPI, urlValues)
 	if err != nil {
 		return nil, err
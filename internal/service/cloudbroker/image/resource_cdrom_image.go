@@ -40,11 +40,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceCDROMImageCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceCDROMImageCreate: called for image %s", d.Get("name").(string))
+	logger.Debugf(ctx, "resourceCDROMImageCreate: called for image %s", d.Get("name").(string))
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -116,7 +115,7 @@ func resourceCDROMImageCreate(ctx context.Context, d *schema.ResourceData, m int
 }
 
 func resourceCDROMImageDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceCDROMImageDelete: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceCDROMImageDelete: called for %s, id: %s", d.Get("name").(string), d.Id())
 
 	image, err := utilityImageCheckPresence(ctx, d, m)
 	if image == nil {
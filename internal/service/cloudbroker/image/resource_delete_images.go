@@ -41,7 +41,6 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceCreateListImages(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -51,7 +50,7 @@ func resourceCreateListImages(ctx context.Context, d *schema.ResourceData, m int
 }
 
 func resourceDeleteListImages(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceDeleteListImages: start deleting...")
+	logger.Debugf(ctx, "resourceDeleteListImages: start deleting...")
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
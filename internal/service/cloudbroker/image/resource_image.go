@@ -42,11 +42,10 @@ import (
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
 	"github.com/rudecs/terraform-provider-decort/internal/flattens"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceImageCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceImageCreate: called for image %s", d.Get("name").(string))
+	logger.Debugf(ctx, "resourceImageCreate: called for image %s", d.Get("name").(string))
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -129,7 +128,7 @@ func resourceImageCreate(ctx context.Context, d *schema.ResourceData, m interfac
 }
 
 func resourceImageRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceImageRead: called for %s id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceImageRead: called for %s id: %s", d.Get("name").(string), d.Id())
 
 	image, err := utilityImageCheckPresence(ctx, d, m)
 	if image == nil {
@@ -180,7 +179,7 @@ func resourceImageRead(ctx context.Context, d *schema.ResourceData, m interface{
 }
 
 func resourceImageDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceImageDelete: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceImageDelete: called for %s, id: %s", d.Get("name").(string), d.Id())
 
 	image, err := utilityImageCheckPresence(ctx, d, m)
 	if image == nil {
@@ -212,7 +211,7 @@ func resourceImageDelete(ctx context.Context, d *schema.ResourceData, m interfac
 }
 
 func resourceImageEditName(ctx context.Context, d *schema.ResourceData, m interface{}) error {
-	log.Debugf("resourceImageEditName: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceImageEditName: called for %s, id: %s", d.Get("name").(string), d.Id())
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 	urlValues.Add("imageId", strconv.Itoa(d.Get("image_id").(int)))
@@ -226,7 +225,7 @@ func resourceImageEditName(ctx context.Context, d *schema.ResourceData, m interf
 }
 
 func resourceImageEdit(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceImageEdit: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceImageEdit: called for %s, id: %s", d.Get("name").(string), d.Id())
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 
@@ -323,7 +322,7 @@ func resourceImageChangeEnabled(ctx context.Context, d *schema.ResourceData, m i
 }
 
 func resourceImageLink(ctx context.Context, d *schema.ResourceData, m interface{}) error {
-	log.Debugf("resourceVirtualImageLink: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceVirtualImageLink: called for %s, id: %s", d.Get("name").(string), d.Id())
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 	urlValues.Add("imageId", strconv.Itoa(d.Get("image_id").(int)))
@@ -337,7 +336,7 @@ func resourceImageLink(ctx context.Context, d *schema.ResourceData, m interface{
 }
 
 func resourceImageShare(ctx context.Context, d *schema.ResourceData, m interface{}) error {
-	log.Debugf("resourceImageShare: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceImageShare: called for %s, id: %s", d.Get("name").(string), d.Id())
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 	urlValues.Add("imageId", strconv.Itoa(d.Get("image_id").(int)))
@@ -388,7 +387,7 @@ func resourceImageChangeComputeci(ctx context.Context, d *schema.ResourceData, m
 }
 
 func resourceImageUpdateNodes(ctx context.Context, d *schema.ResourceData, m interface{}) error {
-	log.Debugf("resourceImageUpdateNodes: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourceImageUpdateNodes: called for %s, id: %s", d.Get("name").(string), d.Id())
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 	urlValues.Add("imageId", strconv.Itoa(d.Get("image_id").(int)))
@@ -40,11 +40,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceVirtualImageCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceImageCreate: called for image %s", d.Get("name").(string))
+	logger.Debugf(ctx, "resourceImageCreate: called for image %s", d.Get("name").(string))
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
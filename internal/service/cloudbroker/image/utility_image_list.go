@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -62,7 +61,7 @@ func utilityImageListCheckPresence(ctx context.Context, d *schema.ResourceData,
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityImageListCheckPresence: load image list")
+	logger.Debugf(ctx, "utilityImageListCheckPresence: load image list")
 	imageListRaw, err := c.DecortAPICall(ctx, "POST", imageListGetAPI, urlValues)
 	if err != nil {
 		return nil, err
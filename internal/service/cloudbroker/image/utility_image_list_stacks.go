@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -50,7 +49,7 @@ func utilityImageListStacksCheckPresence(ctx context.Context, d *schema.Resource
 
 	urlValues.Add("imageId", strconv.Itoa(d.Get("image_id").(int)))
 
-	log.Debugf("utilityImageListStacksCheckPresence: load image list")
+	logger.Debugf(ctx, "utilityImageListStacksCheckPresence: load image list")
 	imageListRaw, err := c.DecortAPICall(ctx, "POST", imageListStacksApi, urlValues)
 	if err != nil {
 		return nil, err
@@ -44,11 +44,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceK8sCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sCreate: called with name %s, rg %d", d.Get("name").(string), d.Get("rg_id").(int))
+	logger.Debugf(ctx, "resourceK8sCreate: called with name %s, rg %d", d.Get("name").(string), d.Get("rg_id").(int))
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -112,7 +111,7 @@ func resourceK8sCreate(ctx context.Context, d *schema.ResourceData, m interface{
 		if err := json.Unmarshal([]byte(resp), &task); err != nil {
 			return diag.FromErr(err)
 		}
-		log.Debugf("resourceK8sCreate: instance creating - %s", task.Stage)
+		logger.Debugf(ctx, "resourceK8sCreate: instance creating - %s", task.Stage)
 
 		if task.Completed {
 			if task.Error != "" {
@@ -152,7 +151,7 @@ func resourceK8sCreate(ctx context.Context, d *schema.ResourceData, m interface{
 	urlValues.Add("k8sId", d.Id())
 	kubeconfig, err := c.DecortAPICall(ctx, "POST", K8sGetConfigAPI, urlValues)
 	if err != nil {
-		log.Warnf("could not get kubeconfig: %v", err)
+		logger.Warnf(ctx, "could not get kubeconfig: %v", err)
 	}
 	d.Set("kubeconfig", kubeconfig)
 
@@ -160,7 +159,7 @@ func resourceK8sCreate(ctx context.Context, d *schema.ResourceData, m interface{
 }
 
 func resourceK8sRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sRead: called with id %s, rg %d", d.Id(), d.Get("rg_id").(int))
+	logger.Debugf(ctx, "resourceK8sRead: called with id %s, rg %d", d.Id(), d.Get("rg_id").(int))
 
 	k8s, err := utilityK8sCheckPresence(ctx, d, m)
 	if k8s == nil {
@@ -196,7 +195,7 @@ func resourceK8sRead(ctx context.Context, d *schema.ResourceData, m interface{})
 	urlValues.Add("k8sId", d.Id())
 	kubeconfig, err := c.DecortAPICall(ctx, "POST", K8sGetConfigAPI, urlValues)
 	if err != nil {
-		log.Warnf("could not get kubeconfig: %v", err)
+		logger.Warnf(ctx, "could not get kubeconfig: %v", err)
 	}
 	d.Set("kubeconfig", kubeconfig)
 
@@ -204,7 +203,7 @@ func resourceK8sRead(ctx context.Context, d *schema.ResourceData, m interface{})
 }
 
 func resourceK8sUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sUpdate: called with id %s, rg %d", d.Id(), d.Get("rg_id").(int))
+	logger.Debugf(ctx, "resourceK8sUpdate: called with id %s, rg %d", d.Id(), d.Get("rg_id").(int))
 
 	c := m.(*controller.ControllerCfg)
 
@@ -251,7 +250,7 @@ func resourceK8sUpdate(ctx context.Context, d *schema.ResourceData, m interface{
 }
 
 func resourceK8sDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sDelete: called with id %s, rg %d", d.Id(), d.Get("rg_id").(int))
+	logger.Debugf(ctx, "resourceK8sDelete: called with id %s, rg %d", d.Id(), d.Get("rg_id").(int))
 
 	k8s, err := utilityK8sCheckPresence(ctx, d, m)
 	if k8s == nil {
@@ -33,18 +33,19 @@ package k8s
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceK8sWgCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sWgCreate: called with k8s id %d", d.Get("k8s_id").(int))
+	logger.Debugf(ctx, "resourceK8sWgCreate: called with k8s id %d", d.Get("k8s_id").(int))
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -95,7 +96,7 @@ func resourceK8sWgCreate(ctx context.Context, d *schema.ResourceData, m interfac
 }
 
 func resourceK8sWgRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sWgRead: called with k8s id %d", d.Get("k8s_id").(int))
+	logger.Debugf(ctx, "resourceK8sWgRead: called with k8s id %d", d.Get("k8s_id").(int))
 
 	wg, err := utilityK8sWgCheckPresence(ctx, d, m)
 	if wg == nil {
@@ -113,7 +114,7 @@ func resourceK8sWgRead(ctx context.Context, d *schema.ResourceData, m interface{
 }
 
 func resourceK8sWgUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sWgUpdate: called with k8s id %d", d.Get("k8s_id").(int))
+	logger.Debugf(ctx, "resourceK8sWgUpdate: called with k8s id %d", d.Get("k8s_id").(int))
 
 	c := m.(*controller.ControllerCfg)
 
@@ -146,7 +147,7 @@ func resourceK8sWgUpdate(ctx context.Context, d *schema.ResourceData, m interfac
 }
 
 func resourceK8sWgDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceK8sWgDelete: called with k8s id %d", d.Get("k8s_id").(int))
+	logger.Debugf(ctx, "resourceK8sWgDelete: called with k8s id %d", d.Get("k8s_id").(int))
 
 	wg, err := utilityK8sWgCheckPresence(ctx, d, m)
 	if wg == nil {
@@ -218,6 +219,37 @@ func resourceK8sWgSchemaMake() map[string]*schema.Schema {
 	}
 }
 
+func resourceK8sWgImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourceK8sWgImport: called with id %s", d.Id())
+
+	parameters := strings.Split(d.Id(), "#")
+	if len(parameters) != 2 {
+		return nil, fmt.Errorf("invalid import id %q: expected <k8s_id>#<wg_id>", d.Id())
+	}
+
+	k8sId, err := strconv.Atoi(parameters[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid import id %q: k8s id must be a number", d.Id())
+	}
+	wgId, err := strconv.Atoi(parameters[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid import id %q: worker group id must be a number", d.Id())
+	}
+
+	d.SetId(parameters[1])
+	d.Set("k8s_id", k8sId)
+
+	wg, err := utilityK8sWgCheckPresence(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+	if wg == nil {
+		return nil, fmt.Errorf("worker group %d not found in k8s cluster %d", wgId, k8sId)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func ResourceK8sWg() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
@@ -228,7 +260,7 @@ func ResourceK8sWg() *schema.Resource {
 		DeleteContext: resourceK8sWgDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceK8sWgImport,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -39,7 +39,6 @@ import (
 	// "net/url"
 
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -48,11 +47,11 @@ import (
 
 // Parse list of all disks from API compute/get into a list of "extra disks" attached to this compute
 // Extra disks are all compute disks but a boot disk.
-func parseComputeDisksToExtraDisks(disks []DiskRecord) []interface{} {
+func parseComputeDisksToExtraDisks(ctx context.Context, disks []DiskRecord) []interface{} {
 	// this return value will be used to d.Set("extra_disks",) item of dataSourceCompute schema,
 	// which is a simple list of integer disk IDs excluding boot disk ID
 	length := len(disks)
-	log.Debugf("parseComputeDisksToExtraDisks: called for %d disks", length)
+	logger.Debugf(ctx, "parseComputeDisksToExtraDisks: called for %d disks", length)
 
 	if length == 0 || (length == 1 && disks[0].Type == "B") {
 		// the disk list is empty (which is kind of strange - diskless compute?), or
@@ -89,10 +88,10 @@ func findBootDisk(disks []DiskRecord) *DiskRecord {
 
 // Parse the list of interfaces from compute/get response into a list of networks
 // attached to this compute
-func parseComputeInterfacesToNetworks(ifaces []InterfaceRecord) []interface{} {
+func parseComputeInterfacesToNetworks(ctx context.Context, ifaces []InterfaceRecord) []interface{} {
 	// return value will be used to d.Set("network") item of dataSourceCompute schema
 	length := len(ifaces)
-	log.Debugf("parseComputeInterfacesToNetworks: called for %d ifaces", length)
+	logger.Debugf(ctx, "parseComputeInterfacesToNetworks: called for %d ifaces", length)
 
 	result := []interface{}{}
 
@@ -113,20 +112,20 @@ func parseComputeInterfacesToNetworks(ifaces []InterfaceRecord) []interface{} {
 	return result
 }
 
-func flattenCompute(d *schema.ResourceData, compFacts string) error {
+func flattenCompute(ctx context.Context, d *schema.ResourceData, compFacts string) error {
 	// This function expects that compFacts string contains response from API compute/get,
 	// i.e. detailed information about compute instance.
 	//
 	// NOTE: this function modifies ResourceData argument - as such it should never be called
 	// from resourceComputeExists(...) method
 	model := ComputeGetResp{}
-	log.Debugf("flattenCompute: ready to unmarshal string %s", compFacts)
+	logger.Debugf(ctx, "flattenCompute: ready to unmarshal string %s", compFacts)
 	err := json.Unmarshal([]byte(compFacts), &model)
 	if err != nil {
 		return err
 	}
 
-	log.Debugf("flattenCompute: ID %d, RG ID %d", model.ID, model.RgID)
+	logger.Debugf(ctx, "flattenCompute: ID %d, RG ID %d", model.ID, model.RgID)
 
 	d.SetId(fmt.Sprintf("%d", model.ID))
 	// d.Set("compute_id", model.ID) - we should NOT set compute_id in the schema here: if it was set - it is already set, if it wasn't - we shouldn't
@@ -159,22 +158,22 @@ func flattenCompute(d *schema.ResourceData, compFacts string) error {
 	d.Set("pool", bootDisk.Pool)
 
 	if len(model.Disks) > 0 {
-		log.Debugf("flattenCompute: calling parseComputeDisksToExtraDisks for %d disks", len(model.Disks))
-		if err = d.Set("extra_disks", parseComputeDisksToExtraDisks(model.Disks)); err != nil {
+		logger.Debugf(ctx, "flattenCompute: calling parseComputeDisksToExtraDisks for %d disks", len(model.Disks))
+		if err = d.Set("extra_disks", parseComputeDisksToExtraDisks(ctx, model.Disks)); err != nil {
 			return err
 		}
 	}
 
 	if len(model.Interfaces) > 0 {
-		log.Debugf("flattenCompute: calling parseComputeInterfacesToNetworks for %d interfaces", len(model.Interfaces))
-		if err = d.Set("network", parseComputeInterfacesToNetworks(model.Interfaces)); err != nil {
+		logger.Debugf(ctx, "flattenCompute: calling parseComputeInterfacesToNetworks for %d interfaces", len(model.Interfaces))
+		if err = d.Set("network", parseComputeInterfacesToNetworks(ctx, model.Interfaces)); err != nil {
 			return err
 		}
 	}
 
 	if len(model.OsUsers) > 0 {
-		log.Debugf("flattenCompute: calling parseOsUsers for %d logins", len(model.OsUsers))
-		if err = d.Set("os_users", parseOsUsers(model.OsUsers)); err != nil {
+		logger.Debugf(ctx, "flattenCompute: calling parseOsUsers for %d logins", len(model.OsUsers))
+		if err = d.Set("os_users", parseOsUsers(ctx, model.OsUsers)); err != nil {
 			return err
 		}
 	}
@@ -191,7 +190,7 @@ func dataSourceComputeRead(ctx context.Context, d *schema.ResourceData, m interf
 		return diag.FromErr(err)
 	}
 
-	if err = flattenCompute(d, compFacts); err != nil {
+	if err = flattenCompute(ctx, d, compFacts); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -40,7 +40,6 @@ import (
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
 	"github.com/rudecs/terraform-provider-decort/internal/statefuncs"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -53,10 +52,8 @@ func cloudInitDiffSupperss(key, oldVal, newVal string, d *schema.ResourceData) b
 		// and there is a chance that the user will want custom cloud init parameters - so we check if
 		// cloud_init is explicitly set in TF file by making sure that its new value is different from "applied",
 		// which is a reserved key word.
-		log.Debugf("cloudInitDiffSupperss: key=%s, oldVal=%q, newVal=%q -> suppress=FALSE", key, oldVal, newVal)
 		return false // there is a difference between stored and new value
 	}
-	log.Debugf("cloudInitDiffSupperss: key=%s, oldVal=%q, newVal=%q -> suppress=TRUE", key, oldVal, newVal)
 	return true // suppress difference
 }
 
@@ -64,7 +61,7 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 	// we assume all mandatory parameters it takes to create a comptue instance are properly
 	// specified - we rely on schema "Required" attributes to let Terraform validate them for us
 
-	log.Debugf("resourceComputeCreate: called for Compute name %q, RG ID %d", d.Get("name").(string), d.Get("rg_id").(int))
+	logger.Debugf(ctx, "resourceComputeCreate: called for Compute name %q, RG ID %d", d.Get("name").(string), d.Get("rg_id").(int))
 
 	// create basic Compute (i.e. without extra disks and network connections - those will be attached
 	// by subsequent individual API calls).
@@ -105,9 +102,9 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 	driver := d.Get("driver").(string)
 	if driver == "KVM_PPC" {
 		computeCreateAPI = KvmPPCCreateAPI
-		log.Debugf("resourceComputeCreate: creating Compute of type KVM VM PowerPC")
+		logger.Debugf(ctx, "resourceComputeCreate: creating Compute of type KVM VM PowerPC")
 	} else { // note that we do not validate arch value for explicit "KVM_X86" here
-		log.Debugf("resourceComputeCreate: creating Compute of type KVM VM x86")
+		logger.Debugf(ctx, "resourceComputeCreate: creating Compute of type KVM VM x86")
 	}
 
 	argVal, argSet = d.GetOk("cloud_init")
@@ -137,22 +134,22 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 			urlValues.Add("detachDisks", "1")
 
 			if _, err := c.DecortAPICall(ctx, "POST", ComputeDeleteAPI, urlValues); err != nil {
-				log.Errorf("resourceComputeCreate: could not delete compute after failed creation: %v", err)
+				logger.Errorf(ctx, "resourceComputeCreate: could not delete compute after failed creation: %v", err)
 			}
 			d.SetId("")
 		}
 	}()
 
-	log.Debugf("resourceComputeCreate: new simple Compute ID %d, name %s created", compId, d.Get("name").(string))
+	logger.Debugf(ctx, "resourceComputeCreate: new simple Compute ID %d, name %s created", compId, d.Get("name").(string))
 
 	// Configure data disks if any
 	argVal, argSet = d.GetOk("extra_disks")
 	if argSet && argVal.(*schema.Set).Len() > 0 {
 		// urlValues.Add("desc", argVal.(string))
-		log.Debugf("resourceComputeCreate: calling utilityComputeExtraDisksConfigure to attach %d extra disk(s)", argVal.(*schema.Set).Len())
+		logger.Debugf(ctx, "resourceComputeCreate: calling utilityComputeExtraDisksConfigure to attach %d extra disk(s)", argVal.(*schema.Set).Len())
 		err = utilityComputeExtraDisksConfigure(ctx, d, m, false) // do_delta=false, as we are working on a new compute
 		if err != nil {
-			log.Errorf("resourceComputeCreate: error when attaching extra disk(s) to a new Compute ID %d: %v", compId, err)
+			logger.Errorf(ctx, "resourceComputeCreate: error when attaching extra disk(s) to a new Compute ID %d: %v", compId, err)
 			cleanup = true
 			return diag.FromErr(err)
 		}
@@ -160,10 +157,10 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 	// Configure external networks if any
 	argVal, argSet = d.GetOk("network")
 	if argSet && argVal.(*schema.Set).Len() > 0 {
-		log.Debugf("resourceComputeCreate: calling utilityComputeNetworksConfigure to attach %d network(s)", argVal.(*schema.Set).Len())
+		logger.Debugf(ctx, "resourceComputeCreate: calling utilityComputeNetworksConfigure to attach %d network(s)", argVal.(*schema.Set).Len())
 		err = utilityComputeNetworksConfigure(ctx, d, m, false) // do_delta=false, as we are working on a new compute
 		if err != nil {
-			log.Errorf("resourceComputeCreate: error when attaching networks to a new Compute ID %d: %s", compId, err)
+			logger.Errorf(ctx, "resourceComputeCreate: error when attaching networks to a new Compute ID %d: %s", compId, err)
 			cleanup = true
 			return diag.FromErr(err)
 		}
@@ -174,14 +171,14 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 	if d.Get("started").(bool) {
 		reqValues := &url.Values{}
 		reqValues.Add("computeId", fmt.Sprintf("%d", compId))
-		log.Debugf("resourceComputeCreate: starting Compute ID %d after completing its resource configuration", compId)
+		logger.Debugf(ctx, "resourceComputeCreate: starting Compute ID %d after completing its resource configuration", compId)
 		if _, err := c.DecortAPICall(ctx, "POST", ComputeStartAPI, reqValues); err != nil {
 			cleanup = true
 			return diag.FromErr(err)
 		}
 	}
 
-	log.Debugf("resourceComputeCreate: new Compute ID %d, name %s creation sequence complete", compId, d.Get("name").(string))
+	logger.Debugf(ctx, "resourceComputeCreate: new Compute ID %d, name %s creation sequence complete", compId, d.Get("name").(string))
 
 	// We may reuse dataSourceComputeRead here as we maintain similarity
 	// between Compute resource and Compute data source schemas
@@ -191,7 +188,7 @@ func resourceComputeCreate(ctx context.Context, d *schema.ResourceData, m interf
 }
 
 func resourceComputeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceComputeRead: called for Compute name %s, RG ID %d",
+	logger.Debugf(ctx, "resourceComputeRead: called for Compute name %s, RG ID %d",
 		d.Get("name").(string), d.Get("rg_id").(int))
 
 	compFacts, err := utilityComputeCheckPresence(ctx, d, m)
@@ -203,18 +200,18 @@ func resourceComputeRead(ctx context.Context, d *schema.ResourceData, m interfac
 		return nil
 	}
 
-	if err = flattenCompute(d, compFacts); err != nil {
+	if err = flattenCompute(ctx, d, compFacts); err != nil {
 		return diag.FromErr(err)
 	}
 
-	log.Debugf("resourceComputeRead: after flattenCompute: Compute ID %s, name %q, RG ID %d",
+	logger.Debugf(ctx, "resourceComputeRead: after flattenCompute: Compute ID %s, name %q, RG ID %d",
 		d.Id(), d.Get("name").(string), d.Get("rg_id").(int))
 
 	return nil
 }
 
 func resourceComputeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceComputeUpdate: called for Compute ID %s / name %s, RGID %d",
+	logger.Debugf(ctx, "resourceComputeUpdate: called for Compute ID %s / name %s, RGID %d",
 		d.Id(), d.Get("name").(string), d.Get("rg_id").(int))
 
 	c := m.(*controller.ControllerCfg)
@@ -249,7 +246,7 @@ func resourceComputeUpdate(ctx context.Context, d *schema.ResourceData, m interf
 	}
 
 	if doUpdate {
-		log.Debugf("resourceComputeUpdate: changing CPU %d -> %d and/or RAM %d -> %d",
+		logger.Debugf(ctx, "resourceComputeUpdate: changing CPU %d -> %d and/or RAM %d -> %d",
 			oldCpu.(int), newCpu.(int),
 			oldRam.(int), newRam.(int))
 		params.Add("force", "true")
@@ -265,14 +262,14 @@ func resourceComputeUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		bdsParams := &url.Values{}
 		bdsParams.Add("diskId", fmt.Sprintf("%d", d.Get("boot_disk_id").(int)))
 		bdsParams.Add("size", fmt.Sprintf("%d", newSize.(int)))
-		log.Debugf("resourceComputeUpdate: compute ID %s, boot disk ID %d resize %d -> %d",
+		logger.Debugf(ctx, "resourceComputeUpdate: compute ID %s, boot disk ID %d resize %d -> %d",
 			d.Id(), d.Get("boot_disk_id").(int), oldSize.(int), newSize.(int))
 		_, err := c.DecortAPICall(ctx, "POST", DisksResizeAPI, bdsParams)
 		if err != nil {
 			return diag.FromErr(err)
 		}
 	} else if oldSize.(int) > newSize.(int) {
-		log.Warnf("resourceComputeUpdate: compute ID %s - shrinking boot disk is not allowed", d.Id())
+		logger.Warnf(ctx, "resourceComputeUpdate: compute ID %s - shrinking boot disk is not allowed", d.Id())
 	}
 
 	// 3. Calculate and apply changes to data disks
@@ -311,7 +308,7 @@ func resourceComputeDelete(ctx context.Context, d *schema.ResourceData, m interf
 	// there is no way to restore it.
 	// If compute being destroyed has some extra disks attached, they are
 	// detached from the compute
-	log.Debugf("resourceComputeDelete: called for Compute name %s, RG ID %d",
+	logger.Debugf(ctx, "resourceComputeDelete: called for Compute name %s, RG ID %d",
 		d.Get("name").(string), d.Get("rg_id").(int))
 
 	c := m.(*controller.ControllerCfg)
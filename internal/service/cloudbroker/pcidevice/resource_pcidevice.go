@@ -41,11 +41,10 @@ import (
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
 	"github.com/rudecs/terraform-provider-decort/internal/flattens"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourcePcideviceCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourcePcideviceCreate: called for pcidevice %s", d.Get("name").(string))
+	logger.Debugf(ctx, "resourcePcideviceCreate: called for pcidevice %s", d.Get("name").(string))
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -98,7 +97,7 @@ func resourcePcideviceRead(ctx context.Context, d *schema.ResourceData, m interf
 }
 
 func resourcePcideviceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourcePcideviceDelete: called for %s, id: %s", d.Get("name").(string), d.Id())
+	logger.Debugf(ctx, "resourcePcideviceDelete: called for %s, id: %s", d.Get("name").(string), d.Id())
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
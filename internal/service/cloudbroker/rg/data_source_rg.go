@@ -37,7 +37,6 @@ import (
 	"fmt"
 
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
-	log "github.com/sirupsen/logrus"
 
 	// "net/url"
 
@@ -45,18 +44,18 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-func flattenResgroup(d *schema.ResourceData, rg_facts string) error {
+func flattenResgroup(ctx context.Context, d *schema.ResourceData, rg_facts string) error {
 	// NOTE: this function modifies ResourceData argument - as such it should never be called
 	// from resourceRsgroupExists(...) method
 	// log.Debugf("%s", rg_facts)
-	log.Debugf("flattenResgroup: ready to decode response body from API")
+	logger.Debugf(ctx, "flattenResgroup: ready to decode response body from API")
 	details := ResgroupGetResp{}
 	err := json.Unmarshal([]byte(rg_facts), &details)
 	if err != nil {
 		return err
 	}
 
-	log.Debugf("flattenResgroup: decoded RG name %q / ID %d, account ID %d",
+	logger.Debugf(ctx, "flattenResgroup: decoded RG name %q / ID %d, account ID %d",
 		details.Name, details.ID, details.AccountID)
 
 	d.SetId(fmt.Sprintf("%d", details.ID))
@@ -74,7 +73,7 @@ func flattenResgroup(d *schema.ResourceData, rg_facts string) error {
 		d.Set("computes", details.Computes)
 	*/
 
-	log.Debugf("flattenResgroup: calling flattenQuota()")
+	logger.Debugf(ctx, "flattenResgroup: calling flattenQuota()")
 	if err = d.Set("quota", parseQuota(details.Quota)); err != nil {
 		return err
 	}
@@ -91,7 +90,7 @@ func dataSourceResgroupRead(ctx context.Context, d *schema.ResourceData, m inter
 		return diag.FromErr(err)
 	}
 
-	return diag.FromErr(flattenResgroup(d, rg_facts))
+	return diag.FromErr(flattenResgroup(ctx, d, rg_facts))
 }
 
 func DataSourceResgroup() *schema.Resource {
@@ -39,7 +39,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -83,7 +82,7 @@ func utilityResgroupCheckPresence(ctx context.Context, d *schema.ResourceData, m
 
 	if idSet {
 		// go straight for the RG by its ID
-		log.Debugf("utilityResgroupCheckPresence: locating RG by its ID %d", theId)
+		logger.Debugf(ctx, "utilityResgroupCheckPresence: locating RG by its ID %d", theId)
 		urlValues.Add("rgId", fmt.Sprintf("%d", theId))
 		rgFacts, err := c.DecortAPICall(ctx, "POST", ResgroupGetAPI, urlValues)
 		if err != nil {
@@ -107,18 +106,18 @@ func utilityResgroupCheckPresence(ctx context.Context, d *schema.ResourceData, m
 		return "", err
 	}
 	// log.Debugf("%s", apiResp)
-	log.Debugf("utilityResgroupCheckPresence: ready to decode response body from %s", ResgroupListAPI)
+	logger.Debugf(ctx, "utilityResgroupCheckPresence: ready to decode response body from %s", ResgroupListAPI)
 	model := ResgroupListResp{}
 	err = json.Unmarshal([]byte(apiResp), &model)
 	if err != nil {
 		return "", err
 	}
 
-	log.Debugf("utilityResgroupCheckPresence: traversing decoded Json of length %d", len(model))
+	logger.Debugf(ctx, "utilityResgroupCheckPresence: traversing decoded Json of length %d", len(model))
 	for index, item := range model {
 		// match by RG name & account ID
 		if item.Name == rgName.(string) && item.AccountID == d.Get("account_id").(int) {
-			log.Debugf("utilityResgroupCheckPresence: match RG name %s / ID %d, account ID %d at index %d",
+			logger.Debugf(ctx, "utilityResgroupCheckPresence: match RG name %s / ID %d, account ID %d at index %d",
 				item.Name, item.ID, item.AccountID, index)
 
 			// not all required information is returned by rg/list API, so we need to initiate one more
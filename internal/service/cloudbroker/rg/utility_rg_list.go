@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -59,7 +58,7 @@ func utilityRgListCheckPresence(ctx context.Context, d *schema.ResourceData, m i
 		urlValues.Add("includedeleted", strconv.FormatBool(includedeleted.(bool)))
 	}
 
-	log.Debugf("utilityRgListCheckPresence: load rg list")
+	logger.Debugf(ctx, "utilityRgListCheckPresence: load rg list")
 	rgListRaw, err := c.DecortAPICall(ctx, "POST", ResgroupListAPI, urlValues)
 	if err != nil {
 		return nil, err
@@ -43,11 +43,10 @@ import (
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
 	"github.com/rudecs/terraform-provider-decort/internal/flattens"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceSepCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceSepCreate: called for sep %s", d.Get("name").(string))
+	logger.Debugf(ctx, "resourceSepCreate: called for sep %s", d.Get("name").(string))
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -109,7 +108,7 @@ func resourceSepCreate(ctx context.Context, d *schema.ResourceData, m interface{
 }
 
 func resourceSepRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceSepRead: called for %s id: %d", d.Get("name").(string), d.Get("sep_id").(int))
+	logger.Debugf(ctx, "resourceSepRead: called for %s id: %d", d.Get("name").(string), d.Get("sep_id").(int))
 
 	sep, err := utilitySepCheckPresence(ctx, d, m)
 	if sep == nil {
@@ -137,7 +136,7 @@ func resourceSepRead(ctx context.Context, d *schema.ResourceData, m interface{})
 }
 
 func resourceSepDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceSepDelete: called for %s, id: %d", d.Get("name").(string), d.Get("sep_id").(int))
+	logger.Debugf(ctx, "resourceSepDelete: called for %s, id: %d", d.Get("name").(string), d.Get("sep_id").(int))
 
 	sepDes, err := utilitySepCheckPresence(ctx, d, m)
 	if sepDes == nil {
@@ -161,7 +160,7 @@ func resourceSepDelete(ctx context.Context, d *schema.ResourceData, m interface{
 }
 
 func resourceSepEdit(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceSepEdit: called for %s, id: %d", d.Get("name").(string), d.Get("sep_id").(int))
+	logger.Debugf(ctx, "resourceSepEdit: called for %s, id: %d", d.Get("name").(string), d.Get("sep_id").(int))
 	c := m.(*controller.ControllerCfg)
 
 	urlValues := &url.Values{}
@@ -277,7 +276,7 @@ func resourceSepChangeEnabled(ctx context.Context, d *schema.ResourceData, m int
 }
 
 func resourceSepUpdateNodes(ctx context.Context, d *schema.ResourceData, m interface{}) error {
-	log.Debugf("resourceSepUpdateNodes: called for %s, id: %d", d.Get("name").(string), d.Get("sep_id").(int))
+	logger.Debugf(ctx, "resourceSepUpdateNodes: called for %s, id: %d", d.Get("name").(string), d.Get("sep_id").(int))
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 
@@ -329,7 +328,7 @@ func findElInt(sl []interface{}, el interface{}) bool {
 }
 
 func resourceSepUpdateProviders(ctx context.Context, d *schema.ResourceData, m interface{}) error {
-	log.Debugf("resourceSepUpdateProviders: called for %s, id: %d", d.Get("name").(string), d.Get("sep_id").(int))
+	logger.Debugf(ctx, "resourceSepUpdateProviders: called for %s, id: %d", d.Get("name").(string), d.Get("sep_id").(int))
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
 	urlValues.Add("sep_id", strconv.Itoa(d.Get("sep_id").(int)))
@@ -42,11 +42,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceSepConfigCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceSepConfigCreate: called for sep id %d", d.Get("sep_id").(int))
+	logger.Debugf(ctx, "resourceSepConfigCreate: called for sep id %d", d.Get("sep_id").(int))
 
 	if _, ok := d.GetOk("sep_id"); ok {
 		if exists, err := resourceSepConfigExists(ctx, d, m); exists {
@@ -69,7 +68,7 @@ func resourceSepConfigCreate(ctx context.Context, d *schema.ResourceData, m inte
 }
 
 func resourceSepConfigRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceSepConfigRead: called for sep id: %d", d.Get("sep_id").(int))
+	logger.Debugf(ctx, "resourceSepConfigRead: called for sep id: %d", d.Get("sep_id").(int))
 
 	sepConfig, err := utilitySepConfigCheckPresence(ctx, d, m)
 	if sepConfig == nil {
@@ -87,7 +86,7 @@ func resourceSepConfigDelete(ctx context.Context, d *schema.ResourceData, m inte
 }
 
 func resourceSepConfigExists(ctx context.Context, d *schema.ResourceData, m interface{}) (bool, error) {
-	log.Debugf("resourceSepConfigExists: called for sep id: %d", d.Get("sep_id").(int))
+	logger.Debugf(ctx, "resourceSepConfigExists: called for sep id: %d", d.Get("sep_id").(int))
 
 	sepDesConfig, err := utilitySepConfigCheckPresence(ctx, d, m)
 	if sepDesConfig == nil {
@@ -101,7 +100,7 @@ func resourceSepConfigExists(ctx context.Context, d *schema.ResourceData, m inte
 }
 
 func resourceSepConfigEdit(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceSepConfigEdit: called for sep id: %d", d.Get("sep_id").(int))
+	logger.Debugf(ctx, "resourceSepConfigEdit: called for sep id: %d", d.Get("sep_id").(int))
 	c := m.(*controller.ControllerCfg)
 
 	urlValues := &url.Values{}
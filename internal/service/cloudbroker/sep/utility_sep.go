@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -55,7 +54,7 @@ func utilitySepCheckPresence(ctx context.Context, d *schema.ResourceData, m inte
 		urlValues.Add("sep_id", strconv.Itoa(d.Get("sep_id").(int)))
 	}
 
-	log.Debugf("utilitySepCheckPresence: load sep")
+	logger.Debugf(ctx, "utilitySepCheckPresence: load sep")
 	sepRaw, err := c.DecortAPICall(ctx, "POST", sepGetAPI, urlValues)
 	if err != nil {
 		return nil, err
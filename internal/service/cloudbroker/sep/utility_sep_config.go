@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -51,7 +50,7 @@ func utilitySepConfigCheckPresence(ctx context.Context, d *schema.ResourceData,
 
 	urlValues.Add("sep_id", strconv.Itoa(d.Get("sep_id").(int)))
 
-	log.Debugf("utilitySepConfigCheckPresence: load sep config")
+	logger.Debugf(ctx, "utilitySepConfigCheckPresence: load sep config")
 	sepConfigRaw, err := c.DecortAPICall(ctx, "POST", sepGetConfigAPI, urlValues)
 	if err != nil {
 		return nil, err
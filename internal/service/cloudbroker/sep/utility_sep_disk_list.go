@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -55,7 +54,7 @@ func utilitySepDiskListCheckPresence(ctx context.Context, d *schema.ResourceData
 		urlValues.Add("pool_name", poolName.(string))
 	}
 
-	log.Debugf("utilitySepDiskListCheckPresence: load sep")
+	logger.Debugf(ctx, "utilitySepDiskListCheckPresence: load sep")
 	sepDiskListRaw, err := c.DecortAPICall(ctx, "POST", sepDiskListAPI, urlValues)
 	if err != nil {
 		return nil, err
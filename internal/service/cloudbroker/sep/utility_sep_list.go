@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -55,7 +54,7 @@ func utilitySepListCheckPresence(ctx context.Context, d *schema.ResourceData, m
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilitySepListCheckPresence: load image list")
+	logger.Debugf(ctx, "utilitySepListCheckPresence: load image list")
 	sepListRaw, err := c.DecortAPICall(ctx, "POST", sepListAPI, urlValues)
 	if err != nil {
 		return nil, err
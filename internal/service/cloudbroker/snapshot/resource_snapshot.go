@@ -33,6 +33,7 @@ package snapshot
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -41,11 +42,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rudecs/terraform-provider-decort/internal/constants"
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 )
 
 func resourceSnapshotCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceSnapshotCreate: called for snapshot %s", d.Get("label").(string))
+	logger.Debugf(ctx, "resourceSnapshotCreate: called for snapshot %s", d.Get("label").(string))
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -85,7 +85,7 @@ func resourceSnapshotRead(ctx context.Context, d *schema.ResourceData, m interfa
 }
 
 func resourceSnapshotDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Debugf("resourceSnapshotDelete: called for %s, id: %s", d.Get("label").(string), d.Id())
+	logger.Debugf(ctx, "resourceSnapshotDelete: called for %s, id: %s", d.Get("label").(string), d.Id())
 
 	c := m.(*controller.ControllerCfg)
 	urlValues := &url.Values{}
@@ -168,6 +168,30 @@ func resourceSnapshotSchemaMake() map[string]*schema.Schema {
 	}
 }
 
+func resourceSnapshotImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	logger.Debugf(ctx, "resourceSnapshotImport: called with id %s", d.Id())
+
+	parameters := strings.Split(d.Id(), "#")
+	if len(parameters) != 2 || parameters[1] == "" {
+		return nil, fmt.Errorf("invalid import id %q: expected <compute_id>#<snapshot_guid>", d.Id())
+	}
+
+	computeId, err := strconv.Atoi(parameters[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid import id %q: compute id must be a number", d.Id())
+	}
+
+	d.SetId(parameters[1])
+	d.Set("compute_id", computeId)
+	d.Set("rollback", false)
+
+	if _, err := utilitySnapshotCheckPresence(ctx, d, m); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func ResourceSnapshot() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
@@ -178,7 +202,7 @@ func ResourceSnapshot() *schema.Resource {
 		DeleteContext: resourceSnapshotDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceSnapshotImport,
 		},
 
 		Timeouts: &schema.ResourceTimeout{
@@ -38,7 +38,6 @@ import (
 	"strconv"
 
 	"github.com/rudecs/terraform-provider-decort/internal/controller"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -58,7 +57,7 @@ func utilityVinsListCheckPresence(ctx context.Context, d *schema.ResourceData, m
 		urlValues.Add("size", strconv.Itoa(size.(int)))
 	}
 
-	log.Debugf("utilityVinsListCheckPresence")
+	logger.Debugf(ctx, "utilityVinsListCheckPresence")
 	vinsListRaw, err := c.DecortAPICall(ctx, "POST", VinsListAPI, urlValues)
 	if err != nil {
 		return nil, err
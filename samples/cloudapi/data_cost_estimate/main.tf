@@ -0,0 +1,99 @@
+/*
+Пример использования
+Получение оценки стоимости ресурсов аккаунта или ресурсной группы
+по таблице цен
+Оценку изменения стоимости по сохранённому плану можно получить командой decort-cost:
+terraform show -json plan.tfplan > plan.json
+decort-cost -plan plan.json -prices prices.json
+*/
+#Расскомментируйте этот код,
+#и внесите необходимые правки в версию и путь,
+#чтобы работать с установленным вручную (не через hashicorp provider registry) провайдером
+/*
+terraform {
+  required_providers {
+    decort = {
+      version = "1.1"
+      source  = "digitalenergy.online/decort/decort"
+    }
+  }
+}
+*/
+
+provider "decort" {
+  authenticator = "oauth2"
+  #controller_url = <DECORT_CONTROLLER_URL>
+  controller_url = "https://mr4.digitalenergy.online"
+  #oauth2_url = <DECORT_SSO_URL>
+  oauth2_url           = "https://sso.digitalenergy.online"
+  allow_unverified_ssl = true
+}
+
+data "decort_cost_estimate" "ce" {
+  #id аккаунта
+  #необязательный параметр, задается account_id или rg_id
+  #тип - число
+  account_id = 33333
+
+  #id ресурсной группы
+  #необязательный параметр, задается account_id или rg_id
+  #тип - число
+  #rg_id = 1111
+
+  #оцениваемое потребление
+  #необязательный параметр
+  #тип - строка
+  #значения:
+  #reserved - зарезервированные ресурсы (по умолчанию)
+  #current - потреблённые единицы (CU) по данным контроллера
+  usage = "reserved"
+
+  #таблица цен потребляемых единиц (CU), цены указываются за единицу за выбранный период, например месяц
+  #обязательный параметр
+  #тип - блок
+  prices {
+    #валюта, копируется в результат
+    #необязательный параметр
+    #тип - строка
+    currency = "RUB"
+
+    #цены единиц по типу CU:
+    #CU_C - виртуальное cpu ядро
+    #CU_M - МБ RAM
+    #CU_D - ГБ диска в пулах, не указанных в блоках pool
+    #CU_I - внешний ip адрес
+    #для фактического потребления аккаунта можно указать и другие типы CU, например CU_NP
+    #необязательный параметр
+    #тип - словарь чисел
+    units = {
+      CU_C = 500
+      CU_M = 0.3
+      CU_D = 5
+      CU_I = 200
+    }
+
+    #цена ГБ диска в пуле SEP
+    #необязательный параметр
+    #тип - блок, может повторяться
+    pool {
+      #id SEP
+      #обязательный параметр
+      #тип - число
+      sep_id = 1
+
+      #имя пула, если не задано - все пулы SEP
+      #необязательный параметр
+      #тип - строка
+      pool = "data01"
+
+      #цена ГБ диска
+      #обязательный параметр
+      #тип - число
+      price = 12
+    }
+  }
+}
+
+output "test" {
+  value = data.decort_cost_estimate.ce.total
+}
@@ -0,0 +1,49 @@
+/*
+Пример использования
+Получение информации об элементе каталога K8s (k8ci)
+по id или по версии Kubernetes
+*/
+#Расскомментируйте этот код,
+#и внесите необходимые правки в версию и путь,
+#чтобы работать с установленным вручную (не через hashicorp provider registry) провайдером
+/*
+terraform {
+  required_providers {
+    decort = {
+      version = "1.1"
+      source  = "digitalenergy.online/decort/decort"
+    }
+  }
+}
+*/
+
+provider "decort" {
+  authenticator = "oauth2"
+  #controller_url = <DECORT_CONTROLLER_URL>
+  controller_url = "https://mr4.digitalenergy.online"
+  #oauth2_url = <DECORT_SSO_URL>
+  oauth2_url           = "https://sso.digitalenergy.online"
+  allow_unverified_ssl = true
+}
+
+data "decort_k8ci" "k" {
+  #id элемента каталога
+  #необязательный параметр, задается k8ci_id или version
+  #тип - число
+  #k8ci_id = 9
+
+  #версия Kubernetes, с префиксом v или без него
+  #необязательный параметр, задается k8ci_id или version
+  #тип - строка
+  version = "1.22.3"
+
+  #искать по версии также среди отключенных элементов каталога
+  #необязательный параметр
+  #тип - булев тип
+  #по-умолчанию - false
+  #include_disabled = false
+}
+
+output "test" {
+  value = data.decort_k8ci.k
+}
@@ -0,0 +1,48 @@
+/*
+Пример использования
+Получение списка элементов каталога K8s (k8ci)
+*/
+#Расскомментируйте этот код,
+#и внесите необходимые правки в версию и путь,
+#чтобы работать с установленным вручную (не через hashicorp provider registry) провайдером
+/*
+terraform {
+  required_providers {
+    decort = {
+      version = "1.1"
+      source  = "digitalenergy.online/decort/decort"
+    }
+  }
+}
+*/
+
+provider "decort" {
+  authenticator = "oauth2"
+  #controller_url = <DECORT_CONTROLLER_URL>
+  controller_url = "https://mr4.digitalenergy.online"
+  #oauth2_url = <DECORT_SSO_URL>
+  oauth2_url           = "https://sso.digitalenergy.online"
+  allow_unverified_ssl = true
+}
+
+data "decort_k8ci_list" "kl" {
+  #включить отключенные элементы каталога
+  #необязательный параметр
+  #тип - булев тип
+  #по-умолчанию - false
+  #include_disabled = true
+
+  #фильтр по статусу
+  #необязательный параметр
+  #тип - строка
+  status = "ENABLED"
+
+  #фильтр по id grid
+  #необязательный параметр
+  #тип - число
+  #gid = 212
+}
+
+output "test" {
+  value = data.decort_k8ci_list.kl
+}
@@ -0,0 +1,63 @@
+/*
+Пример использования
+Получение списка нод k8s кластера с ролями и IP-адресами
+и генерация inventory для Ansible
+*/
+#Расскомментируйте этот код,
+#и внесите необходимые правки в версию и путь,
+#чтобы работать с установленным вручную (не через hashicorp provider registry) провайдером
+/*
+terraform {
+  required_providers {
+    decort = {
+      version = "1.1"
+      source  = "digitalenergy.online/decort/decort"
+    }
+  }
+}
+*/
+
+provider "decort" {
+  authenticator = "oauth2"
+  #controller_url = <DECORT_CONTROLLER_URL>
+  controller_url = "https://mr4.digitalenergy.online"
+  #oauth2_url = <DECORT_SSO_URL>
+  oauth2_url           = "https://sso.digitalenergy.online"
+  allow_unverified_ssl = true
+}
+
+data "decort_k8s_nodes" "nodes" {
+  #id кластера
+  #обязательный параметр
+  #тип - число
+  k8s_id = 49304
+
+  #формат дополнительного вывода
+  #необязательный параметр
+  #тип - строка
+  #возможные значения - "ansible_inventory"
+  #если не задан, inventory не формируется
+  format = "ansible_inventory"
+
+  #формат inventory
+  #необязательный параметр
+  #тип - строка
+  #возможные значения - "ini", "yaml"
+  #по умолчанию - "ini"
+  inventory_format = "ini"
+}
+
+#ноды с ключом по имени ноды
+output "nodes" {
+  value = { for node in data.decort_k8s_nodes.nodes.nodes : node.name => node }
+}
+
+#IP-адрес балансировщика кластера
+output "lb_ip" {
+  value = data.decort_k8s_nodes.nodes.lb_ip
+}
+
+#inventory можно сохранить в файл с помощью ресурса local_file
+output "inventory" {
+  value = data.decort_k8s_nodes.nodes.inventory
+}
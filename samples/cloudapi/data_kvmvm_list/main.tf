@@ -0,0 +1,55 @@
+/*
+Пример использования
+Получение списка compute (виртуальных машин) с фильтрацией по тегам
+*/
+#Расскомментируйте этот код,
+#и внесите необходимые правки в версию и путь,
+#чтобы работать с установленным вручную (не через hashicorp provider registry) провайдером
+/*
+terraform {
+  required_providers {
+    decort = {
+      version = "1.1"
+      source  = "digitalenergy.online/decort/decort"
+    }
+  }
+}
+*/
+
+provider "decort" {
+  authenticator = "oauth2"
+  #controller_url = <DECORT_CONTROLLER_URL>
+  controller_url = "https://ds1.digitalenergy.online"
+  #oauth2_url = <DECORT_SSO_URL>
+  oauth2_url           = "https://sso.digitalenergy.online"
+  allow_unverified_ssl = true
+}
+
+data "decort_kvmvm_list" "comps" {
+  #включить удаленные compute
+  #опциональный параметр
+  #тип - булев тип
+  #includedeleted = false
+
+  #номер страницы
+  #опциональный параметр
+  #тип - число
+  #page = 1
+
+  #размер страницы
+  #опциональный параметр
+  #тип - число
+  #size = 10
+
+  #фильтр по тегам
+  #возвращаются compute, у которых есть все указанные теги с такими же значениями
+  #опциональный параметр
+  #тип - словарь строк
+  tags = {
+    environment = "prod"
+  }
+}
+
+output "test" {
+  value = data.decort_kvmvm_list.comps
+}
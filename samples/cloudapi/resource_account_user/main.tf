@@ -0,0 +1,71 @@
+/*
+Пример использования
+Ресурса account_user
+Ресурс позволяет:
+1. Предоставлять пользователю доступ к аккаунту
+2. Изменять права доступа
+3. Отзывать доступ
+
+*/
+
+#Расскомментируйте этот код,
+#и внесите необходимые правки в версию и путь,
+#чтобы работать с установленным вручную (не через hashicorp provider registry) провайдером
+/*
+terraform {
+  required_providers {
+    decort = {
+      version = "1.1"
+      source  = "digitalenergy.online/decort/decort"
+    }
+  }
+}
+*/
+
+provider "decort" {
+  authenticator = "oauth2"
+  #controller_url = <DECORT_CONTROLLER_URL>
+  controller_url = "https://mr4.digitalenergy.online"
+  #oauth2_url = <DECORT_SSO_URL>
+  oauth2_url           = "https://sso.digitalenergy.online"
+  allow_unverified_ssl = true
+}
+
+
+resource "decort_account_user" "acl" {
+  #обязательный параметр
+  #id аккаунта
+  #тип - число
+  account_id = 1234
+
+  #id пользователя
+  #тип - строка
+  #должен быть указан один из user_id, email
+  user_id = "user@decs3o"
+
+  #email пользователя, id пользователя определяется платформой
+  #тип - строка
+  #email = "user@example.com"
+
+  #обязательный параметр
+  #права доступа: R, RCX или ARCXDU
+  #тип - строка
+  access_type = "RCX"
+
+  #опциональный параметр
+  #отозвать доступ также к ресурсным группам аккаунта при удалении
+  #тип - булев тип
+  #по-умолчанию - false
+  #recursive_delete = false
+
+  #опциональный параметр
+  #требовать явного доступа к аккаунту; если false, унаследованный доступ
+  #принимается как есть и не отзывается при удалении ресурса
+  #тип - булев тип
+  #по-умолчанию - true
+  #explicit = true
+}
+
+output "test" {
+  value = decort_account_user.acl
+}
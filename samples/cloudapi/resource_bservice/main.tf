@@ -89,6 +89,67 @@ resource "decort_bservice" "b" {
   #по-умолачанию - false
   #restore      = true
 
+  #группа компьютов сервиса
+  #необязательный параметр
+  #тип - объект
+  #может быть несколько в ресурсе
+  #группы сопоставляются по имени
+  #при start = true группы запускаются по очереди в порядке depends_on_groups,
+  #каждая после того, как все компьюты групп, от которых она зависит, запущены
+  #останавливаются группы в обратном порядке
+  /*
+  group {
+    #имя группы, уникальное в пределах сервиса
+    #обязательный параметр
+    #тип - строка
+    name = "db"
+
+    #кол-во компьютов в группе
+    #обязательный параметр
+    #тип - число
+    comp_count = 1
+
+    #кол-во cpu, ram в МБ, размер загрузочного диска в ГБ каждого компьюта
+    #обязательные параметры
+    #тип - число
+    cpu  = 2
+    ram  = 4096
+    disk = 20
+
+    #id образа
+    #обязательный параметр
+    #тип - число
+    #изменение пересоздает группу
+    image_id = 1111
+
+    #время в секундах, за которое группа должна запуститься
+    #необязательный параметр
+    #тип - число
+    #по-умолчанию - 0, ограничено таймаутом ресурса
+    timeout_start = 300
+
+    #id ViNS для подключения компьютов группы
+    #необязательный параметр
+    #тип - массив чисел
+    vinses = [2222]
+  }
+
+  group {
+    name       = "app"
+    comp_count = 2
+    cpu        = 2
+    ram        = 2048
+    disk       = 20
+    image_id   = 1111
+    vinses     = [2222]
+
+    #имена групп сервиса, которые запускаются до этой группы
+    #необязательный параметр
+    #тип - массив строк
+    depends_on_groups = ["db"]
+  }
+  */
+
   #мгновенное удаление сервиса без права восстановления
   #необязательный параметр
   #тип - булев тип
@@ -116,6 +116,48 @@ resource "decort_bservice_group" "bsg" {
   #используется при редактировании
   #force_update   = true
 
+  #поэтапное применение изменений cpu, ram, disk и image_id к компьютам группы
+  #необязательный параметр
+  #тип - объект
+  #используется при редактировании
+  #компьюты останавливаются, изменяются и запускаются партиями,
+  #следующая партия начинается после успешной проверки здоровья текущей
+  #при ошибке обновление прерывается, ход обновления - в rolling_update_progress
+  /*
+  rolling_update {
+    #кол-во одновременно обновляемых компьютов
+    #необязательный параметр
+    #тип - число
+    #по-умолчанию - 1
+    max_unavailable = 1
+
+    #пауза между партиями, в секундах
+    #необязательный параметр
+    #тип - число
+    #по-умолчанию - 0
+    batch_pause = 30
+
+    #проверка здоровья компьюта
+    #необязательный параметр
+    #тип - строка
+    #значения:
+    #tech_status - компьют запущен (по-умолчанию)
+    #tcp - компьют запущен и принимает соединения на health_check_port
+    health_check = "tcp"
+
+    #порт для проверки tcp
+    #необязательный параметр
+    #тип - число
+    health_check_port = 80
+
+    #время на прохождение проверки здоровья, в секундах
+    #необязательный параметр
+    #тип - число
+    #по-умолчанию - 300
+    health_check_timeout = 300
+  }
+  */
+
   #старт/стоп вычислительных мощностей
   #необязательный параметр
   #тип - булев тип
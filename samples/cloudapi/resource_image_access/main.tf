@@ -0,0 +1,48 @@
+/*
+Пример использования
+Ресурса image_access
+Ресурс позволяет:
+1. Предоставлять аккаунту доступ к образу
+2. Отзывать доступ аккаунта к образу
+
+*/
+
+#Расскомментируйте этот код,
+#и внесите необходимые правки в версию и путь,
+#чтобы работать с установленным вручную (не через hashicorp provider registry) провайдером
+/*
+terraform {
+  required_providers {
+    decort = {
+      version = "1.1"
+      source  = "digitalenergy.online/decort/decort"
+    }
+  }
+}
+*/
+
+provider "decort" {
+  authenticator = "oauth2"
+  #controller_url = <DECORT_CONTROLLER_URL>
+  controller_url = "https://mr4.digitalenergy.online"
+  #oauth2_url = <DECORT_SSO_URL>
+  oauth2_url           = "https://sso.digitalenergy.online"
+  allow_unverified_ssl = true
+}
+
+
+resource "decort_image_access" "ia" {
+  #обязательный параметр
+  #id образа
+  #тип - число
+  image_id = 1234
+
+  #обязательный параметр
+  #id аккаунта, которому предоставляется доступ
+  #тип - число
+  account_id = 101
+}
+
+output "test" {
+  value = decort_image_access.ia
+}
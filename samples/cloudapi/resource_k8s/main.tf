@@ -48,6 +48,9 @@ resource "decort_k8s" "cluster" {
   #id catalogue item 
   #обязательный параметр
   #тип - число
+  #изменение у существующего кластера обновляет его до следующей минорной версии Kubernetes,
+  #другие изменения отклоняются при планировании
+  #id можно получить с помощью data source decort_k8ci по версии
   k8sci_id = 9
 
   #имя для первой worker group, созданной в кластере
@@ -108,6 +111,21 @@ resource "decort_k8s" "cluster" {
     #тип - число
     disk = 10
   }
+
+  #id или имена compute worker node, которые нужно заменить (например, сломанные)
+  #каждая добавленная в список нода выводится из планирования (cordon),
+  #удаляется из своей worker group и заменяется новой нодой
+  #операция выполняется только для новых элементов списка
+  #опциональный параметр
+  #тип - список строк
+  #replace_nodes = ["1234", "k8s-worker-2"]
+
+  #id или имена compute master node, которые нужно перезапустить
+  #ноды перезапускаются по одной, каждая добавленная в список нода перезапускается один раз
+  #следующая нода перезапускается, когда API сервер кластера сообщит о готовности предыдущей
+  #опциональный параметр
+  #тип - список строк
+  #restart_masters = ["1230"]
 }
 
 output "test_cluster" {
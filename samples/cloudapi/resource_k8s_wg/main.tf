@@ -50,6 +50,14 @@ resource "decort_k8s_wg" "wg" {
   #по - умолчанию - 1
   num = 2
 
+  #границы числа worker node для cluster-autoscaler (команда decort-autoscaler)
+  #пока число worker node находится в этих границах, изменения num игнорируются
+  #опциональные параметры
+  #тип - число
+  #по умолчанию - 0, автомасштабирование выключено
+  min_num = 1
+  max_num = 5
+
   #количество cpu для 1 worker node
   #опциональный параметр
   #тип - число
@@ -68,9 +76,46 @@ resource "decort_k8s_wg" "wg" {
   #по - умолчанию - 0
   #если установлен параметр 0, то размер диска будет равен размеру образа
   disk = 10
+
+  #id SEP для загрузочных дисков worker node
+  #опциональный параметр
+  #тип - число
+  #по умолчанию - 0, SEP выбирается платформой
+  sep_id = 1
+
+  #пул SEP для загрузочных дисков worker node
+  #опциональный параметр
+  #тип - строка
+  sep_pool = "data01"
+
+  #изменение cpu, ram, disk, sep_id или sep_pool не пересоздает worker group,
+  #а поочередно заменяет worker node: сначала создается и запускается новая нода,
+  #затем удаляется старая
+
+  #метки worker node в формате key=value
+  #метки, добавленные платформой, не отображаются
+  #опциональный параметр
+  #тип - список строк
+  labels = ["role=backend"]
+
+  #taints worker node в формате key=value:effect
+  #опциональный параметр
+  #тип - список строк
+  taints = ["dedicated=backend:NoSchedule"]
+
+  #аннотации worker node в формате key=value
+  #опциональный параметр
+  #тип - список строк
+  annotations = ["team=core"]
 }
 
 
 output "test_wg" {
   value = decort_k8s_wg.wg
 }
+
+#значение для флага -node-group команды decort-autoscaler, границы автомасштабирования
+#берутся из min_num и max_num
+output "autoscaler_node_group" {
+  value = decort_k8s_wg.wg.autoscaler_node_group
+}
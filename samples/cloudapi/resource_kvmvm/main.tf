@@ -28,6 +28,39 @@ provider "decort" {
   #oauth2_url = <DECORT_SSO_URL>
   oauth2_url           = "https://sso.digitalenergy.online"
   allow_unverified_ssl = true
+
+  #теги, добавляемые ко всем ресурсам, поддерживающим теги
+  #теги ресурса имеют приоритет
+  #опциональный параметр
+  #тип - словарь строк
+  #default_tags = {
+  #  owner       = "devops"
+  #  cost_centre = "cc-101"
+  #}
+
+  #обязательный префикс имен compute, дисков и vins
+  #опциональный параметр
+  #тип - строка
+  #name_prefix = "prod-"
+
+  #регулярное выражение для имен compute, дисков и vins
+  #опциональный параметр
+  #тип - строка
+  #name_pattern = "^prod-[a-z0-9-]+$"
+
+  #время в секундах, в течение которого переиспользуются ответы get и list запросов к API
+  #любой изменяющий запрос сбрасывает кэш
+  #опциональный параметр
+  #тип - число
+  #значение по умолчанию - 0, кэш отключен
+  #cache_ttl = 30
+
+  #файл, в который каждый запрос к API записывается строкой JSON
+  #с id запроса, ресурсом, операцией, длительностью и статусом
+  #значения паролей и секретов маскируются
+  #опциональный параметр
+  #тип - строка
+  #trace_file = "decort-trace.jsonl"
 }
 
 resource "decort_kvmvm" "comp" {
@@ -72,6 +105,16 @@ resource "decort_kvmvm" "comp" {
   #тип - строка
   description = "test update description in tf words update"
 
+  #теги compute в формате ключ-значение
+  #опциональный параметр
+  #теги, не описанные в конфигурации, удаляются
+  #тип - словарь строк
+  tags = {
+    owner       = "devops"
+    cost_centre = "cc-101"
+    environment = "prod"
+  }
+
   #Создание и добавление диска дял compute
   #опциональный параметр
   #тип - список дисков
@@ -209,6 +252,42 @@ resource "decort_kvmvm" "comp" {
     #ipa_type = ""
   }
 
+  #правила проброса портов
+  #опциональный параметр
+  #compute должен быть подключен к ViNS
+  #пересечение диапазонов внешних портов проверяется на этапе plan
+  #может быть один, несколько или ни одного блока
+  #тип - блок
+  #port_forwarding {
+    #начало диапазона внешних портов
+    #обязательный параметр
+    #тип - число
+    #public_port_start = 8022
+
+    #конец диапазона внешних портов
+    #опциональный параметр
+    #по умолчанию равен public_port_start
+    #тип - число
+    #public_port_end = 8022
+
+    #внутренний порт
+    #обязательный параметр
+    #тип - число
+    #local_port = 22
+
+    #протокол
+    #возможные значения - tcp, udp
+    #опциональный параметр
+    #по умолчанию - tcp
+    #тип - строка
+    #proto = "tcp"
+  #}
+
+  #удалять правила проброса портов, не описанные в блоках port_forwarding
+  #опциональный параметр
+  #по умолчанию - false
+  #тип - bool
+  #port_forwarding_exclusive = false
 
 }
 
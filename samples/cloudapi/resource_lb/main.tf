@@ -64,6 +64,13 @@ resource "decort_lb" "lb" {
   #тип - строка
   #desc      = "temp super lb for testing tf provider"
 
+  #флаг режима высокой доступности load balancer
+  #необязательный параметр
+  #тип - булев тип
+  #при включении создается secondary node, ресурс ожидает ее перехода в статус STARTED
+  #отключить режим высокой доступности нельзя
+  #ha_mode = true
+
   #флаг доступности load balancer
   #необязательный параметр
   #тип - булев тип
@@ -0,0 +1,82 @@
+/*
+Пример использования
+Ресурсов в нескольких локациях платформы
+*/
+#Расскомментируйте этот код,
+#и внесите необходимые правки в версию и путь,
+#чтобы работать с установленным вручную (не через hashicorp provider registry) провайдером
+/*
+terraform {
+  required_providers {
+    decort = {
+      version = "1.1"
+      source  = "digitalenergy.online/decort/decort"
+    }
+  }
+}
+*/
+
+#провайдер по умолчанию создает ресурсы в локации "ru-msk"
+provider "decort" {
+  authenticator = "oauth2"
+  #controller_url = <DECORT_CONTROLLER_URL>
+  controller_url = "https://mr4.digitalenergy.online"
+  #oauth2_url = <DECORT_SSO_URL>
+  oauth2_url           = "https://sso.digitalenergy.online"
+  allow_unverified_ssl = true
+
+  #код локации, в которой создаются ресурсы без gid и location
+  #опциональный параметр, не совместим с grid_id
+  #тип - строка
+  #если не задан ни location, ни grid_id, используется первая локация платформы
+  location = "ru-msk"
+
+  #id грида, в котором создаются ресурсы без gid и location
+  #опциональный параметр, не совместим с location
+  #тип - число
+  #grid_id = 212
+}
+
+#провайдер с псевдонимом создает ресурсы в другой локации
+provider "decort" {
+  alias                = "spb"
+  authenticator        = "oauth2"
+  controller_url       = "https://mr4.digitalenergy.online"
+  oauth2_url           = "https://sso.digitalenergy.online"
+  allow_unverified_ssl = true
+  location             = "ru-spb"
+}
+
+#ресурсная группа в локации провайдера по умолчанию
+resource "decort_resgroup" "msk" {
+  name       = "rg-msk"
+  account_id = 88366
+}
+
+#ресурсная группа в локации провайдера с псевдонимом
+resource "decort_resgroup" "spb" {
+  provider   = decort.spb
+  name       = "rg-spb"
+  account_id = 88366
+}
+
+resource "decort_disk" "disk" {
+  account_id = 88366
+  disk_name  = "disk-spb"
+  size_max   = 20
+
+  #код локации, в которой создается диск
+  #опциональный параметр, не совместим с gid
+  #тип - строка
+  #значение по умолчанию - локация провайдера
+  #изменение параметра пересоздает диск
+  location = "ru-spb"
+}
+
+output "grids" {
+  value = {
+    msk  = decort_resgroup.msk.gid
+    spb  = decort_resgroup.spb.gid
+    disk = decort_disk.disk.gid
+  }
+}
@@ -48,6 +48,7 @@ resource "decort_snapshot" "s" {
   #тип - булев тип
   #по-уолчанию - false
   #если флаг был измеен с false на true, то произойдет откат
+  #устарел, используйте ресурс decort_snapshot_rollback
   #rollback = false
 }
 
@@ -0,0 +1,82 @@
+/*
+Пример использования
+Ресурса snapshot_policy
+Ресурс позволяет:
+1. Создавать snapshot вычислительных мощностей и дисков по расписанию
+   при каждом запуске terraform apply (например, из cron), если истек interval
+2. Удалять старые snapshot сверх retention_count и старше retention_days
+
+*/
+
+#Расскомментируйте этот код,
+#и внесите необходимые правки в версию и путь,
+#чтобы работать с установленным вручную (не через hashicorp provider registry) провайдером
+/*
+terraform {
+  required_providers {
+    decort = {
+      version = "1.1"
+      source  = "digitalenergy.online/decort/decort"
+    }
+  }
+}
+*/
+
+provider "decort" {
+  authenticator = "oauth2"
+  #controller_url = <DECORT_CONTROLLER_URL>
+  controller_url = "https://mr4.digitalenergy.online"
+  #oauth2_url = <DECORT_SSO_URL>
+  oauth2_url           = "https://sso.digitalenergy.online"
+  allow_unverified_ssl = true
+}
+
+
+resource "decort_snapshot_policy" "daily" {
+  #опциональный параметр
+  #id вычислительных мощностей
+  #тип - множество чисел
+  compute_ids = [24074]
+
+  #опциональный параметр
+  #id дисков, snapshot создается для вычислительной мощности,
+  #к которой подключен диск
+  #тип - множество чисел
+  #должен быть указан хотя бы один из compute_ids, disk_ids
+  #disk_ids = [1234]
+
+  #опциональный параметр
+  #шаблон наименования snapshot, поддерживаются {date}, {time}, {unix} (UTC)
+  #snapshot с наименованиями по шаблону управляются политикой
+  #тип - строка
+  #по-умолчанию - "tf-{date}-{time}"
+  label_template = "daily-{date}-{time}"
+
+  #опциональный параметр
+  #минимальный интервал между snapshot
+  #тип - строка
+  #по-умолчанию - "24h"
+  interval = "24h"
+
+  #опциональный параметр
+  #количество хранимых snapshot для каждой вычислительной мощности, 0 - без ограничений
+  #тип - число
+  #по-умолчанию - 7
+  retention_count = 7
+
+  #опциональный параметр
+  #snapshot старше указанного количества дней удаляются, 0 - без ограничений
+  #тип - число
+  #по-умолчанию - 0
+  retention_days = 14
+
+  #опциональный параметр
+  #удалять snapshot политики при удалении ресурса
+  #тип - булев тип
+  #по-умолчанию - false
+  #delete_snapshots = false
+}
+
+output "test" {
+  value = decort_snapshot_policy.daily
+}
@@ -0,0 +1,59 @@
+/*
+Пример использования
+Ресурса snapshot_rollback
+Ресурс позволяет:
+1. Откатывать вычислительную мощность или диск к snapshot при создании ресурса
+2. Хранить в state, какой snapshot был восстановлен и когда
+
+*/
+
+#Расскомментируйте этот код,
+#и внесите необходимые правки в версию и путь,
+#чтобы работать с установленным вручную (не через hashicorp provider registry) провайдером
+/*
+terraform {
+  required_providers {
+    decort = {
+      version = "1.1"
+      source  = "digitalenergy.online/decort/decort"
+    }
+  }
+}
+*/
+
+provider "decort" {
+  authenticator = "oauth2"
+  #controller_url = <DECORT_CONTROLLER_URL>
+  controller_url = "https://mr4.digitalenergy.online"
+  #oauth2_url = <DECORT_SSO_URL>
+  oauth2_url           = "https://sso.digitalenergy.online"
+  allow_unverified_ssl = true
+}
+
+
+resource "decort_snapshot_rollback" "r" {
+  #id вычислительной мощности
+  #тип - число
+  #должен быть указан один из compute_id, disk_id
+  compute_id = 24074
+
+  #id диска
+  #тип - число
+  #disk_id = 1234
+
+  #обязательный параметр
+  #наименование snapshot
+  #тип - строка
+  label = "daily-20221101-030000"
+
+  #опциональный параметр
+  #произвольные значения, при их изменении откат выполняется повторно
+  #тип - словарь строк
+  triggers = {
+    ticket = "INC-1234"
+  }
+}
+
+output "test" {
+  value = decort_snapshot_rollback.r
+}
@@ -121,19 +121,21 @@ resource "decort_vins" "vins" {
 
   #опциональный параметр
   #блок для добавления natRule
+  #правила сравниваются по ip, портам и протоколу
+  #пересечение диапазонов внешних портов проверяется на этапе plan
   #тип - блок
   nat_rule {
-    #опциональный параметр
+    #обязательный параметр
     #ip внутренний
     #тип - строка
     int_ip         = "192.168.0.28"
 
-    #опциональный параметр
+    #обязательный параметр
     #внутренний порт
     #тип - число
     int_port       = 80
 
-    #опциональный параметр
+    #обязательный параметр
     #начало диапазона внешних портов
     #тип - число
     ext_port_start = 8001
@@ -145,10 +147,18 @@ resource "decort_vins" "vins" {
 
     #опциональный параметр
     #протокол natRule
+    #значение по умолчанию: tcp
     #тип - строка
     proto          = "tcp"
   }
 
+  #опциональный параметр
+  #удалять natRule, не описанные в блоках nat_rule
+  #(в том числе правила проброса портов compute)
+  #значение по умолчанию: false
+  #тип - булев тип
+  nat_rule_exclusive = false
+
   #опциональный параметр
   #восстановление ресурса
   #тип - булев тип
@@ -0,0 +1,66 @@
+/*
+Пример использования
+Ресурса vins_ip_reservation
+Ресурс позволяет:
+1. Резервировать ip в vins
+2. Освобождать ip при удалении ресурса
+3. Привязывать ip к mac и compute
+
+*/
+
+#Расскомментируйте этот код,
+#и внесите необходимые правки в версию и путь,
+#чтобы работать с установленным вручную (не через hashicorp provider registry) провайдером
+/*
+terraform {
+  required_providers {
+    decort = {
+      version = "1.1"
+      source  = "digitalenergy.online/decort/decort"
+    }
+  }
+}
+*/
+
+provider "decort" {
+  authenticator = "oauth2"
+  #controller_url = <DECORT_CONTROLLER_URL>
+  controller_url = "https://mr4.digitalenergy.online"
+  #oauth2_url = <DECORT_SSO_URL>
+  oauth2_url           = "https://sso.digitalenergy.online"
+  allow_unverified_ssl = true
+}
+
+resource "decort_vins_ip_reservation" "ip" {
+  #id vins
+  #обязательный параметр
+  #тип - число
+  vins_id = 1234
+
+  #тип резервирования
+  #возможные значения - DHCP, VIP, EXCLUDE
+  #обязательный параметр
+  #тип - строка
+  type = "DHCP"
+
+  #резервируемый ip
+  #опциональный параметр
+  #если не задан, выделяется платформой
+  #если ip уже зарезервирован в vins, ошибка выводится на этапе plan
+  #тип - строка
+  ip_addr = "192.168.0.28"
+
+  #mac адрес для привязки ip
+  #опциональный параметр
+  #тип - строка
+  #mac_addr = "52:54:00:00:00:01"
+
+  #id compute для привязки ip
+  #опциональный параметр
+  #тип - число
+  #compute_id = 1234
+}
+
+output "test" {
+  value = decort_vins_ip_reservation.ip
+}